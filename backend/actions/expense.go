@@ -10,16 +10,22 @@ import (
 )
 
 type SubmitExpenseInput struct {
-	UserID      int64  `json:"user_id"`
+	UserID      int64  `json:"-"` // the submitter, set from the session
 	AmountIDR   int64  `json:"amount_idr"`
 	Description string `json:"description"`
 	ReceiptURL  string `json:"receipt_url"`
+
+	Category constants.ExpenseCategory `json:"category" example:"travel"` // defaults to other
 }
 
 func SubmitExpense(input SubmitExpenseInput) (*models.Expense, *models.Approval, error) {
 	if err := rules.ValidateExpense(input.AmountIDR, input.Description); err != nil {
 		return nil, nil, err
 	}
+	category, err := rules.ExpenseCategory(input.Category)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	now := time.Now().UTC()
 	requiresApproval := rules.RequiresManagerApproval(input.AmountIDR)
@@ -29,6 +35,7 @@ func SubmitExpense(input SubmitExpenseInput) (*models.Expense, *models.Approval,
 		UserID:           input.UserID,
 		AmountIDR:        input.AmountIDR,
 		Description:      input.Description,
+		Category:         category,
 		ReceiptURL:       input.ReceiptURL,
 		SubmittedAt:      now,
 		Status:           rules.InitialExpenseStatus(requiresApproval),
@@ -40,6 +47,7 @@ func SubmitExpense(input SubmitExpenseInput) (*models.Expense, *models.Approval,
 		ApproverID: nil,
 		Notes:      "",
 		Status:     constants.ApprovalStatusPending,
+		Step:       1,
 		CreatedAt:  now,
 	}
 
@@ -47,9 +55,16 @@ func SubmitExpense(input SubmitExpenseInput) (*models.Expense, *models.Approval,
 }
 
 type ApproveExpenseInput struct {
-	Expense    *models.Expense
-	ApproverID *int64
-	Notes      string
+	Expense      *models.Expense
+	ApproverID   *int64
+	OnBehalfOfID *int64 // approver the delegate is acting for
+	Notes        string
+
+	SeparationOfDuties  rules.SeparationOfDutiesPolicy
+	ApproverSuperiorIDs []int64
+	PriorApproverIDs    []int64
+	// manager chain of the approver in OnBehalfOfID
+	PrincipalSuperiorIDs []int64
 }
 
 func ApproveExpense(input ApproveExpenseInput) (*models.Expense, *models.Approval, error) {
@@ -61,6 +76,29 @@ func ApproveExpense(input ApproveExpenseInput) (*models.Expense, *models.Approva
 		return nil, nil, err
 	}
 
+	if err := checkSeparationOfDuties(input.Expense, input.ApproverID, input.OnBehalfOfID, input.SeparationOfDuties, input.ApproverSuperiorIDs, input.PrincipalSuperiorIDs, input.PriorApproverIDs); err != nil {
+		return nil, nil, err
+	}
+
+	approval := input.Expense.Approval
+	if approval.Step < 1 {
+		approval.Step = 1
+	}
+	if input.ApproverID != nil && approval.Step < rules.RequiredApprovalSteps(input.Expense.AmountIDR) {
+		// an earlier step, the expense stays pending for the next manager up the chain
+		approval.Steps = append(approval.Steps, models.ApprovalStep{
+			ApprovalID:   approval.ID,
+			Step:         approval.Step,
+			ApproverID:   *input.ApproverID,
+			OnBehalfOfID: input.OnBehalfOfID,
+			Notes:        input.Notes,
+			DecidedAt:    time.Now().UTC(),
+		})
+		approval.Step++
+		approval.AssigneeID = nextApprover(input.OnBehalfOfID, input.ApproverSuperiorIDs, input.PrincipalSuperiorIDs)
+		return input.Expense, approval, nil
+	}
+
 	toStatus := constants.ExpenseStatusApproved // manually for now
 	if err := rules.CanTransition(input.Expense.Status, toStatus); err != nil {
 		return nil, nil, err
@@ -70,15 +108,23 @@ func ApproveExpense(input ApproveExpenseInput) (*models.Expense, *models.Approva
 
 	input.Expense.Approval.Status = constants.ApprovalStatusApproved
 	input.Expense.Approval.ApproverID = input.ApproverID
+	input.Expense.Approval.OnBehalfOfID = input.OnBehalfOfID
 	input.Expense.Approval.Notes = input.Notes
 
 	return input.Expense, input.Expense.Approval, nil
 }
 
 type RejectExpenseInput struct {
-	Expense    *models.Expense
-	ApproverID *int64
-	Notes      string
+	Expense      *models.Expense
+	ApproverID   *int64
+	OnBehalfOfID *int64 // approver the delegate is acting for
+	Notes        string
+
+	SeparationOfDuties  rules.SeparationOfDutiesPolicy
+	ApproverSuperiorIDs []int64
+	PriorApproverIDs    []int64
+	// manager chain of the approver in OnBehalfOfID
+	PrincipalSuperiorIDs []int64
 }
 
 func RejectExpense(input RejectExpenseInput) (*models.Expense, *models.Approval, error) {
@@ -89,6 +135,10 @@ func RejectExpense(input RejectExpenseInput) (*models.Expense, *models.Approval,
 		return nil, nil, err
 	}
 
+	if err := checkSeparationOfDuties(input.Expense, input.ApproverID, input.OnBehalfOfID, input.SeparationOfDuties, input.ApproverSuperiorIDs, input.PrincipalSuperiorIDs, input.PriorApproverIDs); err != nil {
+		return nil, nil, err
+	}
+
 	toStatus := constants.ExpenseStatusRejected // manually for now
 	if err := rules.CanTransition(input.Expense.Status, toStatus); err != nil {
 		return nil, nil, err
@@ -98,7 +148,37 @@ func RejectExpense(input RejectExpenseInput) (*models.Expense, *models.Approval,
 
 	input.Expense.Approval.Status = constants.ApprovalStatusRejected
 	input.Expense.Approval.ApproverID = input.ApproverID
+	input.Expense.Approval.OnBehalfOfID = input.OnBehalfOfID
 	input.Expense.Approval.Notes = input.Notes
 
 	return input.Expense, input.Expense.Approval, nil
 }
+
+// nextApprover is the manager above the one who approved, nil at the top of the chain
+func nextApprover(onBehalfOfID *int64, approverSuperiorIDs, principalSuperiorIDs []int64) *int64 {
+	chain := approverSuperiorIDs
+	if onBehalfOfID != nil {
+		chain = principalSuperiorIDs
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	return &chain[0]
+}
+
+// system decisions (nil approver) are not subject to separation of duties
+func checkSeparationOfDuties(expense *models.Expense, approverID, onBehalfOfID *int64, policy rules.SeparationOfDutiesPolicy, superiorIDs, principalSuperiorIDs, priorApproverIDs []int64) error {
+	if approverID == nil {
+		return nil
+	}
+
+	return policy.Check(rules.SeparationOfDutiesInput{
+		ApproverID:          *approverID,
+		SubmitterID:         expense.UserID,
+		ApproverSuperiorIDs: superiorIDs,
+		PriorApproverIDs:    priorApproverIDs,
+
+		PrincipalID:          onBehalfOfID,
+		PrincipalSuperiorIDs: principalSuperiorIDs,
+	})
+}
@@ -6,11 +6,12 @@ import (
 )
 
 type ExpenseAuditLogInput struct {
-	ExpenseID  int64
-	ActorID    *int64
-	FromStatus constants.ExpenseStatus
-	ToStatus   constants.ExpenseStatus
-	Reason     string
+	ExpenseID    int64
+	ActorID      *int64
+	OnBehalfOfID *int64
+	FromStatus   constants.ExpenseStatus
+	ToStatus     constants.ExpenseStatus
+	Reason       string
 }
 
 func ExpenseAuditLog(input ExpenseAuditLogInput) (audit *models.ExpenseAuditLog, err error) {
@@ -22,11 +23,12 @@ func ExpenseAuditLog(input ExpenseAuditLogInput) (audit *models.ExpenseAuditLog,
 	}
 
 	audit = &models.ExpenseAuditLog{
-		ExpenseID:  input.ExpenseID,
-		ActorID:    actorID,
-		FromStatus: fromStatus,
-		ToStatus:   input.ToStatus,
-		Reason:     input.Reason,
+		ExpenseID:    input.ExpenseID,
+		ActorID:      actorID,
+		OnBehalfOfID: input.OnBehalfOfID,
+		FromStatus:   fromStatus,
+		ToStatus:     input.ToStatus,
+		Reason:       input.Reason,
 	}
 
 	return audit, nil
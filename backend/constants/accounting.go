@@ -0,0 +1,35 @@
+package constants
+
+// AccountingFormat is a journal file layout accounting software imports
+type AccountingFormat string
+
+const (
+	AccountingFormatCSV  AccountingFormat = "csv"  // generic journal, one row per debit or credit
+	AccountingFormatIIF  AccountingFormat = "iif"  // QuickBooks Desktop general journal
+	AccountingFormatXero AccountingFormat = "xero" // Xero manual journal import
+)
+
+var AccountingFormats = []AccountingFormat{
+	AccountingFormatCSV,
+	AccountingFormatIIF,
+	AccountingFormatXero,
+}
+
+// GLAccount is an account of the general ledger in the accounting software
+type GLAccount struct {
+	Code string
+	Name string
+}
+
+// DefaultGLAccounts books a category until a manager maps it elsewhere
+var DefaultGLAccounts = map[ExpenseCategory]GLAccount{
+	ExpenseCategoryTravel:    {"6110", "Travel"},
+	ExpenseCategoryMeals:     {"6120", "Meals and Entertainment"},
+	ExpenseCategoryLodging:   {"6130", "Lodging"},
+	ExpenseCategoryTransport: {"6140", "Local Transport"},
+	ExpenseCategorySupplies:  {"6150", "Office Supplies"},
+	ExpenseCategoryOther:     {"6100", "Reimbursed Employee Expenses"},
+}
+
+// DefaultGLPaymentAccount is credited with every payment, see ACCOUNTING_PAYMENT_ACCOUNT_CODE
+var DefaultGLPaymentAccount = GLAccount{"1000", "Cash"}
@@ -0,0 +1,28 @@
+package constants
+
+// APIScope limits what an API key may do on top of its service account's role
+type APIScope string
+
+const (
+	APIScopeExpensesRead    APIScope = "expenses:read"
+	APIScopeExpensesWrite   APIScope = "expenses:write" // submit, approvals and rejections need a browser session
+	APIScopeUsersRead       APIScope = "users:read"
+	APIScopeUsersWrite      APIScope = "users:write"
+	APIScopeSettingsRead    APIScope = "settings:read" // SLA policies, delegations and GL accounts
+	APIScopeSettingsWrite   APIScope = "settings:write"
+	APIScopeAuditRead       APIScope = "audit:read"
+	APIScopeReportsRead     APIScope = "reports:read"     // dashboards, ledger and statements
+	APIScopeAccountingWrite APIScope = "accounting:write" // journal exports, which mark expenses as exported
+)
+
+var APIScopes = []APIScope{
+	APIScopeExpensesRead,
+	APIScopeExpensesWrite,
+	APIScopeUsersRead,
+	APIScopeUsersWrite,
+	APIScopeSettingsRead,
+	APIScopeSettingsWrite,
+	APIScopeAuditRead,
+	APIScopeReportsRead,
+	APIScopeAccountingWrite,
+}
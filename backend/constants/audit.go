@@ -0,0 +1,48 @@
+package constants
+
+type AuditAction string
+
+const (
+	AuditActionLogin              AuditAction = "auth.login" // result in metadata, failures included
+	AuditActionPasswordChange     AuditAction = "auth.password_change"
+	AuditActionPasswordReset      AuditAction = "auth.password_reset" // through the emailed link
+	AuditActionTwoFactorEnable    AuditAction = "auth.two_factor_enable"
+	AuditActionTwoFactorDisable   AuditAction = "auth.two_factor_disable"
+	AuditActionRecoveryCodesRegen AuditAction = "auth.recovery_codes_regenerate"
+	AuditActionSessionRevoke      AuditAction = "auth.session_revoke"
+	AuditActionUserCreate         AuditAction = "user.create"
+	AuditActionUserUpdate         AuditAction = "user.update"
+	AuditActionUserDeactivate     AuditAction = "user.deactivate"
+	AuditActionUserReactivate     AuditAction = "user.reactivate"
+	AuditActionUserPasswordReset  AuditAction = "user.password_reset" // by a manager
+	AuditActionUserUnlock         AuditAction = "user.unlock"
+	AuditActionInviteCreate       AuditAction = "invite.create"
+	AuditActionInviteRevoke       AuditAction = "invite.revoke"
+	AuditActionAPIKeyCreate       AuditAction = "api_key.create"
+	AuditActionAPIKeyRevoke       AuditAction = "api_key.revoke"
+	AuditActionExpenseCreate      AuditAction = "expense.create"
+	AuditActionApprovalDecide     AuditAction = "approval.decide"
+	AuditActionSLAPolicyUpdate    AuditAction = "sla_policy.update"
+	AuditActionDelegationCreate   AuditAction = "delegation.create"
+	AuditActionDelegationRevoke   AuditAction = "delegation.revoke"
+	AuditActionPaymentRetry       AuditAction = "payment.retry"
+	AuditActionPaymentFail        AuditAction = "payment.fail"
+	AuditActionPaymentComplete    AuditAction = "payment.complete"
+	AuditActionGLAccountUpdate    AuditAction = "gl_account.update"
+	AuditActionAccountingExport   AuditAction = "accounting.export"
+)
+
+type AuditEntityType string
+
+const (
+	AuditEntityUser       AuditEntityType = "user"
+	AuditEntitySession    AuditEntityType = "session"
+	AuditEntityInvite     AuditEntityType = "invite"
+	AuditEntityAPIKey     AuditEntityType = "api_key"
+	AuditEntityExpense    AuditEntityType = "expense"
+	AuditEntityApproval   AuditEntityType = "approval"
+	AuditEntitySLAPolicy  AuditEntityType = "sla_policy"
+	AuditEntityDelegation AuditEntityType = "delegation"
+	AuditEntityGLAccount  AuditEntityType = "gl_account"
+	AuditEntityAccounting AuditEntityType = "accounting_export"
+)
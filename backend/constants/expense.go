@@ -9,9 +9,30 @@ const (
 	ExpenseStatusCompleted ExpenseStatus = "completed"
 )
 
+type ExpenseCategory string
+
+const (
+	ExpenseCategoryTravel    ExpenseCategory = "travel"
+	ExpenseCategoryMeals     ExpenseCategory = "meals"
+	ExpenseCategoryLodging   ExpenseCategory = "lodging"
+	ExpenseCategoryTransport ExpenseCategory = "transport"
+	ExpenseCategorySupplies  ExpenseCategory = "supplies"
+	ExpenseCategoryOther     ExpenseCategory = "other"
+)
+
+var ExpenseCategories = []ExpenseCategory{
+	ExpenseCategoryTravel,
+	ExpenseCategoryMeals,
+	ExpenseCategoryLodging,
+	ExpenseCategoryTransport,
+	ExpenseCategorySupplies,
+	ExpenseCategoryOther,
+}
+
 // does not require type conversion when used in domains
 const (
-	MinExpenseAmount  int64 = 10000
-	MaxExpenseAmount  int64 = 50000000
-	ApprovalThreshold int64 = 1000000
+	MinExpenseAmount        int64 = 10000
+	MaxExpenseAmount        int64 = 50000000
+	ApprovalThreshold       int64 = 1000000
+	SecondApprovalThreshold int64 = 10000000 // a second manager approves from this amount
 )
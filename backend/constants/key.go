@@ -0,0 +1,8 @@
+package constants
+
+type SigningKeyStatus string
+
+const (
+	SigningKeyStatusActive  SigningKeyStatus = "active"  // signs new tokens
+	SigningKeyStatusRetired SigningKeyStatus = "retired" // only verifies tokens issued before rotation
+)
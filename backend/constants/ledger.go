@@ -0,0 +1,39 @@
+package constants
+
+// LedgerAccount is the code of an account in the reimbursement ledger
+type LedgerAccount string
+
+const (
+	LedgerAccountCash              LedgerAccount = "1000"
+	LedgerAccountEmployeePayable   LedgerAccount = "2100" // kept per employee through the line's user
+	LedgerAccountReimbursedExpense LedgerAccount = "6100"
+)
+
+type LedgerAccountType string
+
+// asset and expense accounts grow with debits, liabilities with credits
+const (
+	LedgerAccountTypeAsset     LedgerAccountType = "asset"
+	LedgerAccountTypeLiability LedgerAccountType = "liability"
+	LedgerAccountTypeExpense   LedgerAccountType = "expense"
+)
+
+type LedgerAccountInfo struct {
+	Code LedgerAccount
+	Name string
+	Type LedgerAccountType
+}
+
+// LedgerAccounts is the chart of accounts, in trial balance order
+var LedgerAccounts = []LedgerAccountInfo{
+	{LedgerAccountCash, "Cash", LedgerAccountTypeAsset},
+	{LedgerAccountEmployeePayable, "Employee reimbursements payable", LedgerAccountTypeLiability},
+	{LedgerAccountReimbursedExpense, "Reimbursed employee expenses", LedgerAccountTypeExpense},
+}
+
+type JournalEntryKind string
+
+const (
+	JournalEntryKindApproval   JournalEntryKind = "approval"   // the company owes the employee
+	JournalEntryKindSettlement JournalEntryKind = "settlement" // the employee was paid
+)
@@ -0,0 +1,12 @@
+package constants
+
+type LoginResult string
+
+const (
+	LoginResultSuccess             LoginResult = "success"
+	LoginResultInvalidCredentials  LoginResult = "invalid_credentials"   // unknown email or wrong password, counted
+	LoginResultInvalidSecondFactor LoginResult = "invalid_second_factor" // wrong TOTP or recovery code, counted
+	LoginResultThrottled           LoginResult = "throttled"             // refused before checking the password
+	LoginResultDeactivated         LoginResult = "deactivated"
+	LoginResultUnlocked            LoginResult = "unlocked" // counters reset by a manager or a password reset
+)
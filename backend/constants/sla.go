@@ -0,0 +1,9 @@
+package constants
+
+type SLAAction string
+
+const (
+	SLAActionNone     SLAAction = "none"
+	SLAActionRemind   SLAAction = "remind"
+	SLAActionEscalate SLAAction = "escalate"
+)
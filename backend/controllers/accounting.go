@@ -0,0 +1,387 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UpdateGLAccountRequest struct {
+	AccountCode string `json:"account_code" binding:"required" example:"6110"`
+	AccountName string `json:"account_name" binding:"required" example:"Travel"`
+}
+
+type CreateAccountingExportRequest struct {
+	Format constants.AccountingFormat `json:"format" example:"xero"`
+}
+
+type AccountingExportsListResponse struct {
+	Data []models.AccountingExport `json:"data"`
+	Meta PaginationMeta            `json:"meta"`
+}
+
+// accountingExportRow is one expense of an export download
+type accountingExportRow struct {
+	ID                int64
+	UUID              uuid.UUID
+	Description       string
+	AmountIDR         int64 `gorm:"column:amount_idr"`
+	ProcessedAt       time.Time
+	PaymentReference  *string
+	EmployeeName      string
+	DebitAccountCode  string
+	DebitAccountName  string
+	CreditAccountCode string
+	CreditAccountName string
+}
+
+// GetGLAccounts godoc
+// @Summary List GL account mappings
+// @Description The general ledger account every expense category is booked to in accounting exports, unmapped categories show their default (manager only)
+// @Tags Accounting
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]models.GLAccountMapping}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/accounting/gl-accounts [get]
+func GetGLAccounts(c *gin.Context) {
+	accounts, err := helpers.GetGLAccounts(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch GL accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// UpdateGLAccount godoc
+// @Summary Map a category to a GL account
+// @Description Book the expenses of a category to this account in later downloads. QuickBooks matches accounts by name, Xero by code (manager only)
+// @Tags Accounting
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param category path string true "Expense category, e.g. travel"
+// @Param request body UpdateGLAccountRequest true "GL account"
+// @Success 200 {object} models.GLAccountMapping
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/accounting/gl-accounts/{category} [put]
+func UpdateGLAccount(c *gin.Context) {
+	category := constants.ExpenseCategory(c.Param("category"))
+	if !rules.IsExpenseCategory(category) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense category not found"})
+		return
+	}
+
+	var input UpdateGLAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := rules.ValidateGLAccount(constants.GLAccount{Code: input.AccountCode, Name: input.AccountName}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accounts, err := helpers.GetGLAccounts(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch GL accounts"})
+		return
+	}
+	var before models.GLAccountMapping
+	for _, account := range accounts {
+		if account.Category == category {
+			before = account
+		}
+	}
+
+	mapping := models.GLAccountMapping{Category: category, AccountCode: input.AccountCode, AccountName: input.AccountName}
+	if err := db.DB.Save(&mapping).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update GL account"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionGLAccountUpdate,
+		EntityType: constants.AuditEntityGLAccount,
+		EntityID:   string(category),
+		Before:     before,
+		After:      mapping,
+	})
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// CreateAccountingExport godoc
+// @Summary Export paid expenses to accounting
+// @Description Record a batch of the completed expenses paid in the period that no earlier export contains. Download it with /manager/accounting/exports/{id}/download (manager only)
+// @Tags Accounting
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param from query string false "Paid at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Paid before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Param request body CreateAccountingExportRequest false "Download format, csv by default"
+// @Success 201 {object} models.AccountingExport
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/accounting/exports [post]
+func CreateAccountingExport(c *gin.Context) {
+	actor, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var input CreateAccountingExportRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if input.Format == "" {
+		input.Format = constants.AccountingFormatCSV
+	}
+	format, err := rules.AccountingFormat(input.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	export := models.AccountingExport{Format: format, ActorID: &actor.ID, PaidFrom: from, PaidTo: to}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Expense{}).
+			Where("status = ? AND processed_at IS NOT NULL", constants.ExpenseStatusCompleted).
+			Where("id NOT IN (?)", tx.Model(&models.AccountingExportItem{}).Select("expense_id"))
+		if from != nil {
+			query = query.Where("processed_at >= ?", *from)
+		}
+		if to != nil {
+			query = query.Where("processed_at < ?", *to)
+		}
+
+		var expenses []models.Expense
+		if err := query.Select("id", "category", "amount_idr").Order("id").Find(&expenses).Error; err != nil {
+			return err
+		}
+		if len(expenses) == 0 {
+			return rules.ErrNothingToExport
+		}
+
+		accounts, err := helpers.GLAccountsByCategory(tx)
+		if err != nil {
+			return err
+		}
+		payment := helpers.GLPaymentAccount()
+
+		items := make([]models.AccountingExportItem, 0, len(expenses))
+		for _, expense := range expenses {
+			export.ExpenseCount++
+			export.TotalIDR += expense.AmountIDR
+
+			debit, ok := accounts[expense.Category]
+			if !ok {
+				debit = accounts[constants.ExpenseCategoryOther]
+			}
+			items = append(items, models.AccountingExportItem{
+				ExpenseID:         expense.ID,
+				DebitAccountCode:  debit.Code,
+				DebitAccountName:  debit.Name,
+				CreditAccountCode: payment.Code,
+				CreditAccountName: payment.Name,
+			})
+		}
+		if err := tx.Create(&export).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].AccountingExportID = export.ID
+		}
+		// the unique expense_id only fails when a concurrent export took some of them
+		if err := tx.Create(&items).Error; err != nil {
+			if services.IsUniqueViolation(err) {
+				return rules.ErrAlreadyExported
+			}
+			return err
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, rules.ErrNothingToExport):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, rules.ErrAlreadyExported):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create accounting export"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionAccountingExport,
+		EntityType: constants.AuditEntityAccounting,
+		EntityID:   export.ID,
+		After:      export,
+	})
+
+	c.JSON(http.StatusCreated, export)
+}
+
+// GetAccountingExports godoc
+// @Summary List accounting exports
+// @Description Earlier exports, newest first (manager only)
+// @Tags Accounting
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} AccountingExportsListResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/accounting/exports [get]
+func GetAccountingExports(c *gin.Context) {
+	var exports []models.AccountingExport
+	var total int64
+
+	page, limit, offset := helpers.GetPagination(c)
+
+	query := db.DB.Model(&models.AccountingExport{})
+
+	// count first
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count accounting exports"})
+		return
+	}
+
+	// fetch paginated data
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&exports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch accounting exports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountingExportsListResponse{
+		Data: exports,
+		Meta: PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// DownloadAccountingExport godoc
+// @Summary Download an accounting export
+// @Description The journal of an export, one entry per expense debiting its category's GL account and crediting the payment account. Generic CSV, QuickBooks IIF or a Xero manual journal CSV, dated in Asia/Jakarta (manager only)
+// @Tags Accounting
+// @Security CookieAuth
+// @Produce text/csv
+// @Produce text/plain
+// @Param id path int true "Accounting export ID"
+// @Param format query string false "csv, iif or xero, the export's format by default"
+// @Success 200 {string} string "Journal file"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/accounting/exports/{id}/download [get]
+func DownloadAccountingExport(c *gin.Context) {
+	var export models.AccountingExport
+	if err := db.DB.First(&export, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Accounting export not found"})
+		return
+	}
+
+	format := export.Format
+	if value := c.Query("format"); value != "" {
+		var err error
+		if format, err = rules.AccountingFormat(constants.AccountingFormat(value)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// the accounts come from the export, not the current mapping
+	rows, err := db.DB.Model(&models.Expense{}).
+		Select("expenses.id, expenses.uuid, expenses.description, expenses.amount_idr, "+
+			"expenses.processed_at, expenses.payment_reference, users.name AS employee_name, "+
+			"accounting_export_items.debit_account_code, accounting_export_items.debit_account_name, "+
+			"accounting_export_items.credit_account_code, accounting_export_items.credit_account_name").
+		Joins("JOIN accounting_export_items ON accounting_export_items.expense_id = expenses.id").
+		Joins("LEFT JOIN users ON users.id = expenses.user_id").
+		Where("accounting_export_items.accounting_export_id = ?", export.ID).
+		Order("expenses.processed_at ASC, expenses.id ASC").
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expenses"})
+		return
+	}
+	defer rows.Close()
+
+	writer, err := helpers.NewJournalWriter(c, format, "journal-"+strconv.FormatInt(export.ID, 10))
+	if err != nil {
+		log.Printf("Failed to start accounting export: %v", err)
+		return
+	}
+
+	// rows are streamed, an error past this point can only cut the file short
+	for rows.Next() {
+		var row accountingExportRow
+		if err := db.DB.ScanRows(rows, &row); err != nil {
+			log.Printf("Failed to export accounting row: %v", err)
+			break
+		}
+
+		if err := writer.WriteEntry(helpers.AccountingEntry{
+			Number:           "EXP-" + strconv.FormatInt(row.ID, 10),
+			Date:             row.ProcessedAt,
+			Employee:         row.EmployeeName,
+			Description:      row.Description,
+			ExpenseUUID:      row.UUID.String(),
+			PaymentReference: formatOptionalString(row.PaymentReference),
+			AmountIDR:        row.AmountIDR,
+			Debit:            constants.GLAccount{Code: row.DebitAccountCode, Name: row.DebitAccountName},
+			Credit:           constants.GLAccount{Code: row.CreditAccountCode, Name: row.CreditAccountName},
+		}); err != nil {
+			log.Printf("Failed to write accounting export: %v", err)
+			break
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Printf("Failed to finish accounting export: %v", err)
+	}
+}
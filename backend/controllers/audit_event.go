@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// audit events are listed newest first
+var auditEventSort = helpers.Sort{Key: "created_at", Column: "created_at", Desc: true}
+
+// GetAuditEvents godoc
+// @Summary Get audit events
+// @Description Get paginated audit events, newest first: logins, user, approval, policy and delegation changes, credential changes and payment retries (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number, ignored when cursor is given"
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Keyset paging, empty for the first page then meta.next_cursor"
+// @Param actor_id query int false "Filter by actor user ID"
+// @Param action query string false "Filter by action, a trailing dot matches a group, e.g. user."
+// @Param entity_type query string false "Filter by entity type" Enums(user, session, invite, api_key, expense, approval, sla_policy, delegation)
+// @Param entity_id query string false "Filter by entity ID"
+// @Param from query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Created before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success 200 {object} object{data=[]models.AuditEvent,meta=PaginationMeta}
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/audit-events [get]
+func GetAuditEvents(c *gin.Context) {
+	var events []models.AuditEvent
+	var total int64
+
+	page, limit, offset := helpers.GetPagination(c)
+
+	cursor, keyset, err := helpers.GetCursor(c, auditEventSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := db.DB.Model(&models.AuditEvent{})
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if action := c.Query("action"); action != "" {
+		if strings.HasSuffix(action, ".") {
+			query = query.Where("action LIKE ?", action+"%")
+		} else {
+			query = query.Where("action = ?", action)
+		}
+	}
+	if entityType := c.Query("entity_type"); entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count audit events"})
+		return
+	}
+
+	if keyset {
+		page = 0
+		query = helpers.ApplyCursor(query, auditEventSort, "id", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
+
+	if err := query.
+		Order(auditEventSort.OrderBy("id")).
+		Limit(limit + 1).
+		Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit events"})
+		return
+	}
+
+	events, next := helpers.NextCursor(events, limit, auditEventSort, func(event models.AuditEvent) (interface{}, int64) {
+		return event.CreatedAt, event.ID
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+		"meta": PaginationMeta{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			NextCursor: next,
+		},
+	})
+}
@@ -2,16 +2,19 @@ package controllers
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"backend/constants"
 	"backend/db"
+	"backend/helpers"
 	"backend/models"
+	"backend/rules"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,17 +24,23 @@ type LoginResponse struct {
 	Role  constants.UserRole `json:"role" example:"constants.UserRoleUser"`
 	Name  string             `json:"name" example:"John Doe"`
 	Email string             `json:"email" example:"john@example.com"`
+
+	// the role requires a second factor that is not enrolled yet, approvals stay blocked until it is
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty" example:"false"`
 }
 
 // @Summary User login
-// @Description Authenticate user with email and password, returns JWT token
+// @Description Authenticate user with email and password, returns a short-lived JWT and sets a rotating refresh token cookie.
+// @Description Users with two-factor authentication get a 202 with a token for /auth/login/2fa instead.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body object{email=string,password=string} true "Login credentials"
 // @Success 200 {object} LoginResponse
+// @Success 202 {object} TwoFactorChallengeResponse
 // @Failure 400 {object} httputil.HTTPError
 // @Failure 401 {object} httputil.HTTPError
+// @Failure 429 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /login [post]
 func Login(c *gin.Context) {
@@ -45,59 +54,95 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if retryAfter, err := checkLoginThrottle(c, email); err != nil {
+		helpers.RecordLoginAttempt(db.DB, c, nil, email, constants.LoginResultThrottled)
+		loginThrottled(c, retryAfter, err)
+		return
+	}
+
+	// unknown emails still pay for a bcrypt comparison so timing does not reveal them
 	var user models.User
-	if err := db.DB.First(&user, "email = ?", input.Email).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	found := db.DB.First(&user, "email = ?", email).Error == nil
+	hash := user.PasswordHash
+	if !found || hash == "" {
+		hash = dummyPasswordHash
+	}
+	// service accounts only authenticate with API keys
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(input.Password)); err != nil || !found || user.PasswordHash == "" || user.ServiceAccount {
+		var userID *int64
+		if found {
+			userID = &user.ID
+		}
+		helpers.RecordLoginAttempt(db.DB, c, userID, email, constants.LoginResultInvalidCredentials)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": rules.ErrInvalidCredentials.Error()})
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword(
-		[]byte(user.PasswordHash),
-		[]byte(input.Password),
-	); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password doesn't match"})
+	if err := rules.CanAuthenticate(&user); err != nil {
+		helpers.RecordLoginAttempt(db.DB, c, &user.ID, email, constants.LoginResultDeactivated)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Generate JWT token
-	secret := os.Getenv("JWT_SECRET")
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 24 * 1).Unix(), // 7 days
+	// the password is right, the session only starts after the second factor
+	if user.HasTwoFactor() {
+		mfaToken, err := helpers.IssueMFAToken(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusAccepted, TwoFactorChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int(helpers.MFATokenTTL().Seconds()),
+		})
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	accessToken, refreshToken, err := startSession(c, &user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// Get environment
-	maxAge := 60 * 60 * 24 * 1
-
-	c.SetCookie(
-		"token",
-		tokenString,
-		maxAge, // 24 hours
-		"/",
-		"",
-		false,
-		true, // httpOnly
-	)
-
-	// Set user info cookies (accessible by frontend)
-	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("user_id=%d; Path=/; Max-Age=%d; SameSite=Lax", user.ID, maxAge))
-	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("role=%s; Path=/; Max-Age=%d; SameSite=Lax", user.Role, maxAge))
-	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("user_name=%s; Path=/; Max-Age=%d; SameSite=Lax", user.Name, maxAge))
+	helpers.RecordLoginAttempt(db.DB, c, &user.ID, email, constants.LoginResultSuccess)
+	helpers.SetAuthCookies(c, &user, accessToken, refreshToken)
 
 	// Also return in response body for immediate use
 	c.JSON(http.StatusOK, LoginResponse{
-		Token: tokenString,
+		Token: accessToken,
 		ID:    uint(user.ID),
 		Role:  user.Role,
 		Name:  user.Name,
 		Email: user.Email,
+
+		TwoFactorSetupRequired: rules.LoadTwoFactorPolicy().Requires(&user),
 	})
 }
+
+// dummyPasswordHash is compared against when the account has no password
+var dummyPasswordHash = func() string {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	return string(hash)
+}()
+
+// checkLoginThrottle applies rules.LoginThrottlePolicy to the email and the client address.
+// The counters are best effort, a database error lets the attempt through.
+func checkLoginThrottle(c *gin.Context, email string) (time.Duration, error) {
+	policy := rules.LoadLoginThrottlePolicy()
+	now := time.Now().UTC()
+
+	failures, err := helpers.CountLoginFailures(db.DB, email, c.ClientIP(), now.Add(-policy.Window))
+	if err != nil {
+		log.Printf("Failed to count login failures for %s: %v", email, err)
+		return 0, nil
+	}
+	return policy.Check(failures, now)
+}
+
+func loginThrottled(c *gin.Context, retryAfter time.Duration, err error) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", fmt.Sprint(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after": seconds})
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"backend/db"
+	"backend/helpers"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserDashboard godoc
+// @Summary Personal spending summary
+// @Description Totals of the authenticated user's expenses this month, quarter and year (UTC): reimbursed by the time they were paid (processed_at), awaiting payment, pending and rejected by the time they were submitted. Also the average time to payment and the largest expense against the per-expense limits
+// @Tags Expenses
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} services.SpendingSummary
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /user/dashboard [get]
+func UserDashboard(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	summary, err := services.UserSpendingSummary(db.DB, user.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute spending summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
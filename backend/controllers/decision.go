@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"backend/actions"
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/workers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BulkDecisionRequest struct {
+	ExpenseIDs   []int64 `json:"expense_ids" binding:"required,min=1,max=100" example:"1,2,3"`
+	Decision     string  `json:"decision" binding:"required,oneof=approve reject" example:"approve"`
+	Notes        string  `json:"notes" example:"Approved in bulk"`
+	OnBehalfOfID *int64  `json:"on_behalf_of_id" example:"1"`
+}
+
+type BulkDecisionResult struct {
+	ExpenseID int64                   `json:"expense_id" example:"1"`
+	Success   bool                    `json:"success" example:"true"`
+	Status    constants.ExpenseStatus `json:"status,omitempty" example:"approved"`
+	Error     string                  `json:"error,omitempty" example:"expense is not pending for approval"`
+}
+
+type BulkDecisionResponse struct {
+	Data      []BulkDecisionResult `json:"data"`
+	Succeeded int                  `json:"succeeded" example:"2"`
+	Failed    int                  `json:"failed" example:"1"`
+}
+
+// decisionError carries the HTTP status a failed decision maps to
+type decisionError struct {
+	Status  int
+	Message string
+}
+
+// BulkDecision godoc
+// @Summary Approve or reject expenses in bulk
+// @Description Apply the same decision to several expenses, each one goes through the regular approval rules (manager only, browser session, not API keys)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body BulkDecisionRequest true "Bulk decision payload"
+// @Success 200 {object} BulkDecisionResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 403 {object} httputil.HTTPError
+// @Router /manager/expenses/bulk-decision [post]
+func BulkDecision(c *gin.Context) {
+	var input BulkDecisionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	approver, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	decision := constants.ApprovalStatusApproved
+	if input.Decision == "reject" {
+		decision = constants.ApprovalStatusRejected
+	}
+
+	response := BulkDecisionResponse{Data: []BulkDecisionResult{}}
+	seen := map[int64]bool{}
+	worker := workers.NewPaymentWorker()
+
+	for _, expenseID := range input.ExpenseIDs {
+		if seen[expenseID] {
+			continue
+		}
+		seen[expenseID] = true
+
+		result := BulkDecisionResult{ExpenseID: expenseID}
+		updatedExpense, decisionErr := decideExpense(c, approver, strconv.FormatInt(expenseID, 10), decision, input.Notes, input.OnBehalfOfID)
+		if decisionErr != nil {
+			result.Error = decisionErr.Message
+			response.Failed++
+		} else {
+			result.Success = true
+			result.Status = updatedExpense.Status
+			response.Succeeded++
+
+			if updatedExpense.Status == constants.ExpenseStatusApproved {
+				worker.ProcessExpensePaymentAsync(updatedExpense.ID)
+			}
+		}
+
+		response.Data = append(response.Data, result)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// decideExpense runs an approve or reject decision through the action rules and saves it with its audit log
+func decideExpense(c *gin.Context, approver models.User, expenseID string, decision constants.ApprovalStatus, notes string, requestedOnBehalfOf *int64) (*models.Expense, *decisionError) {
+	approverID := approver.ID
+
+	var expense models.Expense
+	if err := db.DB.Preload("Approval").First(&expense, "id = ?", expenseID).Error; err != nil {
+		return nil, &decisionError{http.StatusNotFound, "Expense not found"}
+	}
+	if expense.Approval == nil {
+		return nil, &decisionError{http.StatusBadRequest, "Expense has no approval record"}
+	}
+	approvalBefore := *expense.Approval
+
+	canDecide, reason := rules.CanApproveExpense, "Expense approved"
+	if decision == constants.ApprovalStatusRejected {
+		canDecide, reason = rules.CanRejectExpense, "Expense rejected"
+	}
+	if err := canDecide(expense.Status); err != nil {
+		return nil, &decisionError{http.StatusBadRequest, err.Error()}
+	}
+
+	// part of log
+	originalStatus := expense.Status
+
+	onBehalfOf, err := resolveOnBehalfOf(approverID, &expense, requestedOnBehalfOf)
+	if errors.Is(err, rules.ErrNoActiveDelegation) || errors.Is(err, rules.ErrDelegatedOwnExpense) {
+		return nil, &decisionError{http.StatusForbidden, err.Error()}
+	}
+	if err != nil {
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to resolve delegation"}
+	}
+	onBehalfOfID, reason := decisionAuthority(reason, onBehalfOf)
+
+	superiorIDs, priorApproverIDs, err := separationOfDutiesContext(approverID, expense.ID)
+	if err != nil {
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to load approver reporting line"}
+	}
+	var principalSuperiorIDs []int64
+	if onBehalfOfID != nil {
+		if principalSuperiorIDs, err = helpers.GetManagerChain(db.DB, *onBehalfOfID); err != nil {
+			return nil, &decisionError{http.StatusInternalServerError, "Failed to load approver reporting line"}
+		}
+	}
+
+	var updatedExpense *models.Expense
+	var updatedApproval *models.Approval
+	if decision == constants.ApprovalStatusApproved {
+		updatedExpense, updatedApproval, err = actions.ApproveExpense(actions.ApproveExpenseInput{
+			Expense:             &expense,
+			ApproverID:          &approverID,
+			OnBehalfOfID:        onBehalfOfID,
+			Notes:               notes,
+			SeparationOfDuties:  rules.LoadSeparationOfDutiesPolicy(),
+			ApproverSuperiorIDs: superiorIDs,
+			PriorApproverIDs:    priorApproverIDs,
+
+			PrincipalSuperiorIDs: principalSuperiorIDs,
+		})
+	} else {
+		updatedExpense, updatedApproval, err = actions.RejectExpense(actions.RejectExpenseInput{
+			Expense:             &expense,
+			ApproverID:          &approverID,
+			OnBehalfOfID:        onBehalfOfID,
+			Notes:               notes,
+			SeparationOfDuties:  rules.LoadSeparationOfDutiesPolicy(),
+			ApproverSuperiorIDs: superiorIDs,
+			PriorApproverIDs:    priorApproverIDs,
+
+			PrincipalSuperiorIDs: principalSuperiorIDs,
+		})
+	}
+	if err != nil {
+		if rules.IsSeparationOfDutiesViolation(err) {
+			recordBlockedDecision(&expense, approverID, err)
+			return nil, &decisionError{http.StatusForbidden, err.Error()}
+		}
+		return nil, &decisionError{http.StatusBadRequest, err.Error()}
+	}
+	if len(updatedApproval.Steps) > len(approvalBefore.Steps) {
+		step := updatedApproval.Steps[len(updatedApproval.Steps)-1].Step
+		reason = fmt.Sprintf("%s (step %d of %d)", reason, step, rules.RequiredApprovalSteps(updatedExpense.AmountIDR))
+	}
+
+	audit, err := actions.ExpenseAuditLog(actions.ExpenseAuditLogInput{
+		ExpenseID:    updatedExpense.ID,
+		ActorID:      &approverID,
+		OnBehalfOfID: onBehalfOfID,
+		FromStatus:   originalStatus,
+		ToStatus:     updatedExpense.Status,
+		Reason:       reason,
+	})
+	if err != nil {
+		return nil, &decisionError{http.StatusBadRequest, err.Error()}
+	}
+
+	tx := db.DB.Begin()
+
+	// only the first of concurrent decisions on the expense changes its status
+	result := tx.Model(&models.Expense{}).
+		Where("id = ? AND status = ?", updatedExpense.ID, originalStatus).
+		Updates(map[string]interface{}{"status": updatedExpense.Status})
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to update expense"}
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, &decisionError{http.StatusConflict, rules.ErrExpenseNotPendingApproval.Error()}
+	}
+
+	// and of concurrent approvals of the same step
+	result = tx.Model(&models.Approval{}).
+		Where("id = ? AND step = ?", updatedApproval.ID, approvalBefore.Step).
+		Update("step", updatedApproval.Step)
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to update approval"}
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, &decisionError{http.StatusConflict, rules.ErrExpenseNotPendingApproval.Error()}
+	}
+
+	if err := tx.Omit("Steps").Save(&updatedApproval).Error; err != nil {
+		tx.Rollback()
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to update approval"}
+	}
+	for i := range updatedApproval.Steps {
+		if updatedApproval.Steps[i].ID != 0 {
+			continue
+		}
+		if err := tx.Create(&updatedApproval.Steps[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, &decisionError{http.StatusInternalServerError, "Failed to record approval step"}
+		}
+	}
+
+	if err := helpers.AppendExpenseAuditLog(tx, audit); err != nil {
+		tx.Rollback()
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to create audit log"}
+	}
+
+	if originalStatus != constants.ExpenseStatusApproved && updatedExpense.Status == constants.ExpenseStatusApproved {
+		if err := helpers.PostExpenseApproval(tx, updatedExpense, audit.CreatedAt); err != nil {
+			tx.Rollback()
+			return nil, &decisionError{http.StatusInternalServerError, "Failed to post ledger entry"}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, &decisionError{http.StatusInternalServerError, "Failed to save decision"}
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionApprovalDecide,
+		EntityType: constants.AuditEntityApproval,
+		EntityID:   updatedApproval.ID,
+		Before:     approvalBefore,
+		After:      updatedApproval,
+		Metadata:   map[string]interface{}{"expense_id": updatedExpense.ID},
+	})
+
+	return updatedExpense, nil
+}
+
+// decisionAuthority makes a delegated decision explicit in the audit trail
+func decisionAuthority(reason string, onBehalfOf *models.User) (*int64, string) {
+	if onBehalfOf == nil {
+		return nil, reason
+	}
+	return &onBehalfOf.ID, fmt.Sprintf("%s by delegate on behalf of %s", reason, onBehalfOf.Name)
+}
+
+// separationOfDutiesContext loads the approver's manager chain and who approved the earlier
+// steps of the expense, in person or through a delegate
+func separationOfDutiesContext(approverID, expenseID int64) (superiorIDs, priorApproverIDs []int64, err error) {
+	superiorIDs, err = helpers.GetManagerChain(db.DB, approverID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var steps []models.ApprovalStep
+	if err := db.DB.Joins("JOIN approvals ON approvals.id = approval_steps.approval_id").
+		Where("approvals.expense_id = ?", expenseID).
+		Find(&steps).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, step := range steps {
+		priorApproverIDs = append(priorApproverIDs, step.ApproverID)
+		if step.OnBehalfOfID != nil {
+			priorApproverIDs = append(priorApproverIDs, *step.OnBehalfOfID)
+		}
+	}
+
+	return superiorIDs, priorApproverIDs, nil
+}
+
+// recordBlockedDecision keeps a trace of decisions refused by separation of duties, status is unchanged
+func recordBlockedDecision(expense *models.Expense, approverID int64, reason error) {
+	audit, err := actions.ExpenseAuditLog(actions.ExpenseAuditLogInput{
+		ExpenseID:  expense.ID,
+		ActorID:    &approverID,
+		FromStatus: expense.Status,
+		ToStatus:   expense.Status,
+		Reason:     "Blocked: " + reason.Error(),
+	})
+	if err != nil {
+		log.Printf("Failed to build blocked decision log for expense %d: %v", expense.ID, err)
+		return
+	}
+
+	if err := helpers.AppendExpenseAuditLog(db.DB, audit); err != nil {
+		log.Printf("Failed to record blocked decision for expense %d: %v", expense.ID, err)
+	}
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateDelegationRequest struct {
+	DelegateID int64     `json:"delegate_id" binding:"required" example:"2"`
+	StartsAt   time.Time `json:"starts_at" binding:"required" example:"2026-01-05T00:00:00Z"`
+	EndsAt     time.Time `json:"ends_at" binding:"required" example:"2026-01-12T00:00:00Z"`
+	Reason     string    `json:"reason" example:"Annual leave"`
+}
+
+// CreateDelegation godoc
+// @Summary Delegate approvals
+// @Description Let another manager act on your approvals during a date range (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateDelegationRequest true "Delegation payload"
+// @Success 201 {object} models.ApprovalDelegation
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/delegations [post]
+func CreateDelegation(c *gin.Context) {
+	var input CreateDelegationRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	delegator, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := rules.ValidateDelegation(delegator.ID, input.DelegateID, input.StartsAt, input.EndsAt, time.Now().UTC()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var delegate models.User
+	if err := db.DB.First(&delegate, input.DelegateID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Delegate not found"})
+		return
+	}
+
+	if err := rules.CanApproveRole(&delegate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Delegate must be able to approve expenses"})
+		return
+	}
+
+	delegation := models.ApprovalDelegation{
+		DelegatorID: delegator.ID,
+		DelegateID:  delegate.ID,
+		StartsAt:    input.StartsAt.UTC(),
+		EndsAt:      input.EndsAt.UTC(),
+		Reason:      input.Reason,
+	}
+
+	if err := db.DB.Create(&delegation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save delegation"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionDelegationCreate,
+		EntityType: constants.AuditEntityDelegation,
+		EntityID:   delegation.ID,
+		After:      delegation,
+	})
+
+	c.JSON(http.StatusCreated, delegation)
+}
+
+// GetDelegations godoc
+// @Summary List delegations
+// @Description List delegations given by or to the authenticated manager (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param active query bool false "Only delegations active right now"
+// @Success 200 {object} object{data=[]models.ApprovalDelegation}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/delegations [get]
+func GetDelegations(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	query := db.DB.Model(&models.ApprovalDelegation{}).
+		Preload("Delegator", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
+		Preload("Delegate", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
+		Where("delegator_id = ? OR delegate_id = ?", user.ID, user.ID)
+
+	if c.Query("active") == "true" {
+		now := time.Now().UTC()
+		query = query.Where("revoked_at IS NULL AND starts_at <= ? AND ends_at > ?", now, now)
+	}
+
+	var delegations []models.ApprovalDelegation
+	if err := query.Order("starts_at DESC").Find(&delegations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch delegations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": delegations})
+}
+
+// RevokeDelegation godoc
+// @Summary Revoke a delegation
+// @Description Revoke a delegation you created (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Delegation ID"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/delegations/{id} [delete]
+func RevokeDelegation(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var delegation models.ApprovalDelegation
+	if err := db.DB.First(&delegation, "id = ? AND delegator_id = ?", c.Param("id"), user.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delegation not found"})
+		return
+	}
+
+	if delegation.RevokedAt == nil {
+		now := time.Now().UTC()
+		delegation.RevokedAt = &now
+		if err := db.DB.Save(&delegation).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke delegation"})
+			return
+		}
+
+		helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+			Action:     constants.AuditActionDelegationRevoke,
+			EntityType: constants.AuditEntityDelegation,
+			EntityID:   delegation.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Delegation has been revoked",
+	})
+}
+
+// resolveOnBehalfOf finds whose authority the approver is using. An explicit request must be
+// backed by an active delegation; otherwise the submitter's manager is used when they delegated
+// to the approver. Returns nil when the approver acts on their own authority. A delegate never
+// decides on the delegator's own expense.
+func resolveOnBehalfOf(approverID int64, expense *models.Expense, requested *int64) (*models.User, error) {
+	now := time.Now().UTC()
+
+	if requested != nil {
+		if *requested == approverID {
+			return nil, nil
+		}
+		if *requested == expense.UserID {
+			return nil, rules.ErrDelegatedOwnExpense
+		}
+		delegation, err := helpers.FindActiveDelegation(db.DB, *requested, approverID, now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, rules.ErrNoActiveDelegation
+		}
+		if err != nil {
+			return nil, err
+		}
+		return delegation.Delegator, nil
+	}
+
+	var submitter models.User
+	if err := db.DB.Select("id", "manager_id").First(&submitter, expense.UserID).Error; err != nil {
+		return nil, err
+	}
+	if submitter.ManagerID == nil || *submitter.ManagerID == approverID || *submitter.ManagerID == expense.UserID {
+		return nil, nil
+	}
+
+	delegation, err := helpers.FindActiveDelegation(db.DB, *submitter.ManagerID, approverID, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return delegation.Delegator, nil
+}
@@ -1,13 +1,18 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"backend/actions"
+	"backend/constants"
 	"backend/db"
 	"backend/helpers"
 	"backend/rules"
+	"backend/services"
 	"backend/workers"
 
 	"github.com/gin-gonic/gin"
@@ -17,9 +22,10 @@ import (
 )
 
 type PaginationMeta struct {
-	Page  int   `json:"page" example:"1"`
-	Limit int   `json:"limit" example:"10"`
-	Total int64 `json:"total" example:"42"`
+	Page       int     `json:"page,omitempty" example:"1"` // left out when paging by cursor
+	Limit      int     `json:"limit" example:"10"`
+	Total      int64   `json:"total" example:"42"`
+	NextCursor *string `json:"next_cursor,omitempty" example:"eyJzIjoidXBkYXRlZF9hdCJ9"` // nil on the last page
 }
 
 type ExpensesListResponse struct {
@@ -31,8 +37,8 @@ type MessageResponse struct {
 }
 
 type StatusExpenseRequest struct {
-	ApproverID *int64 `json:"approver_id" example:"99"`
-	Notes      string `json:"notes" example:"Approved"`
+	Notes        string `json:"notes" example:"Approved"`
+	OnBehalfOfID *int64 `json:"on_behalf_of_id" example:"1"` // optional, manager who delegated to you
 }
 
 type HealthCheckResponse struct {
@@ -77,17 +83,44 @@ func HealthCheck(c *gin.Context) {
 	})
 }
 
+// sort keys of the manager expense list, mapped to trusted column expressions
+var expenseSorts = map[string]string{
+	"updated_at":   "expenses.updated_at",
+	"submitted_at": "expenses.submitted_at",
+	"created_at":   "expenses.created_at",
+	"amount":       "expenses.amount_idr",
+	"status":       "expenses.status",
+	"category":     "expenses.category",
+}
+
+// a user's own expenses, most recently changed first
+var userExpenseSort = helpers.Sort{Key: "updated_at", Column: "expenses.updated_at", Desc: true}
+
 // GetExpenses godoc
 // @Summary Get all expenses (manager only)
-// @Description Get paginated list of all expenses (manager only)
+// @Description Search all expenses (manager only). Filters combine with AND, status and category take comma separated lists.
 // @Tags ManagerExpenses
 // @Security CookieAuth
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number"
+// @Param page query int false "Page number, ignored when cursor is given"
 // @Param limit query int false "Page size"
-// @Param status query string false "Filter by expense status"
+// @Param cursor query string false "Keyset paging, empty for the first page then meta.next_cursor"
+// @Param status query string false "Filter by expense status, e.g. pending,approved"
+// @Param flagged query bool false "Only flagged expenses, e.g. submitter deactivated"
+// @Param q query string false "Free-text search over the description"
+// @Param min_amount query int false "Minimum amount in IDR, inclusive"
+// @Param max_amount query int false "Maximum amount in IDR, inclusive"
+// @Param submitted_from query string false "Submitted at or after, RFC 3339 or YYYY-MM-DD"
+// @Param submitted_to query string false "Submitted before, a date includes that whole day"
+// @Param user_id query int false "Submitted by this user"
+// @Param category query string false "Filter by category, e.g. travel,meals"
+// @Param approver_id query int false "Approved or rejected by this user"
+// @Param assignee_id query int false "Waiting on this approver"
+// @Param sort query string false "updated_at, submitted_at, created_at, amount, status or category" default(updated_at)
+// @Param order query string false "asc or desc" default(desc)
 // @Success 200 {object} ExpensesListResponse
+// @Failure 400 {object} httputil.HTTPError
 // @Failure 401 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /manager/expenses [get]
@@ -96,16 +129,16 @@ func GetExpenses(c *gin.Context) {
 	var total int64
 
 	page, limit, offset := helpers.GetPagination(c)
-	status := c.Query("status")
-
-	query := db.DB.Model(&models.Expense{}).
-		Preload("User", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "name")
-		}).
-		Preload("Approval")
 
-	if status != "" {
-		query = query.Where("status = ?", status)
+	query, sort, err := expenseSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cursor, keyset, err := helpers.GetCursor(c, sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	// count first
@@ -114,26 +147,150 @@ func GetExpenses(c *gin.Context) {
 		return
 	}
 
-	// fetch paginated data
+	if keyset {
+		page = 0
+		query = helpers.ApplyCursor(query, sort, "expenses.id", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
+
+	// fetch paginated data, one extra row tells whether a next page exists
 	if err := query.
-		Order("updated_at DESC").
-		Limit(limit).
-		Offset(offset).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
+		Preload("Approval").
+		Order(sort.OrderBy("expenses.id")).
+		Limit(limit + 1).
 		Find(&expenses).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expenses"})
 		return
 	}
 
+	expenses, next := helpers.NextCursor(expenses, limit, sort, expensePosition(sort))
+
 	c.JSON(http.StatusOK, ExpensesListResponse{
 		Data: expenses,
 		Meta: PaginationMeta{
-			Page:  page,
-			Limit: limit,
-			Total: total,
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			NextCursor: next,
 		},
 	})
 }
 
+// expenseSearchQuery applies the filters of GetExpenses and returns the sort to use
+func expenseSearchQuery(c *gin.Context) (*gorm.DB, helpers.Sort, error) {
+	sort, err := helpers.GetSort(c, expenseSorts, "updated_at", "desc")
+	if err != nil {
+		return nil, sort, err
+	}
+
+	submittedFrom, submittedTo, err := helpers.GetTimeRangeOf(c, "submitted_from", "submitted_to")
+	if err != nil {
+		return nil, sort, err
+	}
+
+	minAmount, err := amountQuery(c, "min_amount")
+	if err != nil {
+		return nil, sort, err
+	}
+	maxAmount, err := amountQuery(c, "max_amount")
+	if err != nil {
+		return nil, sort, err
+	}
+	if minAmount != nil && maxAmount != nil && *minAmount > *maxAmount {
+		return nil, sort, rules.ErrInvalidAmountRange
+	}
+
+	query := db.DB.Model(&models.Expense{})
+
+	if statuses := helpers.GetListQuery(c, "status"); len(statuses) > 0 {
+		query = query.Where("expenses.status IN ?", statuses)
+	}
+	if categories := helpers.GetListQuery(c, "category"); len(categories) > 0 {
+		for _, category := range categories {
+			if !rules.IsExpenseCategory(constants.ExpenseCategory(category)) {
+				return nil, sort, rules.ErrInvalidCategory
+			}
+		}
+		query = query.Where("expenses.category IN ?", categories)
+	}
+	if flagged := c.Query("flagged"); flagged != "" {
+		query = query.Where("expenses.flagged = ?", flagged == "true")
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("expenses.user_id = ?", userID)
+	}
+	if approverID := c.Query("approver_id"); approverID != "" {
+		query = query.Where("expenses.id IN (?)", db.DB.Model(&models.Approval{}).Select("expense_id").Where("approver_id = ?", approverID))
+	}
+	if assigneeID := c.Query("assignee_id"); assigneeID != "" {
+		query = query.Where("expenses.id IN (?)", db.DB.Model(&models.Approval{}).Select("expense_id").Where("assignee_id = ? AND status = ?", assigneeID, constants.ApprovalStatusPending))
+	}
+	if minAmount != nil {
+		query = query.Where("expenses.amount_idr >= ?", *minAmount)
+	}
+	if maxAmount != nil {
+		query = query.Where("expenses.amount_idr <= ?", *maxAmount)
+	}
+	if submittedFrom != nil {
+		query = query.Where("expenses.submitted_at >= ?", *submittedFrom)
+	}
+	if submittedTo != nil {
+		query = query.Where("expenses.submitted_at < ?", *submittedTo)
+	}
+	if text := strings.TrimSpace(c.Query("q")); text != "" {
+		query = searchExpenseDescription(query, text)
+	}
+
+	return query, sort, nil
+}
+
+// searchExpenseDescription matches whole words through the full-text index and falls
+// back to the trigram index for fragments, "hote" still finds "Hotel Jakarta".
+// Other databases, sqlite in tests, only get the substring match.
+func searchExpenseDescription(query *gorm.DB, text string) *gorm.DB {
+	pattern := "%" + text + "%"
+	if services.IsPostgres(query) {
+		return query.Where("(expenses.search_vector @@ websearch_to_tsquery('simple', ?) OR expenses.description ILIKE ?)", text, pattern)
+	}
+	return query.Where("LOWER(expenses.description) LIKE ?", strings.ToLower(pattern))
+}
+
+// expensePosition reads the cursor position of an expense in sort
+func expensePosition(sort helpers.Sort) func(models.Expense) (interface{}, int64) {
+	return func(expense models.Expense) (interface{}, int64) {
+		switch sort.Key {
+		case "submitted_at":
+			return expense.SubmittedAt, expense.ID
+		case "created_at":
+			return expense.CreatedAt, expense.ID
+		case "amount":
+			return expense.AmountIDR, expense.ID
+		case "status":
+			return expense.Status, expense.ID
+		case "category":
+			return expense.Category, expense.ID
+		default:
+			return expense.UpdatedAt, expense.ID
+		}
+	}
+}
+
+func amountQuery(c *gin.Context, param string) (*int64, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || amount < 0 {
+		return nil, fmt.Errorf("%s: %w", param, rules.ErrInvalidAmount)
+	}
+	return &amount, nil
+}
+
 // GetUserExpenses godoc
 // @Summary Get user's expenses
 // @Description Get paginated list of expenses for the authenticated user
@@ -141,10 +298,12 @@ func GetExpenses(c *gin.Context) {
 // @Security CookieAuth
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number"
+// @Param page query int false "Page number, ignored when cursor is given"
 // @Param limit query int false "Page size"
+// @Param cursor query string false "Keyset paging, empty for the first page then meta.next_cursor"
 // @Param status query string false "Filter by expense status"
-// @Success 200 {object} object{models.Expense}
+// @Success 200 {object} ExpensesListResponse
+// @Failure 400 {object} httputil.HTTPError
 // @Failure 401 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /expenses [get]
@@ -161,6 +320,12 @@ func GetUserExpenses(c *gin.Context) {
 	page, limit, offset := helpers.GetPagination(c)
 	status := c.Query("status")
 
+	cursor, keyset, err := helpers.GetCursor(c, userExpenseSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	query := db.DB.Model(&models.Expense{}).
 		Preload("User", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name")
@@ -177,21 +342,30 @@ func GetUserExpenses(c *gin.Context) {
 		return
 	}
 
+	if keyset {
+		page = 0
+		query = helpers.ApplyCursor(query, userExpenseSort, "expenses.id", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
+
 	if err := query.
-		Order("updated_at DESC").
-		Limit(limit).
-		Offset(offset).
+		Order(userExpenseSort.OrderBy("expenses.id")).
+		Limit(limit + 1).
 		Find(&expenses).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user expenses"})
 		return
 	}
 
+	expenses, next := helpers.NextCursor(expenses, limit, userExpenseSort, expensePosition(userExpenseSort))
+
 	c.JSON(http.StatusOK, ExpensesListResponse{
 		Data: expenses,
 		Meta: PaginationMeta{
-			Page:  page,
-			Limit: limit,
-			Total: total,
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			NextCursor: next,
 		},
 	})
 }
@@ -215,7 +389,7 @@ func GetExpense(c *gin.Context) {
 	if err := db.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id", "name")
 	}).
-		Preload("Approval").First(&expense, "id = ?", id).Error; err != nil {
+		Preload("Approval.Steps").First(&expense, "id = ?", id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
 		return
 	}
@@ -243,6 +417,14 @@ func CreateExpense(c *gin.Context) {
 		return
 	}
 
+	submitter, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	// expenses are always filed by the signed-in user, separation of duties relies on it
+	input.UserID = submitter.ID
+
 	expense, approval, err := actions.SubmitExpense(input)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -258,14 +440,37 @@ func CreateExpense(c *gin.Context) {
 	}
 
 	approval.ExpenseID = expense.ID
+	if expense.RequiresApproval {
+		if err := assignApprovalSLA(expense, approval); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign approval SLA"})
+			return
+		}
+	}
 	if err := tx.Create(&approval).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save approval"})
 		return
 	}
 
+	// auto-approved, posted at the submission time
+	if expense.Status == constants.ExpenseStatusApproved {
+		if err := helpers.PostExpenseApproval(tx, expense, expense.SubmittedAt); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post ledger entry"})
+			return
+		}
+	}
+
 	tx.Commit()
 
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionExpenseCreate,
+		EntityType: constants.AuditEntityExpense,
+		EntityID:   expense.ID,
+		After:      expense,
+	})
+
 	if expense.AutoApproved {
 		worker := workers.NewPaymentWorker()
 		worker.ProcessExpensePaymentAsync(expense.ID)
@@ -278,7 +483,7 @@ func CreateExpense(c *gin.Context) {
 
 // ApproveExpense godoc
 // @Summary Approve an expense
-// @Description Approve a pending expense (manager only)
+// @Description Approve a pending expense (manager only, browser session, not API keys)
 // @Tags Manager
 // @Security CookieAuth
 // @Accept json
@@ -288,7 +493,9 @@ func CreateExpense(c *gin.Context) {
 // @Success 200 {object} MessageResponse
 // @Failure 400 {object} httputil.HTTPError
 // @Failure 401 {object} httputil.HTTPError
+// @Failure 403 {object} httputil.HTTPError
 // @Failure 404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /manager/expenses/{id}/approve [put]
 func ApproveExpense(c *gin.Context) {
@@ -300,64 +507,26 @@ func ApproveExpense(c *gin.Context) {
 		return
 	}
 
-	var expense models.Expense
-	if err := db.DB.Preload("Approval").First(&expense, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
-		return
-	}
-
-	if err := rules.CanApproveExpense(expense.Status); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// part of log
-	originalStatus := expense.Status
-
-	updatedExpense, updatedApproval, err := actions.ApproveExpense(actions.ApproveExpenseInput{
-		Expense:    &expense,
-		ApproverID: input.ApproverID,
-		Notes:      input.Notes,
-	})
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	audit, err := actions.ExpenseAuditLog(actions.ExpenseAuditLogInput{
-		ExpenseID:  updatedExpense.ID,
-		ActorID:    input.ApproverID,
-		FromStatus: originalStatus,
-		ToStatus:   updatedExpense.Status,
-		Reason:     "Expense approved",
-	})
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	approver, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
 		return
 	}
 
-	tx := db.DB.Begin()
-
-	if err := tx.Save(&updatedExpense).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update expense"})
+	updatedExpense, decisionErr := decideExpense(c, approver, id, constants.ApprovalStatusApproved, input.Notes, input.OnBehalfOfID)
+	if decisionErr != nil {
+		c.JSON(decisionErr.Status, gin.H{"error": decisionErr.Message})
 		return
 	}
 
-	if err := tx.Save(&updatedApproval).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval"})
-		return
-	}
-
-	if err := tx.Create(&audit).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create audit log"})
+	// an earlier step of a multi-step approval waits for the next manager
+	if updatedExpense.Status == constants.ExpenseStatusPending {
+		c.JSON(http.StatusOK, MessageResponse{
+			Message: "Expense approved at this step, it now waits for the next approver",
+		})
 		return
 	}
 
-	tx.Commit()
-
 	worker := workers.NewPaymentWorker()
 	worker.ProcessExpensePaymentAsync(updatedExpense.ID)
 
@@ -368,7 +537,7 @@ func ApproveExpense(c *gin.Context) {
 
 // RejectExpense godoc
 // @Summary Reject an expense
-// @Description Reject a pending expense (manager only)
+// @Description Reject a pending expense (manager only, browser session, not API keys)
 // @Tags Manager
 // @Security CookieAuth
 // @Accept json
@@ -378,7 +547,9 @@ func ApproveExpense(c *gin.Context) {
 // @Success 200 {object} MessageResponse
 // @Failure 400 {object} httputil.HTTPError
 // @Failure 401 {object} httputil.HTTPError
+// @Failure 403 {object} httputil.HTTPError
 // @Failure 404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /manager/expenses/{id}/reject [put]
 func RejectExpense(c *gin.Context) {
@@ -391,63 +562,17 @@ func RejectExpense(c *gin.Context) {
 		return
 	}
 
-	var expense models.Expense
-	if err := db.DB.Preload("Approval").First(&expense, "id = ?", id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
-		return
-	}
-
-	if err := rules.CanRejectExpense(expense.Status); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	approver, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
 		return
 	}
 
-	// part of log
-	originalStatus := expense.Status
-
-	// Update expense & approval
-	updatedExpense, updatedApproval, err := actions.RejectExpense(actions.RejectExpenseInput{
-		Expense:    &expense,
-		ApproverID: input.ApproverID,
-		Notes:      input.Notes,
-	})
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if _, decisionErr := decideExpense(c, approver, id, constants.ApprovalStatusRejected, input.Notes, input.OnBehalfOfID); decisionErr != nil {
+		c.JSON(decisionErr.Status, gin.H{"error": decisionErr.Message})
 		return
 	}
 
-	audit, err := actions.ExpenseAuditLog(actions.ExpenseAuditLogInput{
-		ExpenseID:  updatedExpense.ID,
-		ActorID:    input.ApproverID,
-		FromStatus: originalStatus,
-		ToStatus:   updatedExpense.Status,
-		Reason:     "Expense rejected",
-	})
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	tx := db.DB.Begin()
-	if err := tx.Save(&updatedExpense).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update expense"})
-		return
-	}
-	if err := tx.Save(&updatedApproval).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval"})
-		return
-	}
-
-	if err := tx.Create(&audit).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create audit log"})
-		return
-	}
-
-	tx.Commit()
-
 	c.JSON(http.StatusOK, MessageResponse{
 		Message: "Expense has been rejected",
 	})
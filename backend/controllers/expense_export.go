@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExpenseExportRow is one expense of an export, with the names a reader needs
+type ExpenseExportRow struct {
+	ID            int64
+	UUID          uuid.UUID
+	SubmittedAt   time.Time
+	SubmitterName string
+	Category      constants.ExpenseCategory
+	Description   string
+	AmountIDR     int64 `gorm:"column:amount_idr"`
+	Status        constants.ExpenseStatus
+	ApproverName  *string
+	ProcessedAt   *time.Time
+	Flagged       bool
+}
+
+const expenseExportColumns = "expenses.id, expenses.uuid, expenses.submitted_at, submitters.name AS submitter_name, " +
+	"expenses.category, expenses.description, expenses.amount_idr, expenses.status, " +
+	"approvers.name AS approver_name, expenses.processed_at, expenses.flagged"
+
+var expenseExportHeader = []string{
+	"ID", "UUID", "Submitted At (" + helpers.ExportTimeZone + ")", "Submitter", "Category", "Description",
+	"Amount (IDR)", "Status", "Approver", "Paid At (" + helpers.ExportTimeZone + ")", "Flagged",
+}
+
+// ExportExpenses godoc
+// @Summary Export expenses as CSV or XLSX
+// @Description Download every expense matching the filters and sort of the manager listing. Amounts are IDR, timestamps Asia/Jakarta (manager only)
+// @Tags ManagerExpenses
+// @Security CookieAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx" default(csv)
+// @Param status query string false "Filter by expense status, e.g. pending,approved"
+// @Param flagged query bool false "Only flagged expenses, e.g. submitter deactivated"
+// @Param q query string false "Free-text search over the description"
+// @Param min_amount query int false "Minimum amount in IDR, inclusive"
+// @Param max_amount query int false "Maximum amount in IDR, inclusive"
+// @Param submitted_from query string false "Submitted at or after, RFC 3339 or YYYY-MM-DD"
+// @Param submitted_to query string false "Submitted before, a date includes that whole day"
+// @Param user_id query int false "Submitted by this user"
+// @Param category query string false "Filter by category, e.g. travel,meals"
+// @Param approver_id query int false "Approved or rejected by this user"
+// @Param assignee_id query int false "Waiting on this approver"
+// @Param sort query string false "updated_at, submitted_at, created_at, amount, status or category" default(updated_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Success 200 {string} string "CSV or XLSX file"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/expenses/export [get]
+func ExportExpenses(c *gin.Context) {
+	query, sort, err := expenseSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExpenseExport(c, query, sort, "expenses")
+}
+
+// ExportUserExpenses godoc
+// @Summary Export own expenses as CSV or XLSX
+// @Description Download the authenticated user's expenses, with the filters and sort of the manager listing. Amounts are IDR, timestamps Asia/Jakarta
+// @Tags Expenses
+// @Security CookieAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv or xlsx" default(csv)
+// @Param status query string false "Filter by expense status, e.g. pending,approved"
+// @Param q query string false "Free-text search over the description"
+// @Param min_amount query int false "Minimum amount in IDR, inclusive"
+// @Param max_amount query int false "Maximum amount in IDR, inclusive"
+// @Param submitted_from query string false "Submitted at or after, RFC 3339 or YYYY-MM-DD"
+// @Param submitted_to query string false "Submitted before, a date includes that whole day"
+// @Param category query string false "Filter by category, e.g. travel,meals"
+// @Param sort query string false "updated_at, submitted_at, created_at, amount, status or category" default(updated_at)
+// @Param order query string false "asc or desc" default(desc)
+// @Success 200 {string} string "CSV or XLSX file"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /user/expenses/export [get]
+func ExportUserExpenses(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	query, sort, err := expenseSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExpenseExport(c, query.Where("expenses.user_id = ?", user.ID), sort, "my-expenses")
+}
+
+// writeExpenseExport streams the rows of query, nothing is held in memory
+func writeExpenseExport(c *gin.Context, query *gorm.DB, sort helpers.Sort, basename string) {
+	format, err := helpers.GetExportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := query.
+		Select(expenseExportColumns).
+		Joins("LEFT JOIN users AS submitters ON submitters.id = expenses.user_id").
+		Joins("LEFT JOIN approvals ON approvals.expense_id = expenses.id").
+		Joins("LEFT JOIN users AS approvers ON approvers.id = approvals.approver_id").
+		Order(sort.OrderBy("expenses.id")).
+		Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch expenses"})
+		return
+	}
+	defer rows.Close()
+
+	filename := basename + "-" + helpers.ExportTime(time.Now()).Format("20060102")
+	writer, err := helpers.NewExportWriter(c, format, filename, "Expenses", expenseExportHeader)
+	if err != nil {
+		log.Printf("Failed to start expense export: %v", err)
+		return
+	}
+
+	// rows are streamed, an error past this point can only cut the file short
+	for rows.Next() {
+		var row ExpenseExportRow
+		if err := db.DB.ScanRows(rows, &row); err != nil {
+			log.Printf("Failed to export expense row: %v", err)
+			break
+		}
+
+		flagged := "no"
+		if row.Flagged {
+			flagged = "yes"
+		}
+		if err := writer.WriteRow([]helpers.ExportCell{
+			helpers.TextCell(strconv.FormatInt(row.ID, 10)),
+			helpers.TextCell(row.UUID.String()),
+			helpers.TimeCell(&row.SubmittedAt),
+			helpers.TextCell(row.SubmitterName),
+			helpers.TextCell(string(row.Category)),
+			helpers.TextCell(row.Description),
+			helpers.AmountCell(row.AmountIDR),
+			helpers.TextCell(string(row.Status)),
+			helpers.TextCell(formatOptionalString(row.ApproverName)),
+			helpers.TimeCell(row.ProcessedAt),
+			helpers.TextCell(flagged),
+		}); err != nil {
+			log.Printf("Failed to write expense export: %v", err)
+			break
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Printf("Failed to finish expense export: %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type CreateInviteRequest struct {
+	Email     string             `json:"email" binding:"required" example:"dave@user.com"`
+	Role      constants.UserRole `json:"role" example:"user"` // defaults to user
+	ManagerID *int64             `json:"manager_id" example:"1"`
+}
+
+type InviteResponse struct {
+	Invite    models.UserInvite `json:"invite"`
+	Token     string            `json:"token" example:"q9Xc..."` // only returned once
+	SignupURL string            `json:"signup_url" example:"http://localhost:3000/signup?token=q9Xc..."`
+}
+
+type RegisterRequest struct {
+	Token    string `json:"token" binding:"required" example:"q9Xc..."`
+	Name     string `json:"name" binding:"required" example:"Dave User"`
+	Password string `json:"password" binding:"required" example:"s3cure-passw0rd"`
+}
+
+// CreateInvite godoc
+// @Summary Invite a user
+// @Description Create a single-use signup invite, earlier pending invites for the same email are revoked (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateInviteRequest true "Invite payload"
+// @Success 201 {object} InviteResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/invites [post]
+func CreateInvite(c *gin.Context) {
+	var input CreateInviteRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inviter, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	email, err := rules.NormalizeEmail(input.Email)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Role == "" {
+		input.Role = constants.UserRoleUser
+	}
+	if err := rules.ValidateRole(input.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateManager(0, input.ManagerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	taken, err := emailTaken(email, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": rules.ErrEmailAlreadyRegistered.Error()})
+		return
+	}
+
+	token, hash, err := helpers.NewSingleUseToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invite"})
+		return
+	}
+
+	now := time.Now().UTC()
+	invite := models.UserInvite{
+		TokenHash:   hash,
+		Email:       email,
+		Role:        input.Role,
+		ManagerID:   input.ManagerID,
+		InvitedByID: inviter.ID,
+		ExpiresAt:   now.Add(helpers.InviteTTL()),
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserInvite{}).
+			Where("email = ? AND used_at IS NULL AND revoked_at IS NULL", email).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&invite).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save invite"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionInviteCreate,
+		EntityType: constants.AuditEntityInvite,
+		EntityID:   invite.ID,
+		After:      invite,
+	})
+
+	c.JSON(http.StatusCreated, InviteResponse{
+		Invite:    invite,
+		Token:     token,
+		SignupURL: strings.TrimSuffix(os.Getenv("NUXT_URL"), "/") + "/signup?token=" + token,
+	})
+}
+
+// GetInvites godoc
+// @Summary List invites
+// @Description List signup invites (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param pending query bool false "Only invites that can still be used"
+// @Success 200 {object} object{data=[]models.UserInvite}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/invites [get]
+func GetInvites(c *gin.Context) {
+	query := db.DB.Model(&models.UserInvite{})
+
+	if c.Query("pending") == "true" {
+		query = query.Where("used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now().UTC())
+	}
+
+	var invites []models.UserInvite
+	if err := query.Order("created_at DESC").Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invites"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invites})
+}
+
+// RevokeInvite godoc
+// @Summary Revoke an invite
+// @Description Revoke an invite that has not been used yet (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Invite ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/invites/{id} [delete]
+func RevokeInvite(c *gin.Context) {
+	var invite models.UserInvite
+	if err := db.DB.First(&invite, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return
+	}
+
+	if invite.UsedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrInviteUsed.Error()})
+		return
+	}
+
+	if invite.RevokedAt == nil {
+		now := time.Now().UTC()
+		invite.RevokedAt = &now
+		if err := db.DB.Save(&invite).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite"})
+			return
+		}
+
+		helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+			Action:     constants.AuditActionInviteRevoke,
+			EntityType: constants.AuditEntityInvite,
+			EntityID:   invite.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Invite has been revoked",
+	})
+}
+
+// Register godoc
+// @Summary Sign up with an invite
+// @Description Create the invited account with the chosen password and start a session, each invite works once
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RegisterRequest true "Signup payload"
+// @Success 201 {object} LoginResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/register [post]
+func Register(c *gin.Context) {
+	var input RegisterRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var invite models.UserInvite
+	if err := db.DB.First(&invite, "token_hash = ?", helpers.HashToken(input.Token)).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrInvalidInvite.Error()})
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := rules.ValidateInvite(&invite, now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := checkNewPassword(input.Password, invite.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	// the unique index on email still guards against a concurrent direct creation
+	taken, err := emailTaken(invite.Email, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": rules.ErrEmailAlreadyRegistered.Error()})
+		return
+	}
+
+	user := models.User{
+		Email:             invite.Email,
+		Name:              strings.TrimSpace(input.Name),
+		Role:              invite.Role,
+		ManagerID:         invite.ManagerID,
+		PasswordHash:      string(hash),
+		PasswordChangedAt: &now,
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// claim the invite first so two concurrent signups cannot both use it
+		result := tx.Model(&models.UserInvite{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", invite.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return rules.ErrInviteUsed
+		}
+
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.UserInvite{}).Where("id = ?", invite.ID).Update("user_id", user.ID).Error
+	})
+	switch {
+	case errors.Is(err, rules.ErrInviteUsed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserCreate,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		ActorID:    &user.ID,
+		After:      user,
+		Metadata:   map[string]interface{}{"invite_id": invite.ID},
+	})
+
+	accessToken, refreshToken, err := startSession(c, &user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	helpers.SetAuthCookies(c, &user, accessToken, refreshToken)
+
+	c.JSON(http.StatusCreated, LoginResponse{
+		Token: accessToken,
+		ID:    uint(user.ID),
+		Role:  user.Role,
+		Name:  user.Name,
+		Email: user.Email,
+	})
+}
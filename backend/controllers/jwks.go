@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"net/http"
+
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys for validating access tokens, includes the active key and recently retired ones
+// @Tags auth
+// @Produce json
+// @Success 200 {object} services.JWKS
+// @Failure 503 {object} httputil.HTTPError
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	keys := services.Keys()
+	if keys == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Signing keys not initialised"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keys.JWKS())
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JournalEntriesListResponse struct {
+	Data []models.JournalEntry `json:"data"`
+	Meta PaginationMeta        `json:"meta"`
+}
+
+// GetTrialBalance godoc
+// @Summary Ledger trial balance
+// @Description Debits, credits and balance of every ledger account over the entries posted in the period, all time by default. Balances are on the account's normal side, total debits always equal total credits (manager only)
+// @Tags Ledger
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param from query string false "Posted at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Posted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success 200 {object} helpers.TrialBalance
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/ledger/trial-balance [get]
+func GetTrialBalance(c *gin.Context) {
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	balance, err := helpers.GetTrialBalance(db.DB, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute trial balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetLedgerBalances godoc
+// @Summary Ledger balances per employee
+// @Description What was expensed for, paid to and is still owed to each employee, from the entries posted in the period, largest amount owed first (manager only)
+// @Tags Ledger
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param user_id query int false "Only this employee"
+// @Param from query string false "Posted at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Posted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success 200 {object} object{data=[]helpers.EmployeeBalance}
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/ledger/balances [get]
+func GetLedgerBalances(c *gin.Context) {
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var userID *int64
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be a number"})
+			return
+		}
+		userID = &id
+	}
+
+	balances, err := helpers.GetEmployeeBalances(db.DB, userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute ledger balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": balances})
+}
+
+// GetJournalEntries godoc
+// @Summary List journal entries
+// @Description Ledger entries with their lines, newest first (manager only)
+// @Tags Ledger
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param expense_id query int false "Entries of this expense"
+// @Param user_id query int false "Entries with a line for this employee"
+// @Param kind query string false "approval or settlement"
+// @Param from query string false "Posted at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Posted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} JournalEntriesListResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/ledger/entries [get]
+func GetJournalEntries(c *gin.Context) {
+	var entries []models.JournalEntry
+	var total int64
+
+	page, limit, offset := helpers.GetPagination(c)
+
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := db.DB.Model(&models.JournalEntry{})
+	if expenseID := c.Query("expense_id"); expenseID != "" {
+		query = query.Where("expense_id = ?", expenseID)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("id IN (?)", db.DB.Model(&models.JournalLine{}).Select("journal_entry_id").Where("user_id = ?", userID))
+	}
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", constants.JournalEntryKind(kind))
+	}
+	if from != nil {
+		query = query.Where("posted_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("posted_at < ?", *to)
+	}
+
+	// count first
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count journal entries"})
+		return
+	}
+
+	// fetch paginated data
+	if err := query.
+		Preload("Lines").
+		Order("posted_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch journal entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, JournalEntriesListResponse{
+		Data: entries,
+		Meta: PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
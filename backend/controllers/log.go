@@ -1,39 +1,97 @@
 package controllers
 
 import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/constants"
 	"backend/db"
 	"backend/helpers"
 	"backend/models"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// ExpenseSummary is the expense an audit log row is about
+type ExpenseSummary struct {
+	UUID          uuid.UUID               `json:"uuid" example:"4f9d3c2e-8a1b-4c6d-9e0f-1a2b3c4d5e6f"`
+	Description   string                  `json:"description" example:"Client dinner"`
+	AmountIDR     int64                   `json:"amount_idr" gorm:"column:amount_idr" example:"1500000"`
+	Status        constants.ExpenseStatus `json:"status" example:"approved"`
+	SubmitterName string                  `json:"submitter_name" example:"Bob User"`
+}
+
+type ExpenseAuditLogRow struct {
+	models.ExpenseAuditLog
+	ActorName      *string        `json:"actor_name" example:"Alice Manager"` // nil for background workers
+	OnBehalfOfName *string        `json:"on_behalf_of_name" example:"Carol Manager"`
+	Expense        ExpenseSummary `json:"expense" gorm:"embedded;embeddedPrefix:expense_"`
+}
+
+// sortable columns of the expense audit log listing and export
+var expenseAuditLogSorts = map[string]string{
+	"created_at":  "expense_audit_logs.created_at",
+	"expense_id":  "expense_audit_logs.expense_id",
+	"actor_name":  "COALESCE(actors.name, '')", // worker entries have no actor, cursors need a value
+	"from_status": "expense_audit_logs.from_status",
+	"to_status":   "expense_audit_logs.to_status",
+	"amount":      "expenses.amount_idr",
+}
+
+const expenseAuditLogColumns = "expense_audit_logs.*, " +
+	"actors.name AS actor_name, " +
+	"principals.name AS on_behalf_of_name, " +
+	"expenses.uuid AS expense_uuid, " +
+	"expenses.description AS expense_description, " +
+	"expenses.amount_idr AS expense_amount_idr, " +
+	"expenses.status AS expense_status, " +
+	"submitters.name AS expense_submitter_name"
+
 // GetAuditLog godoc
 // @Summary Get audit logs
-// @Description Get paginated list of audit logs for expenses status changes (manager only)
+// @Description Get paginated list of audit logs for expenses status changes with the actor's name and an expense summary (manager only)
 // @Tags Manager
 // @Security CookieAuth
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number"
+// @Param page query int false "Page number, ignored when cursor is given"
 // @Param limit query int false "Page size"
+// @Param cursor query string false "Keyset paging, empty for the first page then meta.next_cursor"
 // @Param expense_id query int false "Filter by expense ID"
-// @Success 200 {object} object{data=[]models.ExpenseAuditLog,meta=PaginationMeta}
+// @Param actor_id query int false "Filter by actor user ID"
+// @Param from_status query string false "Filter by status before the change"
+// @Param to_status query string false "Filter by status after the change"
+// @Param reason query string false "Search the reason text"
+// @Param from query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Created before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Param sort query string false "Sort field" Enums(created_at, expense_id, actor_name, from_status, to_status, amount)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Success 200 {object} object{data=[]ExpenseAuditLogRow,meta=PaginationMeta}
+// @Failure 400 {object} httputil.HTTPError
 // @Failure 401 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /manager/expense-logs [get]
 func GetExpenseAuditLog(c *gin.Context) {
-	var auditLogs []models.ExpenseAuditLog
+	var auditLogs []ExpenseAuditLogRow
 	var total int64
 
 	page, limit, offset := helpers.GetPagination(c)
-	expenseID := c.Query("expense_id")
-
-	query := db.DB.Model(&models.ExpenseAuditLog{})
 
-	if expenseID != "" {
-		query = query.Where("expense_id = ?", expenseID)
+	query, sort, err := expenseAuditLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cursor, keyset, err := helpers.GetCursor(c, sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	// count first
@@ -42,22 +100,200 @@ func GetExpenseAuditLog(c *gin.Context) {
 		return
 	}
 
-	// fetch paginated data
+	if keyset {
+		page = 0
+		query = helpers.ApplyCursor(query, sort, "expense_audit_logs.id", cursor)
+	} else {
+		query = query.Offset(offset)
+	}
+
+	// fetch paginated data, one extra row tells whether a next page exists
 	if err := query.
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&auditLogs).Error; err != nil {
+		Select(expenseAuditLogColumns).
+		Order(sort.OrderBy("expense_audit_logs.id")).
+		Limit(limit + 1).
+		Scan(&auditLogs).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit logs"})
 		return
 	}
 
+	auditLogs, next := helpers.NextCursor(auditLogs, limit, sort, expenseAuditLogPosition(sort))
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": auditLogs,
 		"meta": PaginationMeta{
-			Page:  page,
-			Limit: limit,
-			Total: total,
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			NextCursor: next,
 		},
 	})
 }
+
+// ExportExpenseAuditLog godoc
+// @Summary Export audit logs as CSV
+// @Description Download every audit log row matching the filters of the listing, in the same order (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Produce text/csv
+// @Param expense_id query int false "Filter by expense ID"
+// @Param actor_id query int false "Filter by actor user ID"
+// @Param from_status query string false "Filter by status before the change"
+// @Param to_status query string false "Filter by status after the change"
+// @Param reason query string false "Search the reason text"
+// @Param from query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Created before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Param sort query string false "Sort field" Enums(created_at, expense_id, actor_name, from_status, to_status, amount)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/expense-logs/export [get]
+func ExportExpenseAuditLog(c *gin.Context) {
+	query, sort, err := expenseAuditLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := query.Select(expenseAuditLogColumns).Order(sort.OrderBy("expense_audit_logs.id")).Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit logs"})
+		return
+	}
+	defer rows.Close()
+
+	helpers.SetCSVHeaders(c, "expense-audit-log-"+time.Now().UTC().Format("20060102")+".csv")
+	c.Status(http.StatusOK)
+
+	// rows are streamed, an error past this point can only cut the file short
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{
+		"id", "created_at", "expense_id", "expense_uuid", "expense_description", "amount_idr", "submitter",
+		"actor_id", "actor_name", "on_behalf_of_name", "from_status", "to_status", "reason", "seq", "hash",
+	})
+	for rows.Next() {
+		var row ExpenseAuditLogRow
+		if err := db.DB.ScanRows(rows, &row); err != nil {
+			log.Printf("Failed to export audit log row: %v", err)
+			break
+		}
+		writer.Write([]string{
+			strconv.FormatInt(row.ID, 10),
+			row.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(row.ExpenseID, 10),
+			row.Expense.UUID.String(),
+			helpers.CSVCell(row.Expense.Description),
+			strconv.FormatInt(row.Expense.AmountIDR, 10),
+			helpers.CSVCell(row.Expense.SubmitterName),
+			formatOptionalID(row.ActorID),
+			helpers.CSVCell(formatOptionalString(row.ActorName)),
+			helpers.CSVCell(formatOptionalString(row.OnBehalfOfName)),
+			string(row.FromStatus),
+			string(row.ToStatus),
+			helpers.CSVCell(row.Reason),
+			formatOptionalID(row.Seq),
+			formatOptionalString(row.Hash),
+		})
+	}
+	writer.Flush()
+}
+
+// expenseAuditLogQuery applies the filters shared by the listing and the export
+func expenseAuditLogQuery(c *gin.Context) (*gorm.DB, helpers.Sort, error) {
+	sort, err := helpers.GetSort(c, expenseAuditLogSorts, "created_at", "desc")
+	if err != nil {
+		return nil, sort, err
+	}
+
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		return nil, sort, err
+	}
+
+	query := db.DB.Table("expense_audit_logs").
+		Joins("LEFT JOIN users AS actors ON actors.id = expense_audit_logs.actor_id").
+		Joins("LEFT JOIN users AS principals ON principals.id = expense_audit_logs.on_behalf_of_id").
+		Joins("LEFT JOIN expenses ON expenses.id = expense_audit_logs.expense_id").
+		Joins("LEFT JOIN users AS submitters ON submitters.id = expenses.user_id")
+
+	if expenseID := c.Query("expense_id"); expenseID != "" {
+		query = query.Where("expense_audit_logs.expense_id = ?", expenseID)
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query = query.Where("expense_audit_logs.actor_id = ?", actorID)
+	}
+	if fromStatus := c.Query("from_status"); fromStatus != "" {
+		query = query.Where("expense_audit_logs.from_status = ?", fromStatus)
+	}
+	if toStatus := c.Query("to_status"); toStatus != "" {
+		query = query.Where("expense_audit_logs.to_status = ?", toStatus)
+	}
+	if reason := strings.TrimSpace(c.Query("reason")); reason != "" {
+		query = query.Where("LOWER(expense_audit_logs.reason) LIKE ?", "%"+strings.ToLower(reason)+"%")
+	}
+	if from != nil {
+		query = query.Where("expense_audit_logs.created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("expense_audit_logs.created_at < ?", *to)
+	}
+
+	return query, sort, nil
+}
+
+// expenseAuditLogPosition reads the cursor position of a row in sort
+func expenseAuditLogPosition(sort helpers.Sort) func(ExpenseAuditLogRow) (interface{}, int64) {
+	return func(row ExpenseAuditLogRow) (interface{}, int64) {
+		switch sort.Key {
+		case "expense_id":
+			return row.ExpenseID, row.ID
+		case "actor_name":
+			return formatOptionalString(row.ActorName), row.ID
+		case "from_status":
+			return row.FromStatus, row.ID
+		case "to_status":
+			return row.ToStatus, row.ID
+		case "amount":
+			return row.Expense.AmountIDR, row.ID
+		default:
+			return row.CreatedAt, row.ID
+		}
+	}
+}
+
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func formatOptionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// VerifyExpenseAuditLog godoc
+// @Summary Verify the audit log hash chain
+// @Description Walk the hash chain of expense audit logs and report the first entry that was edited, removed or inserted, an anchor it no longer matches, or an anchor that is missing or not signed by the anchor key (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} helpers.AuditChainReport
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/expense-logs/verify [get]
+func VerifyExpenseAuditLog(c *gin.Context) {
+	report, err := helpers.VerifyExpenseAuditChain(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
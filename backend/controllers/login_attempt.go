@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoginAttempts godoc
+// @Summary Get login audit trail
+// @Description Get paginated login successes and failures, newest first (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param user_id query int false "Filter by user ID"
+// @Param email query string false "Filter by email"
+// @Param ip_address query string false "Filter by client IP address"
+// @Param result query string false "Filter by result" Enums(success, invalid_credentials, invalid_second_factor, throttled, deactivated, unlocked)
+// @Success 200 {object} object{data=[]models.LoginAttempt,meta=PaginationMeta}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/login-attempts [get]
+func GetLoginAttempts(c *gin.Context) {
+	var attempts []models.LoginAttempt
+	var total int64
+
+	page, limit, offset := helpers.GetPagination(c)
+
+	query := db.DB.Model(&models.LoginAttempt{})
+
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if email := c.Query("email"); email != "" {
+		query = query.Where("email = ?", strings.ToLower(strings.TrimSpace(email)))
+	}
+	if ip := c.Query("ip_address"); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	if result := c.Query("result"); result != "" {
+		query = query.Where("result = ?", result)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count login attempts"})
+		return
+	}
+
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&attempts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": attempts,
+		"meta": PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcLoginCookie = "oidc_login"
+	oidcLoginMaxAge = 10 * 60
+)
+
+// oidcLoginState survives the round trip to the identity provider in an httpOnly cookie
+type oidcLoginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+var (
+	oidcServiceOnce sync.Once
+	oidcService     services.OIDCService
+)
+
+func oidc() services.OIDCService {
+	oidcServiceOnce.Do(func() {
+		if oidcService == nil {
+			oidcService = services.NewOIDCService()
+		}
+	})
+	return oidcService
+}
+
+// ONLY FOR TESTING PURPOSES
+func SetOIDCService(s services.OIDCService) {
+	oidcServiceOnce.Do(func() {})
+	oidcService = s
+}
+
+// OIDCLogin godoc
+// @Summary Start single sign-on
+// @Description Redirect to the identity provider using the authorization code flow with PKCE
+// @Tags auth
+// @Success 302
+// @Failure 500 {object} httputil.HTTPError
+// @Failure 503 {object} httputil.HTTPError
+// @Router /auth/oidc/login [get]
+func OIDCLogin(c *gin.Context) {
+	state, err := services.RandomURLToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	nonce, err := services.RandomURLToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	verifier, challenge, err := services.NewPKCEVerifier()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	authURL, err := oidc().AuthCodeURL(c.Request.Context(), state, nonce, challenge)
+	if errors.Is(err, services.ErrOIDCNotConfigured) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reach identity provider"})
+		return
+	}
+
+	payload, _ := json.Marshal(oidcLoginState{State: state, Nonce: nonce, Verifier: verifier})
+	c.SetCookie(oidcLoginCookie, base64.RawURLEncoding.EncodeToString(payload), oidcLoginMaxAge, "/", "", false, true)
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary Finish single sign-on
+// @Description Exchange the authorization code, provision the user on first login and start a session
+// @Tags auth
+// @Param code query string true "Authorization code"
+// @Param state query string true "State issued by /auth/oidc/login"
+// @Success 302
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 403 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/oidc/callback [get]
+func OIDCCallback(c *gin.Context) {
+	if idpError := c.Query("error"); idpError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider error: " + idpError})
+		return
+	}
+
+	raw, err := c.Cookie(oidcLoginCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing login state, start again"})
+		return
+	}
+	c.SetCookie(oidcLoginCookie, "", -1, "/", "", false, true)
+
+	var login oidcLoginState
+	payload, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || json.Unmarshal(payload, &login) != nil || login.State == "" || login.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login state"})
+		return
+	}
+
+	identity, err := oidc().Exchange(c.Request.Context(), c.Query("code"), login.Verifier, login.Nonce)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed"})
+		return
+	}
+
+	user, err := provisionOIDCUser(identity)
+	if errors.Is(err, rules.ErrOIDCEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to provision user"})
+		return
+	}
+	if err := rules.CanAuthenticate(user); err != nil {
+		helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultDeactivated)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, err := startSession(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultSuccess)
+	helpers.SetAuthCookies(c, user, accessToken, refreshToken)
+
+	redirect := os.Getenv("OIDC_POST_LOGIN_REDIRECT")
+	if redirect == "" {
+		redirect = "/dashboard"
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// provisionOIDCUser finds the user by subject, then by email, and creates it just in time otherwise.
+// The role follows the ID token claims on every login.
+func provisionOIDCUser(identity *services.OIDCIdentity) (*models.User, error) {
+	roleClaim := os.Getenv("OIDC_ROLE_CLAIM")
+	if roleClaim == "" {
+		roleClaim = "groups"
+	}
+	managerValues := strings.Split(os.Getenv("OIDC_MANAGER_VALUES"), ",")
+	if os.Getenv("OIDC_MANAGER_VALUES") == "" {
+		managerValues = []string{"manager"}
+	}
+	role := rules.MapRoleFromClaims(identity.Claims, roleClaim, managerValues)
+	// a token without the claim says nothing about the role, it must not demote anybody
+	_, hasRoleClaim := identity.Claims[roleClaim]
+
+	var user models.User
+	err := db.DB.First(&user, "oidc_subject = ?", identity.Subject).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// never link or create accounts from an address the IdP did not verify
+		if !rules.IsOIDCEmailVerified(identity.Claims) {
+			return nil, rules.ErrOIDCEmailNotVerified
+		}
+		if identity.Email != "" {
+			err = db.DB.First(&user, "email = ?", strings.ToLower(identity.Email)).Error
+		}
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if identity.Email == "" {
+			return nil, errors.New("ID token has no email claim")
+		}
+		name := identity.Name
+		if name == "" {
+			name = identity.Email
+		}
+		managerID, err := oidcManagerID(identity.Claims)
+		if err != nil {
+			return nil, err
+		}
+		user = models.User{
+			Email:       strings.ToLower(identity.Email),
+			Name:        name,
+			Role:        role,
+			ManagerID:   managerID,
+			OIDCSubject: &identity.Subject,
+		}
+		if err := db.DB.Create(&user).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+
+	case err != nil:
+		return nil, err
+	}
+
+	user.OIDCSubject = &identity.Subject
+	if hasRoleClaim {
+		user.Role = role
+	}
+	if identity.Name != "" {
+		user.Name = identity.Name
+	}
+	if err := db.DB.Save(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// oidcManagerID finds the active manager whose email is in the OIDC_MANAGER_CLAIM claim
+// (default manager_email), nil when the IdP sends none or an unknown address
+func oidcManagerID(claims map[string]interface{}) (*int64, error) {
+	claim := os.Getenv("OIDC_MANAGER_CLAIM")
+	if claim == "" {
+		claim = "manager_email"
+	}
+	email, _ := claims[claim].(string)
+	if email == "" {
+		return nil, nil
+	}
+
+	var manager models.User
+	err := db.DB.Select("id").
+		First(&manager, "LOWER(email) = ? AND role = ? AND deactivated_at IS NULL", strings.ToLower(strings.TrimSpace(email)), constants.UserRoleManager).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &manager.ID, nil
+}
@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required" example:"bob@user.com"`
+}
+
+type ResetPasswordWithTokenRequest struct {
+	Token    string `json:"token" binding:"required" example:"q9Xc..."`
+	Password string `json:"password" binding:"required" example:"n3w-Passw0rd"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"old-Passw0rd"`
+	NewPassword     string `json:"new_password" binding:"required" example:"n3w-Passw0rd"`
+}
+
+var (
+	mailerOnce sync.Once
+	mailer     services.Mailer
+
+	breachedOnce      sync.Once
+	breachedPasswords services.BreachedPasswords
+)
+
+func mail() services.Mailer {
+	mailerOnce.Do(func() {
+		if mailer == nil {
+			mailer = services.NewMailer()
+		}
+	})
+	return mailer
+}
+
+// ONLY FOR TESTING PURPOSES
+func SetMailer(m services.Mailer) {
+	mailerOnce.Do(func() {})
+	mailer = m
+}
+
+func breached() services.BreachedPasswords {
+	breachedOnce.Do(func() {
+		list, err := services.NewBreachedPasswords()
+		if err != nil {
+			log.Printf("Failed to load breached password list, using the built-in one: %v", err)
+			list = services.NewDefaultBreachedPasswords()
+		}
+		breachedPasswords = list
+	})
+	return breachedPasswords
+}
+
+// ForgotPassword godoc
+// @Summary Forgot password
+// @Description Email a single-use reset link. The response is the same, and as fast, whether or not the email exists
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Account email"
+// @Success 202 {object} MessageResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the lookup and the token are handled off the request path, unknown and real
+	// accounts answer in the same time
+	go issuePasswordReset(strings.ToLower(strings.TrimSpace(input.Email)), c.ClientIP())
+
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "If the account exists, a reset link has been sent"})
+}
+
+// issuePasswordReset stores a new reset token for an active account and emails the link
+func issuePasswordReset(email, requestedIP string) {
+	var user models.User
+	if err := db.DB.First(&user, "email = ?", email).Error; err != nil || !user.IsActive() || user.ServiceAccount {
+		return
+	}
+
+	token, hash, err := helpers.NewSingleUseToken()
+	if err != nil {
+		log.Printf("Failed to generate password reset token: %v", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	reset := models.PasswordResetToken{
+		UserID:      user.ID,
+		TokenHash:   hash,
+		RequestedIP: requestedIP,
+		ExpiresAt:   now.Add(helpers.PasswordResetTTL()),
+	}
+
+	// only the latest link works
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&reset).Error
+	})
+	if err != nil {
+		log.Printf("Failed to save password reset token for user %d: %v", user.ID, err)
+		return
+	}
+
+	link := strings.TrimSuffix(os.Getenv("NUXT_URL"), "/") + "/reset-password?token=" + token
+	sendMail(user.Email, "Reset your password", fmt.Sprintf(
+		"Hi %s,\n\nUse the link below to choose a new password. It expires in %s and works once.\n\n%s\n\nIf you did not ask for this, you can ignore this email.",
+		user.Name, helpers.PasswordResetTTL(), link,
+	))
+}
+
+// ResetPassword godoc
+// @Summary Reset password with token
+// @Description Set a new password with the token from the reset email and sign out every session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordWithTokenRequest true "Reset token and new password"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/reset-password [post]
+func ResetPassword(c *gin.Context) {
+	var input ResetPasswordWithTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var reset models.PasswordResetToken
+	if err := db.DB.First(&reset, "token_hash = ?", helpers.HashToken(input.Token)).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrInvalidResetToken.Error()})
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := rules.ValidatePasswordResetToken(&reset, now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, reset.UserID).Error; err != nil || !user.IsActive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrInvalidResetToken.Error()})
+		return
+	}
+
+	if err := checkNewPassword(input.Password, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// claim the token first so it cannot be replayed concurrently
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return rules.ErrResetTokenUsed
+		}
+
+		if err := setPassword(tx, &user, string(hash), now); err != nil {
+			return err
+		}
+		return helpers.RevokeUserSessions(tx, user.ID, now)
+	})
+	if errors.Is(err, rules.ErrResetTokenUsed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultUnlocked)
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionPasswordReset,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		ActorID:    &user.ID,
+	})
+	sendPasswordChangedMail(&user)
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Password has been reset, please sign in again",
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the authenticated user's password, other sessions are signed out
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/password [put]
+func ChangePassword(c *gin.Context) {
+	var input ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if user.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.CurrentPassword)) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrInvalidCurrentPassword.Error()})
+		return
+	}
+	if input.NewPassword == input.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrPasswordUnchanged.Error()})
+		return
+	}
+	if err := checkNewPassword(input.NewPassword, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	now := time.Now().UTC()
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setPassword(tx, &user, string(hash), now); err != nil {
+			return err
+		}
+		// keep the session that made the change
+		return tx.Model(&models.UserSession{}).
+			Where("user_id = ? AND uuid <> ? AND revoked_at IS NULL", user.ID, c.GetString("session_id")).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionPasswordChange,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+	})
+	sendPasswordChangedMail(&user)
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Password has been changed",
+	})
+}
+
+// checkNewPassword applies the strength policy and the breached password list
+func checkNewPassword(password, email string) error {
+	if err := rules.LoadPasswordPolicy().Validate(password, email); err != nil {
+		return err
+	}
+	if breached().Contains(password) {
+		return rules.ErrPasswordBreached
+	}
+	return nil
+}
+
+func setPassword(tx *gorm.DB, user *models.User, hash string, at time.Time) error {
+	return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password_hash":       hash,
+		"password_changed_at": at,
+	}).Error
+}
+
+func sendPasswordChangedMail(user *models.User) {
+	sendMail(user.Email, "Your password was changed", fmt.Sprintf(
+		"Hi %s,\n\nThe password of your expense account was just changed. If this was not you, contact your manager right away.",
+		user.Name,
+	))
+}
+
+// sendMail delivers in the background so response time does not reveal whether an account exists
+func sendMail(to, subject, body string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := mail().Send(ctx, services.MailMessage{To: to, Subject: subject, Body: body}); err != nil {
+			log.Printf("Failed to send %q to %s: %v", subject, to, err)
+		}
+	}()
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/db"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	scimContentType = "application/scim+json"
+
+	scimUserSchema       = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema      = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema       = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimPatchSchema      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimErrorSchema      = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSPConfigSchema   = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimDefaultPageCount = 100
+	scimMaxPageCount     = 200
+)
+
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType" example:"User"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location" example:"http://localhost:8080/api/scim/v2/Users/3"`
+}
+
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty" example:"Bob User"`
+	GivenName  string `json:"givenName,omitempty" example:"Bob"`
+	FamilyName string `json:"familyName,omitempty" example:"User"`
+}
+
+type SCIMEmail struct {
+	Value   string `json:"value" example:"bob@user.com"`
+	Type    string `json:"type,omitempty" example:"work"`
+	Primary bool   `json:"primary,omitempty" example:"true"`
+}
+
+type SCIMMemberRef struct {
+	Value   string `json:"value" example:"3"`
+	Display string `json:"display,omitempty" example:"Bob User"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type SCIMUser struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty" example:"3"`
+	ExternalID  *string         `json:"externalId,omitempty" example:"00u1abcd"`
+	UserName    string          `json:"userName" example:"bob@user.com"`
+	Name        *SCIMName       `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty" example:"Bob User"`
+	Emails      []SCIMEmail     `json:"emails,omitempty"`
+	Active      *bool           `json:"active,omitempty" example:"true"`
+	Groups      []SCIMMemberRef `json:"groups,omitempty"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty" example:"1"`
+	ExternalID  *string         `json:"externalId,omitempty" example:"00g1abcd"`
+	DisplayName string          `json:"displayName" example:"expense-managers"`
+	Members     []SCIMMemberRef `json:"members,omitempty"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults" example:"1"`
+	StartIndex   int         `json:"startIndex" example:"1"`
+	ItemsPerPage int         `json:"itemsPerPage" example:"1"`
+	Resources    interface{} `json:"Resources"`
+}
+
+type SCIMPatchOperation struct {
+	Op    string          `json:"op" example:"replace"`
+	Path  string          `json:"path,omitempty" example:"active"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status" example:"400"`
+	ScimType string   `json:"scimType,omitempty" example:"invalidFilter"`
+	Detail   string   `json:"detail" example:"unsupported attribute title"`
+}
+
+// scimProblem is returned by the patch helpers and rendered as a SCIM error
+type scimProblem struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+// SCIMServiceProviderConfig godoc
+// @Summary SCIM service provider configuration
+// @Description Capabilities advertised to the provisioning client
+// @Tags SCIM
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object
+// @Failure 401 {object} httputil.HTTPError
+// @Router /scim/v2/ServiceProviderConfig [get]
+func SCIMServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, gin.H{
+		"schemas":        []string{scimSPConfigSchema},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": scimMaxPageCount},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Static token configured with SCIM_BEARER_TOKEN",
+			"primary":     true,
+		}},
+	})
+}
+
+func scimJSON(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, body)
+}
+
+func scimError(c *gin.Context, status int, scimType, detail string) {
+	scimJSON(c, status, SCIMError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func (p *scimProblem) render(c *gin.Context) {
+	scimError(c, p.Status, p.ScimType, p.Detail)
+}
+
+func scimInvalidValue(detail string) *scimProblem {
+	return &scimProblem{http.StatusBadRequest, "invalidValue", detail}
+}
+
+// scimPagination reads the 1-based startIndex and count parameters
+func scimPagination(c *gin.Context) (startIndex, count int) {
+	startIndex, count = 1, scimDefaultPageCount
+
+	if v, err := strconv.Atoi(c.Query("startIndex")); err == nil && v > 1 {
+		startIndex = v
+	}
+	if v, err := strconv.Atoi(c.Query("count")); err == nil && v >= 0 {
+		count = v
+	}
+	if count > scimMaxPageCount {
+		count = scimMaxPageCount
+	}
+	return startIndex, count
+}
+
+// scimLocation builds the absolute URL of a resource from the current request
+func scimLocation(c *gin.Context, resource, id string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	base := c.Request.URL.Path
+	if idx := strings.Index(base, "/scim/v2"); idx >= 0 {
+		base = base[:idx+len("/scim/v2")]
+	}
+	return scheme + "://" + c.Request.Host + base + "/" + resource + "/" + id
+}
+
+func scimID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	return id, err == nil
+}
+
+// syncGroupRoles recomputes the role of the given users from their group memberships.
+// Only enabled when SCIM_MANAGER_GROUPS lists the groups that grant the manager role.
+func syncGroupRoles(userIDs []int64) error {
+	managerGroups := strings.Split(os.Getenv("SCIM_MANAGER_GROUPS"), ",")
+	if os.Getenv("SCIM_MANAGER_GROUPS") == "" || len(userIDs) == 0 {
+		return nil
+	}
+
+	for _, userID := range userIDs {
+		var groupNames []string
+		if err := db.DB.Table("user_groups").
+			Joins("JOIN user_group_members ON user_group_members.group_id = user_groups.id").
+			Where("user_group_members.user_id = ?", userID).
+			Pluck("user_groups.display_name", &groupNames).Error; err != nil {
+			return err
+		}
+
+		role := rules.MapRoleFromClaims(map[string]interface{}{"groups": groupNames}, "groups", managerGroups)
+		if err := db.DB.Table("users").Where("id = ? AND role <> ?", userID, role).Update("role", role).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,517 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var scimGroupColumns = map[string]helpers.SCIMColumn{
+	"id":          {Column: "id"},
+	"displayname": {Column: "display_name"},
+	"externalid":  {Column: "external_id"},
+}
+
+// scimMemberPath matches paths such as members[value eq "3"]
+var scimMemberPath = regexp.MustCompile(`(?i)^members\[value eq "([^"]+)"\]$`)
+
+// SCIMListGroups godoc
+// @Summary List groups (SCIM)
+// @Tags SCIM
+// @Security BearerAuth
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. displayName eq \"expense-managers\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size"
+// @Success 200 {object} SCIMListResponse
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Groups [get]
+func SCIMListGroups(c *gin.Context) {
+	clause, args, err := helpers.ParseSCIMFilter(c.Query("filter"), scimGroupColumns)
+	if err != nil {
+		scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	startIndex, count := scimPagination(c)
+
+	query := db.DB.Model(&models.Group{})
+	if clause != "" {
+		query = query.Where(clause, args...)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to count groups")
+		return
+	}
+
+	var groups []models.Group
+	if err := query.Preload("Members").Order("id").Offset(startIndex - 1).Limit(count).Find(&groups).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to fetch groups")
+		return
+	}
+
+	resources := make([]SCIMGroup, 0, len(groups))
+	for i := range groups {
+		resources = append(resources, toSCIMGroup(c, &groups[i]))
+	}
+
+	scimJSON(c, http.StatusOK, SCIMListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// SCIMGetGroup godoc
+// @Summary Get group (SCIM)
+// @Tags SCIM
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Group ID"
+// @Success 200 {object} SCIMGroup
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Router /scim/v2/Groups/{id} [get]
+func SCIMGetGroup(c *gin.Context) {
+	group, ok := findSCIMGroup(c)
+	if !ok {
+		return
+	}
+	scimJSON(c, http.StatusOK, toSCIMGroup(c, group))
+}
+
+// SCIMCreateGroup godoc
+// @Summary Provision group (SCIM)
+// @Description Create a group, members of the groups listed in SCIM_MANAGER_GROUPS get the manager role
+// @Tags SCIM
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SCIMGroup true "SCIM group"
+// @Success 201 {object} SCIMGroup
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 409 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Groups [post]
+func SCIMCreateGroup(c *gin.Context) {
+	var input SCIMGroup
+	if err := c.ShouldBindJSON(&input); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	if strings.TrimSpace(input.DisplayName) == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	var existing int64
+	db.DB.Model(&models.Group{}).Where("display_name = ?", input.DisplayName).Count(&existing)
+	if existing > 0 {
+		scimError(c, http.StatusConflict, "uniqueness", "displayName is already taken")
+		return
+	}
+
+	members, problem := scimMemberUsers(input.Members)
+	if problem != nil {
+		problem.render(c)
+		return
+	}
+
+	group := models.Group{DisplayName: input.DisplayName, ExternalID: input.ExternalID, Members: members}
+	if err := db.DB.Create(&group).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to create group")
+		return
+	}
+
+	syncSCIMGroupRoles(userIDsOf(members))
+	scimJSON(c, http.StatusCreated, toSCIMGroup(c, &group))
+}
+
+// SCIMReplaceGroup godoc
+// @Summary Replace group (SCIM)
+// @Tags SCIM
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param request body SCIMGroup true "SCIM group"
+// @Success 200 {object} SCIMGroup
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Groups/{id} [put]
+func SCIMReplaceGroup(c *gin.Context) {
+	group, ok := findSCIMGroup(c)
+	if !ok {
+		return
+	}
+
+	var input SCIMGroup
+	if err := c.ShouldBindJSON(&input); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+	if strings.TrimSpace(input.DisplayName) == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	members, problem := scimMemberUsers(input.Members)
+	if problem != nil {
+		problem.render(c)
+		return
+	}
+
+	affected := userIDsOf(group.Members)
+	group.DisplayName = input.DisplayName
+	group.ExternalID = input.ExternalID
+
+	if problem := saveSCIMGroup(group, members); problem != nil {
+		problem.render(c)
+		return
+	}
+
+	syncSCIMGroupRoles(append(affected, userIDsOf(members)...))
+	scimJSON(c, http.StatusOK, toSCIMGroup(c, group))
+}
+
+// SCIMPatchGroup godoc
+// @Summary Update group (SCIM)
+// @Description Apply PatchOp operations on displayName and members, e.g. {"op":"remove","path":"members[value eq \"3\"]"}
+// @Tags SCIM
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param request body SCIMPatchRequest true "SCIM PatchOp"
+// @Success 200 {object} SCIMGroup
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Groups/{id} [patch]
+func SCIMPatchGroup(c *gin.Context) {
+	group, ok := findSCIMGroup(c)
+	if !ok {
+		return
+	}
+
+	var input SCIMPatchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	affected := userIDsOf(group.Members)
+	members := append([]models.User(nil), group.Members...)
+
+	for _, operation := range input.Operations {
+		var problem *scimProblem
+		members, problem = applySCIMGroupPatch(group, members, operation)
+		if problem != nil {
+			problem.render(c)
+			return
+		}
+	}
+
+	if problem := saveSCIMGroup(group, members); problem != nil {
+		problem.render(c)
+		return
+	}
+
+	syncSCIMGroupRoles(append(affected, userIDsOf(members)...))
+	scimJSON(c, http.StatusOK, toSCIMGroup(c, group))
+}
+
+// SCIMDeleteGroup godoc
+// @Summary Delete group (SCIM)
+// @Description Delete the group, its members keep their accounts
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path int true "Group ID"
+// @Success 204
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Groups/{id} [delete]
+func SCIMDeleteGroup(c *gin.Context) {
+	group, ok := findSCIMGroup(c)
+	if !ok {
+		return
+	}
+
+	affected := userIDsOf(group.Members)
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Association("Members").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to delete group")
+		return
+	}
+
+	syncSCIMGroupRoles(affected)
+	c.Status(http.StatusNoContent)
+}
+
+func findSCIMGroup(c *gin.Context) (*models.Group, bool) {
+	id, ok := scimID(c)
+	if !ok {
+		scimError(c, http.StatusNotFound, "", "Group not found")
+		return nil, false
+	}
+
+	var group models.Group
+	if err := db.DB.Preload("Members").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			scimError(c, http.StatusNotFound, "", "Group not found")
+		} else {
+			scimError(c, http.StatusInternalServerError, "", "Failed to fetch group")
+		}
+		return nil, false
+	}
+	return &group, true
+}
+
+func toSCIMGroup(c *gin.Context, group *models.Group) SCIMGroup {
+	id := strconv.FormatInt(group.ID, 10)
+	resource := SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          id,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     []SCIMMemberRef{},
+		Meta: &SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     scimLocation(c, "Groups", id),
+		},
+	}
+
+	for _, member := range group.Members {
+		memberID := strconv.FormatInt(member.ID, 10)
+		resource.Members = append(resource.Members, SCIMMemberRef{
+			Value:   memberID,
+			Display: member.Name,
+			Ref:     scimLocation(c, "Users", memberID),
+		})
+	}
+	return resource
+}
+
+// applySCIMGroupPatch applies one operation to the group and returns the new member list
+func applySCIMGroupPatch(group *models.Group, members []models.User, operation SCIMPatchOperation) ([]models.User, *scimProblem) {
+	op := strings.ToLower(operation.Op)
+	path := strings.TrimPrefix(operation.Path, scimGroupSchema+":")
+
+	if match := scimMemberPath.FindStringSubmatch(path); match != nil {
+		if op != "remove" {
+			return nil, scimInvalidValue("only remove is supported on a member filter")
+		}
+		return removeMembers(members, map[string]bool{match[1]: true}), nil
+	}
+
+	switch {
+	case path == "" && (op == "add" || op == "replace"):
+		var attributes struct {
+			DisplayName *string          `json:"displayName"`
+			ExternalID  *string          `json:"externalId"`
+			Members     *[]SCIMMemberRef `json:"members"`
+		}
+		if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+			return nil, scimInvalidValue("value must be an object when path is omitted")
+		}
+		if attributes.DisplayName != nil {
+			group.DisplayName = *attributes.DisplayName
+		}
+		if attributes.ExternalID != nil {
+			group.ExternalID = attributes.ExternalID
+		}
+		if attributes.Members != nil {
+			added, problem := scimMemberUsers(*attributes.Members)
+			if problem != nil {
+				return nil, problem
+			}
+			if op == "replace" {
+				return added, nil
+			}
+			return addMembers(members, added), nil
+		}
+		return members, nil
+
+	case strings.EqualFold(path, "displayName") && op != "remove":
+		var displayName string
+		if err := json.Unmarshal(operation.Value, &displayName); err != nil || strings.TrimSpace(displayName) == "" {
+			return nil, scimInvalidValue("displayName must be a non-empty string")
+		}
+		group.DisplayName = displayName
+		return members, nil
+
+	case strings.EqualFold(path, "externalId"):
+		if op == "remove" {
+			group.ExternalID = nil
+			return members, nil
+		}
+		var externalID string
+		if err := json.Unmarshal(operation.Value, &externalID); err != nil {
+			return nil, scimInvalidValue("externalId must be a string")
+		}
+		group.ExternalID = &externalID
+		return members, nil
+
+	case strings.EqualFold(path, "members"):
+		var refs []SCIMMemberRef
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &refs); err != nil {
+				return nil, scimInvalidValue("members must be a list")
+			}
+		}
+
+		switch op {
+		case "remove":
+			if len(refs) == 0 {
+				return []models.User{}, nil
+			}
+			ids := map[string]bool{}
+			for _, ref := range refs {
+				ids[ref.Value] = true
+			}
+			return removeMembers(members, ids), nil
+		case "add", "replace":
+			added, problem := scimMemberUsers(refs)
+			if problem != nil {
+				return nil, problem
+			}
+			if op == "replace" {
+				return added, nil
+			}
+			return addMembers(members, added), nil
+		}
+	}
+
+	return nil, scimInvalidValue("unsupported operation " + operation.Op + " " + operation.Path)
+}
+
+func saveSCIMGroup(group *models.Group, members []models.User) *scimProblem {
+	var taken int64
+	db.DB.Model(&models.Group{}).Where("display_name = ? AND id <> ?", group.DisplayName, group.ID).Count(&taken)
+	if taken > 0 {
+		return &scimProblem{http.StatusConflict, "uniqueness", "displayName is already taken"}
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Updates(map[string]interface{}{
+			"display_name": group.DisplayName,
+			"external_id":  group.ExternalID,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(group).Association("Members").Replace(members)
+	})
+	if err != nil {
+		return &scimProblem{http.StatusInternalServerError, "", "Failed to update group"}
+	}
+
+	group.Members = members
+	return nil
+}
+
+// scimMemberUsers resolves member references to users, unknown ids are rejected
+func scimMemberUsers(refs []SCIMMemberRef) ([]models.User, *scimProblem) {
+	if len(refs) == 0 {
+		return []models.User{}, nil
+	}
+
+	ids := make([]int64, 0, len(refs))
+	for _, ref := range refs {
+		id, err := strconv.ParseInt(ref.Value, 10, 64)
+		if err != nil {
+			return nil, scimInvalidValue("unknown member " + ref.Value)
+		}
+		ids = append(ids, id)
+	}
+
+	var users []models.User
+	if err := db.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, &scimProblem{http.StatusInternalServerError, "", "Failed to fetch members"}
+	}
+	if len(users) != len(uniqueIDs(ids)) {
+		return nil, scimInvalidValue("one or more members do not exist")
+	}
+	return users, nil
+}
+
+func addMembers(members, added []models.User) []models.User {
+	seen := map[int64]bool{}
+	for _, member := range members {
+		seen[member.ID] = true
+	}
+	for _, user := range added {
+		if !seen[user.ID] {
+			members = append(members, user)
+			seen[user.ID] = true
+		}
+	}
+	return members
+}
+
+func removeMembers(members []models.User, ids map[string]bool) []models.User {
+	kept := make([]models.User, 0, len(members))
+	for _, member := range members {
+		if !ids[strconv.FormatInt(member.ID, 10)] {
+			kept = append(kept, member)
+		}
+	}
+	return kept
+}
+
+func userIDsOf(users []models.User) []int64 {
+	ids := make([]int64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func uniqueIDs(ids []int64) map[int64]bool {
+	unique := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
+
+// syncSCIMGroupRoles logs instead of failing the request, the next group change retries
+func syncSCIMGroupRoles(userIDs []int64) {
+	ids := make([]int64, 0, len(userIDs))
+	for id := range uniqueIDs(userIDs) {
+		ids = append(ids, id)
+	}
+
+	if err := syncGroupRoles(ids); err != nil {
+		log.Printf("Failed to sync roles from SCIM groups: %v", err)
+	}
+}
@@ -0,0 +1,599 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var scimUserColumns = map[string]helpers.SCIMColumn{
+	"id":                {Column: "id"},
+	"username":          {Column: "email"},
+	"emails.value":      {Column: "email"},
+	"externalid":        {Column: "external_id"},
+	"displayname":       {Column: "name"},
+	"name.formatted":    {Column: "name"},
+	"active":            {Column: "deactivated_at", NullMeansTrue: true},
+	"meta.lastmodified": {Column: "updated_at"},
+	"meta.created":      {Column: "created_at"},
+}
+
+// scimUserChanges collects the attributes touched by a PUT or PATCH before applying them
+type scimUserChanges struct {
+	UserName    *string
+	DisplayName *string
+	GivenName   *string
+	FamilyName  *string
+	ExternalID  **string
+	Active      *bool
+}
+
+// SCIMListUsers godoc
+// @Summary List users (SCIM)
+// @Description List users for the provisioning client, supports eq/ne/co/sw/ew/pr filters joined by and
+// @Tags SCIM
+// @Security BearerAuth
+// @Produce json
+// @Param filter query string false "SCIM filter, e.g. userName eq \"bob@user.com\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Page size"
+// @Success 200 {object} SCIMListResponse
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Users [get]
+func SCIMListUsers(c *gin.Context) {
+	clause, args, err := helpers.ParseSCIMFilter(c.Query("filter"), scimUserColumns)
+	if err != nil {
+		scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	startIndex, count := scimPagination(c)
+
+	query := db.DB.Model(&models.User{})
+	if clause != "" {
+		query = query.Where(clause, args...)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to count users")
+		return
+	}
+
+	var users []models.User
+	if err := query.Order("id").Offset(startIndex - 1).Limit(count).Find(&users).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to fetch users")
+		return
+	}
+
+	resources := make([]SCIMUser, 0, len(users))
+	for i := range users {
+		resources = append(resources, toSCIMUser(c, &users[i], nil))
+	}
+
+	scimJSON(c, http.StatusOK, SCIMListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// SCIMGetUser godoc
+// @Summary Get user (SCIM)
+// @Tags SCIM
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} SCIMUser
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Router /scim/v2/Users/{id} [get]
+func SCIMGetUser(c *gin.Context) {
+	user, ok := findSCIMUser(c)
+	if !ok {
+		return
+	}
+	scimJSON(c, http.StatusOK, toSCIMUser(c, user, loadUserGroups(user.ID)))
+}
+
+// SCIMCreateUser godoc
+// @Summary Provision user (SCIM)
+// @Description Create a user, provisioned users sign in through single sign-on and get the user role
+// @Tags SCIM
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SCIMUser true "SCIM user"
+// @Success 201 {object} SCIMUser
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 409 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Users [post]
+func SCIMCreateUser(c *gin.Context) {
+	var input SCIMUser
+	if err := c.ShouldBindJSON(&input); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	changes := scimUserChangesFromResource(&input)
+	if changes.UserName == nil || *changes.UserName == "" {
+		scimError(c, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+
+	var existing int64
+	if err := db.DB.Model(&models.User{}).Where("LOWER(email) = ?", strings.ToLower(*changes.UserName)).Count(&existing).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to check userName")
+		return
+	}
+	if existing > 0 {
+		scimError(c, http.StatusConflict, "uniqueness", "userName is already taken")
+		return
+	}
+
+	user := models.User{Role: constants.UserRoleUser}
+	applySCIMUserAttributes(&user, changes)
+	if user.Name == "" {
+		user.Name = user.Email
+	}
+
+	if err := db.DB.Create(&user).Error; err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to create user")
+		return
+	}
+
+	if changes.Active != nil && !*changes.Active {
+		if err := helpers.DeactivateUser(db.DB, &user, nil, time.Now().UTC()); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to deactivate user")
+			return
+		}
+	}
+
+	recordSCIMUserEvent(c, constants.AuditActionUserCreate, nil, &user)
+
+	scimJSON(c, http.StatusCreated, toSCIMUser(c, &user, nil))
+}
+
+// SCIMReplaceUser godoc
+// @Summary Replace user (SCIM)
+// @Tags SCIM
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body SCIMUser true "SCIM user"
+// @Success 200 {object} SCIMUser
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Failure 409 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Users/{id} [put]
+func SCIMReplaceUser(c *gin.Context) {
+	user, ok := findSCIMUser(c)
+	if !ok {
+		return
+	}
+
+	var input SCIMUser
+	if err := c.ShouldBindJSON(&input); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	changes := scimUserChangesFromResource(&input)
+	if changes.ExternalID == nil {
+		var cleared *string
+		changes.ExternalID = &cleared
+	}
+
+	before := *user
+	if problem := saveSCIMUserChanges(user, changes); problem != nil {
+		problem.render(c)
+		return
+	}
+
+	recordSCIMUserEvent(c, constants.AuditActionUserUpdate, &before, user)
+
+	scimJSON(c, http.StatusOK, toSCIMUser(c, user, loadUserGroups(user.ID)))
+}
+
+// SCIMPatchUser godoc
+// @Summary Update user (SCIM)
+// @Description Apply PatchOp operations, e.g. {"op":"replace","path":"active","value":false} to deactivate
+// @Tags SCIM
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body SCIMPatchRequest true "SCIM PatchOp"
+// @Success 200 {object} SCIMUser
+// @Failure 400 {object} SCIMError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Failure 409 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Users/{id} [patch]
+func SCIMPatchUser(c *gin.Context) {
+	user, ok := findSCIMUser(c)
+	if !ok {
+		return
+	}
+
+	var input SCIMPatchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	var changes scimUserChanges
+	for _, operation := range input.Operations {
+		if problem := collectSCIMUserPatch(&changes, operation); problem != nil {
+			problem.render(c)
+			return
+		}
+	}
+
+	before := *user
+	if problem := saveSCIMUserChanges(user, changes); problem != nil {
+		problem.render(c)
+		return
+	}
+
+	recordSCIMUserEvent(c, constants.AuditActionUserUpdate, &before, user)
+
+	scimJSON(c, http.StatusOK, toSCIMUser(c, user, loadUserGroups(user.ID)))
+}
+
+// SCIMDeleteUser godoc
+// @Summary Deprovision user (SCIM)
+// @Description Deactivate the user, history is kept. Sessions are revoked and pending expenses flagged
+// @Tags SCIM
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} SCIMError
+// @Failure 500 {object} SCIMError
+// @Router /scim/v2/Users/{id} [delete]
+func SCIMDeleteUser(c *gin.Context) {
+	user, ok := findSCIMUser(c)
+	if !ok {
+		return
+	}
+
+	before := *user
+	if err := helpers.DeactivateUser(db.DB, user, nil, time.Now().UTC()); err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to deactivate user")
+		return
+	}
+
+	recordSCIMUserEvent(c, constants.AuditActionUserDeactivate, &before, user)
+
+	c.Status(http.StatusNoContent)
+}
+
+// recordSCIMUserEvent has no actor, the change comes from the identity provider
+func recordSCIMUserEvent(c *gin.Context, action constants.AuditAction, before, after *models.User) {
+	event := helpers.AuditEventInput{
+		Action:     action,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   after.ID,
+		After:      after,
+		Metadata:   map[string]interface{}{"source": "scim"},
+	}
+	if before != nil {
+		event.Before = before
+	}
+	helpers.RecordAuditEvent(db.DB, c, event)
+}
+
+func findSCIMUser(c *gin.Context) (*models.User, bool) {
+	id, ok := scimID(c)
+	if !ok {
+		scimError(c, http.StatusNotFound, "", "User not found")
+		return nil, false
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			scimError(c, http.StatusNotFound, "", "User not found")
+		} else {
+			scimError(c, http.StatusInternalServerError, "", "Failed to fetch user")
+		}
+		return nil, false
+	}
+	return &user, true
+}
+
+func loadUserGroups(userID int64) []models.Group {
+	var groups []models.Group
+	db.DB.Joins("JOIN user_group_members ON user_group_members.group_id = user_groups.id").
+		Where("user_group_members.user_id = ?", userID).
+		Order("user_groups.display_name").
+		Find(&groups)
+	return groups
+}
+
+func toSCIMUser(c *gin.Context, user *models.User, groups []models.Group) SCIMUser {
+	id := strconv.FormatInt(user.ID, 10)
+	active := user.IsActive()
+
+	given, family, _ := strings.Cut(user.Name, " ")
+	resource := SCIMUser{
+		Schemas:     []string{scimUserSchema},
+		ID:          id,
+		ExternalID:  user.ExternalID,
+		UserName:    user.Email,
+		Name:        &SCIMName{Formatted: user.Name, GivenName: given, FamilyName: family},
+		DisplayName: user.Name,
+		Emails:      []SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     scimLocation(c, "Users", id),
+		},
+	}
+
+	for _, group := range groups {
+		groupID := strconv.FormatInt(group.ID, 10)
+		resource.Groups = append(resource.Groups, SCIMMemberRef{
+			Value:   groupID,
+			Display: group.DisplayName,
+			Ref:     scimLocation(c, "Groups", groupID),
+		})
+	}
+	return resource
+}
+
+func scimUserChangesFromResource(input *SCIMUser) scimUserChanges {
+	changes := scimUserChanges{Active: input.Active}
+
+	userName := input.UserName
+	if userName == "" {
+		for _, email := range input.Emails {
+			if email.Primary || userName == "" {
+				userName = email.Value
+			}
+		}
+	}
+	if userName != "" {
+		changes.UserName = &userName
+	}
+
+	if input.DisplayName != "" {
+		changes.DisplayName = &input.DisplayName
+	}
+	if input.Name != nil {
+		if input.Name.Formatted != "" && changes.DisplayName == nil {
+			changes.DisplayName = &input.Name.Formatted
+		}
+		if input.Name.GivenName != "" {
+			changes.GivenName = &input.Name.GivenName
+		}
+		if input.Name.FamilyName != "" {
+			changes.FamilyName = &input.Name.FamilyName
+		}
+	}
+	if input.ExternalID != nil {
+		changes.ExternalID = &input.ExternalID
+	}
+	return changes
+}
+
+// collectSCIMUserPatch records one PatchOp operation, pathless operations carry an object of attributes
+func collectSCIMUserPatch(changes *scimUserChanges, operation SCIMPatchOperation) *scimProblem {
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return scimInvalidValue("unsupported op " + operation.Op)
+	}
+
+	if operation.Path == "" {
+		if op == "remove" {
+			return &scimProblem{http.StatusBadRequest, "noTarget", "remove needs a path"}
+		}
+
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+			return scimInvalidValue("value must be an object when path is omitted")
+		}
+		for path, value := range attributes {
+			if problem := collectSCIMUserPatch(changes, SCIMPatchOperation{Op: op, Path: path, Value: value}); problem != nil {
+				return problem
+			}
+		}
+		return nil
+	}
+
+	path := strings.ToLower(strings.TrimPrefix(operation.Path, scimUserSchema+":"))
+
+	if op == "remove" {
+		if path != "externalid" {
+			return scimInvalidValue("cannot remove " + operation.Path)
+		}
+		var cleared *string
+		changes.ExternalID = &cleared
+		return nil
+	}
+
+	switch {
+	case path == "active":
+		var active bool
+		if err := json.Unmarshal(operation.Value, &active); err != nil {
+			// some clients send "False" as a string
+			var raw string
+			if json.Unmarshal(operation.Value, &raw) != nil {
+				return scimInvalidValue("active must be a boolean")
+			}
+			parsed, err := strconv.ParseBool(strings.ToLower(raw))
+			if err != nil {
+				return scimInvalidValue("active must be a boolean")
+			}
+			active = parsed
+		}
+		changes.Active = &active
+
+	case path == "username", strings.HasPrefix(path, "emails"):
+		if strings.HasPrefix(path, "emails") && !strings.HasSuffix(path, ".value") {
+			var emails []SCIMEmail
+			if err := json.Unmarshal(operation.Value, &emails); err != nil || len(emails) == 0 {
+				return scimInvalidValue("emails must be a list")
+			}
+			*changes = mergeSCIMUserChanges(*changes, scimUserChangesFromResource(&SCIMUser{Emails: emails}))
+			return nil
+		}
+		value, problem := scimStringValue(operation)
+		if problem != nil {
+			return problem
+		}
+		changes.UserName = &value
+
+	case path == "displayname", path == "name.formatted":
+		value, problem := scimStringValue(operation)
+		if problem != nil {
+			return problem
+		}
+		changes.DisplayName = &value
+
+	case path == "name.givenname":
+		value, problem := scimStringValue(operation)
+		if problem != nil {
+			return problem
+		}
+		changes.GivenName = &value
+
+	case path == "name.familyname":
+		value, problem := scimStringValue(operation)
+		if problem != nil {
+			return problem
+		}
+		changes.FamilyName = &value
+
+	case path == "name":
+		var name SCIMName
+		if err := json.Unmarshal(operation.Value, &name); err != nil {
+			return scimInvalidValue("name must be an object")
+		}
+		*changes = mergeSCIMUserChanges(*changes, scimUserChangesFromResource(&SCIMUser{Name: &name}))
+
+	case path == "externalid":
+		value, problem := scimStringValue(operation)
+		if problem != nil {
+			return problem
+		}
+		externalID := &value
+		changes.ExternalID = &externalID
+
+	default:
+		// attributes we do not store (title, locale, enterprise extension...) are ignored
+	}
+	return nil
+}
+
+func mergeSCIMUserChanges(base, extra scimUserChanges) scimUserChanges {
+	if extra.UserName != nil {
+		base.UserName = extra.UserName
+	}
+	if extra.DisplayName != nil {
+		base.DisplayName = extra.DisplayName
+	}
+	if extra.GivenName != nil {
+		base.GivenName = extra.GivenName
+	}
+	if extra.FamilyName != nil {
+		base.FamilyName = extra.FamilyName
+	}
+	return base
+}
+
+func scimStringValue(operation SCIMPatchOperation) (string, *scimProblem) {
+	var value string
+	if err := json.Unmarshal(operation.Value, &value); err != nil {
+		return "", scimInvalidValue(operation.Path + " must be a string")
+	}
+	return value, nil
+}
+
+func applySCIMUserAttributes(user *models.User, changes scimUserChanges) {
+	if changes.UserName != nil {
+		user.Email = strings.ToLower(strings.TrimSpace(*changes.UserName))
+	}
+	if changes.ExternalID != nil {
+		user.ExternalID = *changes.ExternalID
+	}
+
+	switch {
+	case changes.DisplayName != nil:
+		user.Name = *changes.DisplayName
+	case changes.GivenName != nil || changes.FamilyName != nil:
+		given, family, _ := strings.Cut(user.Name, " ")
+		if changes.GivenName != nil {
+			given = *changes.GivenName
+		}
+		if changes.FamilyName != nil {
+			family = *changes.FamilyName
+		}
+		user.Name = strings.TrimSpace(given + " " + family)
+	}
+}
+
+// saveSCIMUserChanges persists attributes and then applies the active flag, deactivation
+// revokes sessions and flags pending expenses
+func saveSCIMUserChanges(user *models.User, changes scimUserChanges) *scimProblem {
+	if changes.UserName != nil && !strings.EqualFold(*changes.UserName, user.Email) {
+		var taken int64
+		err := db.DB.Model(&models.User{}).
+			Where("LOWER(email) = ? AND id <> ?", strings.ToLower(*changes.UserName), user.ID).
+			Count(&taken).Error
+		if err != nil {
+			return &scimProblem{http.StatusInternalServerError, "", "Failed to check userName"}
+		}
+		if taken > 0 {
+			return &scimProblem{http.StatusConflict, "uniqueness", "userName is already taken"}
+		}
+	}
+
+	applySCIMUserAttributes(user, changes)
+	if user.Email == "" {
+		return scimInvalidValue("userName is required")
+	}
+
+	if err := db.DB.Save(user).Error; err != nil {
+		return &scimProblem{http.StatusInternalServerError, "", "Failed to update user"}
+	}
+
+	if changes.Active == nil {
+		return nil
+	}
+
+	var err error
+	if *changes.Active {
+		err = helpers.ReactivateUser(db.DB, user)
+	} else {
+		err = helpers.DeactivateUser(db.DB, user, nil, time.Now().UTC())
+	}
+	if err != nil {
+		return &scimProblem{http.StatusInternalServerError, "", "Failed to update user status"}
+	}
+	return nil
+}
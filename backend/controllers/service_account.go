@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// service accounts get a unique address that can never receive mail
+const serviceAccountEmailDomain = "service-accounts.invalid"
+
+type CreateServiceAccountRequest struct {
+	Name      string             `json:"name" binding:"required" example:"Accounting integration"`
+	Role      constants.UserRole `json:"role" example:"manager"` // defaults to user
+	ManagerID *int64             `json:"manager_id" example:"1"` // approver of expenses it submits
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string               `json:"name" binding:"required" example:"nightly export"`
+	Scopes    []constants.APIScope `json:"scopes" binding:"required" example:"expenses:read,reports:read"`
+	ExpiresAt *time.Time           `json:"expires_at" example:"2026-12-31T00:00:00Z"` // defaults to API_KEY_TTL from now
+}
+
+type APIKeyResponse struct {
+	APIKey models.APIKey `json:"api_key"`
+	Key    string        `json:"key" example:"etk_q9Xc..."` // only returned once
+}
+
+// CreateServiceAccount godoc
+// @Summary Create a service account
+// @Description Create a user for a machine client, it cannot sign in and authenticates with API keys (manager only)
+// @Tags ManagerServiceAccounts
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateServiceAccountRequest true "Service account payload"
+// @Success 201 {object} models.User
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/service-accounts [post]
+func CreateServiceAccount(c *gin.Context) {
+	var input CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Role == "" {
+		input.Role = constants.UserRoleUser
+	}
+	if err := rules.ValidateRole(input.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateManager(0, input.ManagerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := models.User{
+		Email:          uuid.NewString() + "@" + serviceAccountEmailDomain,
+		Name:           strings.TrimSpace(input.Name),
+		Role:           input.Role,
+		ManagerID:      input.ManagerID,
+		ServiceAccount: true,
+	}
+	if err := db.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserCreate,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		After:      user,
+	})
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// GetServiceAccounts godoc
+// @Summary List service accounts
+// @Description List service accounts, deactivate them through /manager/users/{id}/deactivate (manager only)
+// @Tags ManagerServiceAccounts
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]models.User}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/service-accounts [get]
+func GetServiceAccounts(c *gin.Context) {
+	var users []models.User
+	if err := db.DB.Where("service_account = ?", true).Order("name ASC").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
+
+// CreateAPIKey godoc
+// @Summary Issue an API key
+// @Description Issue a scoped, expiring API key for a service account, sent as "Authorization: Bearer <key>" (manager only)
+// @Tags ManagerServiceAccounts
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Service account ID"
+// @Param request body CreateAPIKeyRequest true "API key payload"
+// @Success 201 {object} APIKeyResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/service-accounts/{id}/keys [post]
+func CreateAPIKey(c *gin.Context) {
+	var input CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	creator, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var account models.User
+	if err := db.DB.First(&account, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return
+	}
+	if err := rules.CanIssueAPIKey(&account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := rules.ValidateScopes(input.Scopes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(helpers.APIKeyTTL())
+	if input.ExpiresAt != nil {
+		expiresAt = input.ExpiresAt.UTC()
+	}
+	if err := rules.ValidateAPIKeyExpiry(expiresAt, now, helpers.APIKeyMaxTTL()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, prefix, hash, err := helpers.NewAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	apiKey := models.APIKey{
+		UserID:      account.ID,
+		Name:        strings.TrimSpace(input.Name),
+		Prefix:      prefix,
+		KeyHash:     hash,
+		Scopes:      input.Scopes,
+		ExpiresAt:   &expiresAt,
+		CreatedByID: &creator.ID,
+	}
+	if err := db.DB.Create(&apiKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save API key"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionAPIKeyCreate,
+		EntityType: constants.AuditEntityAPIKey,
+		EntityID:   apiKey.ID,
+		After:      apiKey,
+	})
+
+	c.JSON(http.StatusCreated, APIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description List the API keys of a service account with their last use (manager only)
+// @Tags ManagerServiceAccounts
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Service account ID"
+// @Success 200 {object} object{data=[]models.APIKey}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/service-accounts/{id}/keys [get]
+func GetAPIKeys(c *gin.Context) {
+	var keys []models.APIKey
+	if err := db.DB.Where("user_id = ?", c.Param("id")).Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key, requests using it are refused right away (manager only)
+// @Tags ManagerServiceAccounts
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Service account ID"
+// @Param key_id path int true "API key ID"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/service-accounts/{id}/keys/{key_id} [delete]
+func RevokeAPIKey(c *gin.Context) {
+	var key models.APIKey
+	if err := db.DB.First(&key, "id = ? AND user_id = ?", c.Param("key_id"), c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		if err := db.DB.Save(&key).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+			return
+		}
+
+		helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+			Action:     constants.AuditActionAPIKeyRevoke,
+			EntityType: constants.AuditEntityAPIKey,
+			EntityID:   key.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "API key has been revoked",
+	})
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"k3J9..."` // optional, the refresh_token cookie is used by browsers
+}
+
+type TokenResponse struct {
+	Token     string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6Ikp"`
+	ExpiresIn int    `json:"expires_in" example:"900"`
+}
+
+type SessionResponse struct {
+	models.UserSession
+	Current bool `json:"current" example:"true"`
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token, the refresh and CSRF tokens are rotated on every call
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body RefreshRequest false "Refresh token when not sent as cookie"
+// @Success 200 {object} TokenResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/refresh [post]
+func Refresh(c *gin.Context) {
+	refreshToken := requestRefreshToken(c)
+	if refreshToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing refresh token"})
+		return
+	}
+
+	now := time.Now().UTC()
+	hash := helpers.HashToken(refreshToken)
+
+	var session models.UserSession
+	if err := db.DB.First(&session, "refresh_token_hash = ?", hash).Error; err != nil {
+		// a rotated token coming back means it was stolen, kill the whole session
+		if errors.Is(err, gorm.ErrRecordNotFound) && revokeReusedToken(hash, now) {
+			helpers.ClearAuthCookies(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": rules.ErrRefreshTokenReused.Error()})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": rules.ErrInvalidRefreshToken.Error()})
+		return
+	}
+
+	if err := rules.ValidateSession(&session, now); err != nil {
+		helpers.ClearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, session.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if err := rules.CanAuthenticate(&user); err != nil {
+		helpers.ClearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	newRefreshToken, newHash, err := helpers.NewRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	csrfToken, csrfHash, err := helpers.NewCSRFToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// compare-and-swap on the old hash so two concurrent refreshes cannot both win
+	result := db.DB.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  newHash,
+			"previous_token_hash": hash,
+			"csrf_token_hash":     csrfHash,
+			"last_used_at":        now,
+			"ip_address":          c.ClientIP(),
+			"user_agent":          c.Request.UserAgent(),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": rules.ErrRefreshTokenReused.Error()})
+		return
+	}
+
+	accessToken, err := helpers.IssueAccessToken(&user, session.UUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	helpers.SetAuthCookies(c, &user, accessToken, newRefreshToken)
+	helpers.SetCSRFCookie(c, csrfToken)
+
+	c.JSON(http.StatusOK, TokenResponse{
+		Token:     accessToken,
+		ExpiresIn: int(helpers.AccessTokenTTL().Seconds()),
+	})
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the current session and clear auth cookies, a session named by the cookies needs the X-CSRF-Token header
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body RefreshRequest false "Refresh token when not sent as cookie"
+// @Success 200 {object} MessageResponse
+// @Failure 403 {object} httputil.HTTPError
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	session, fromCookie := logoutSession(c)
+
+	// the browser attaches the cookies to a cross-site form as well, which must not end the session
+	if session != nil && fromCookie && !helpers.CSRFTokenMatches(*session, c.GetHeader(helpers.CSRFTokenHeader)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": rules.ErrInvalidCSRFToken.Error()})
+		return
+	}
+
+	if session != nil {
+		if err := db.DB.Model(session).Update("revoked_at", time.Now().UTC()).Error; err != nil {
+			log.Printf("Failed to revoke session on logout: %v", err)
+		}
+	}
+
+	helpers.ClearAuthCookies(c)
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Logged out",
+	})
+}
+
+// logoutSession finds the active session named by the refresh token, or else by the access token
+// cookie, fromCookie reports whether the browser sent it on its own
+func logoutSession(c *gin.Context) (session *models.UserSession, fromCookie bool) {
+	var found models.UserSession
+
+	if token, err := c.Cookie(helpers.RefreshTokenCookie); err == nil && token != "" {
+		if err := db.DB.First(&found, "refresh_token_hash = ? AND revoked_at IS NULL", helpers.HashToken(token)).Error; err != nil {
+			return nil, true
+		}
+		return &found, true
+	}
+
+	var input RefreshRequest
+	if err := c.ShouldBindJSON(&input); err == nil && input.RefreshToken != "" {
+		if err := db.DB.First(&found, "refresh_token_hash = ? AND revoked_at IS NULL", helpers.HashToken(input.RefreshToken)).Error; err != nil {
+			return nil, false
+		}
+		return &found, false
+	}
+
+	accessToken, err := c.Cookie(helpers.AccessTokenCookie)
+	if err != nil {
+		return nil, false
+	}
+	claims, err := helpers.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, true
+	}
+	sid, ok := claims["sid"].(string)
+	if !ok {
+		return nil, true
+	}
+	if err := db.DB.First(&found, "uuid = ? AND revoked_at IS NULL", sid).Error; err != nil {
+		return nil, true
+	}
+	return &found, true
+}
+
+// GetSessions godoc
+// @Summary List sessions
+// @Description List active sessions of the authenticated user
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]SessionResponse}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /sessions [get]
+func GetSessions(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var sessions []models.UserSession
+	if err := db.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now().UTC()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	currentSID := c.GetString("session_id")
+	data := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, SessionResponse{
+			UserSession: session,
+			Current:     session.UUID.String() == currentSID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Revoke one of the authenticated user's sessions, its access token stops working immediately
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Session UUID"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /sessions/{uuid} [delete]
+func RevokeSession(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	result := db.DB.Model(&models.UserSession{}).
+		Where("uuid = ? AND user_id = ? AND revoked_at IS NULL", sessionID, user.ID).
+		Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionSessionRevoke,
+		EntityType: constants.AuditEntitySession,
+		EntityID:   sessionID,
+	})
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Session has been revoked",
+	})
+}
+
+// startSession stores a new session and returns its access and refresh tokens
+func startSession(c *gin.Context, user *models.User) (accessToken, refreshToken string, err error) {
+	return startSessionAt(c, user, nil)
+}
+
+// startSessionAt also records when the session passed the second factor, nil when it did not
+func startSessionAt(c *gin.Context, user *models.User, twoFactorVerifiedAt *time.Time) (accessToken, refreshToken string, err error) {
+	refreshToken, hash, err := helpers.NewRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	csrfToken, csrfHash, err := helpers.NewCSRFToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now().UTC()
+	session := models.UserSession{
+		UUID:                uuid.New(),
+		UserID:              user.ID,
+		RefreshTokenHash:    hash,
+		CSRFTokenHash:       &csrfHash,
+		UserAgent:           c.Request.UserAgent(),
+		IPAddress:           c.ClientIP(),
+		ExpiresAt:           now.Add(helpers.RefreshTokenTTL()),
+		LastUsedAt:          now,
+		TwoFactorVerifiedAt: twoFactorVerifiedAt,
+	}
+	if err := db.DB.Create(&session).Error; err != nil {
+		return "", "", err
+	}
+
+	accessToken, err = helpers.IssueAccessToken(user, session.UUID)
+	if err != nil {
+		return "", "", err
+	}
+
+	// the CSRF token lives as long as the session, callers only set the auth cookies
+	helpers.SetCSRFCookie(c, csrfToken)
+
+	return accessToken, refreshToken, nil
+}
+
+func requestRefreshToken(c *gin.Context) string {
+	if token, err := c.Cookie(helpers.RefreshTokenCookie); err == nil && token != "" {
+		return token
+	}
+
+	var input RefreshRequest
+	if err := c.ShouldBindJSON(&input); err == nil {
+		return input.RefreshToken
+	}
+	return ""
+}
+
+func revokeReusedToken(hash string, now time.Time) bool {
+	result := db.DB.Model(&models.UserSession{}).
+		Where("previous_token_hash = ?", hash).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		log.Printf("Failed to revoke session after refresh token reuse: %v", result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
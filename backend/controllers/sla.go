@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SLAMetrics struct {
+	PendingTotal      int64   `json:"pending_total" example:"12"`
+	ReminderOverdue   int64   `json:"reminder_overdue" example:"3"`
+	BreachedPending   int64   `json:"breached_pending" example:"1"`
+	EscalatedPending  int64   `json:"escalated_pending" example:"1"`
+	DecidedInPeriod   int64   `json:"decided_in_period" example:"40"`
+	BreachedInPeriod  int64   `json:"breached_in_period" example:"4"`
+	BreachRate        float64 `json:"breach_rate" example:"0.1"`
+	PeriodDays        int     `json:"period_days" example:"30"`
+	OldestPendingDays float64 `json:"oldest_pending_days" example:"2.5"`
+}
+
+type ManagerDashboardResponse struct {
+	Analytics *services.SpendAnalytics `json:"analytics"`
+	SLA       SLAMetrics               `json:"sla"`
+}
+
+type UpdateSLAPolicyRequest struct {
+	ReminderAfterMinutes int `json:"reminder_after_minutes" binding:"required,min=1" example:"480"`
+	EscalateAfterMinutes int `json:"escalate_after_minutes" binding:"required,min=1" example:"1440"`
+}
+
+const slaMetricsPeriodDays = 30
+
+// ManagerDashboard godoc
+// @Summary Manager dashboard
+// @Description Spend analytics of expenses submitted in the period, by status, user, category and month, with approval turnaround, auto-approval and payment failure rates, plus approval SLA breach metrics (manager only). Analytics are cached per period for ANALYTICS_CACHE_TTL.
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param from query string false "Submitted at or after (RFC 3339 or YYYY-MM-DD), defaults to the start of the month 11 months ago"
+// @Param to query string false "Submitted before (RFC 3339), or on or before (YYYY-MM-DD)"
+// @Success 200 {object} ManagerDashboardResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/dashboard [get]
+func ManagerDashboard(c *gin.Context) {
+	now := time.Now().UTC()
+
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from == nil {
+		defaultFrom := services.DefaultAnalyticsFrom(now)
+		from = &defaultFrom
+	}
+
+	analytics, _, err := services.CachedSpendAnalytics(db.DB, from, to, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute spend analytics"})
+		return
+	}
+
+	metrics, err := getSLAMetrics(now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute SLA metrics"})
+		return
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(services.AnalyticsCacheTTL().Seconds())))
+	c.JSON(http.StatusOK, ManagerDashboardResponse{
+		Analytics: analytics,
+		SLA:       metrics,
+	})
+}
+
+// GetSLAPolicies godoc
+// @Summary List SLA policies
+// @Description List approval SLA policies (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]models.SLAPolicy}
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/sla-policies [get]
+func GetSLAPolicies(c *gin.Context) {
+	var policies []models.SLAPolicy
+	if err := db.DB.Order("min_amount_idr ASC").Find(&policies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch SLA policies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+// UpdateSLAPolicy godoc
+// @Summary Update an SLA policy
+// @Description Change reminder and escalation durations, applies to approvals created afterwards (manager only)
+// @Tags Manager
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "SLA policy ID"
+// @Param request body UpdateSLAPolicyRequest true "SLA durations"
+// @Success 200 {object} models.SLAPolicy
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/sla-policies/{id} [put]
+func UpdateSLAPolicy(c *gin.Context) {
+	var input UpdateSLAPolicyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.EscalateAfterMinutes <= input.ReminderAfterMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "escalation must come after the reminder"})
+		return
+	}
+
+	var policy models.SLAPolicy
+	if err := db.DB.First(&policy, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SLA policy not found"})
+		return
+	}
+
+	before := policy
+	policy.ReminderAfterMinutes = input.ReminderAfterMinutes
+	policy.EscalateAfterMinutes = input.EscalateAfterMinutes
+	if err := db.DB.Save(&policy).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update SLA policy"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionSLAPolicyUpdate,
+		EntityType: constants.AuditEntitySLAPolicy,
+		EntityID:   policy.ID,
+		Before:     before,
+		After:      policy,
+	})
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// assignApprovalSLA routes a new approval to the submitter's manager and starts its SLA clock
+func assignApprovalSLA(expense *models.Expense, approval *models.Approval) error {
+	var submitter models.User
+	if err := db.DB.Select("id", "manager_id").First(&submitter, expense.UserID).Error; err != nil {
+		return err
+	}
+	approval.AssigneeID = submitter.ManagerID
+
+	var policies []models.SLAPolicy
+	if err := db.DB.Find(&policies).Error; err != nil {
+		return err
+	}
+	rules.ApplySLAPolicy(approval, rules.MatchSLAPolicy(policies, expense.AmountIDR), approval.CreatedAt)
+
+	return nil
+}
+
+func getSLAMetrics(now time.Time) (SLAMetrics, error) {
+	metrics := SLAMetrics{PeriodDays: slaMetricsPeriodDays}
+
+	pending := func() *gorm.DB {
+		return db.DB.Model(&models.Approval{}).
+			Where("status = ? AND sla_policy_id IS NOT NULL", constants.ApprovalStatusPending)
+	}
+
+	if err := pending().Count(&metrics.PendingTotal).Error; err != nil {
+		return metrics, err
+	}
+	if err := pending().Where("reminder_due_at <= ?", now).Count(&metrics.ReminderOverdue).Error; err != nil {
+		return metrics, err
+	}
+	if err := pending().Where("escalation_due_at <= ? OR escalation_level > 0", now).Count(&metrics.BreachedPending).Error; err != nil {
+		return metrics, err
+	}
+	if err := pending().Where("escalation_level > 0").Count(&metrics.EscalatedPending).Error; err != nil {
+		return metrics, err
+	}
+
+	var oldest struct{ CreatedAt *time.Time }
+	if err := pending().Select("MIN(created_at) AS created_at").Scan(&oldest).Error; err != nil {
+		return metrics, err
+	}
+	if oldest.CreatedAt != nil {
+		metrics.OldestPendingDays = now.Sub(*oldest.CreatedAt).Hours() / 24
+	}
+
+	decided := func() *gorm.DB {
+		return db.DB.Model(&models.Approval{}).
+			Where("status <> ? AND sla_policy_id IS NOT NULL", constants.ApprovalStatusPending).
+			Where("updated_at >= ?", now.AddDate(0, 0, -slaMetricsPeriodDays))
+	}
+
+	if err := decided().Count(&metrics.DecidedInPeriod).Error; err != nil {
+		return metrics, err
+	}
+	if err := decided().Where("escalation_level > 0 OR updated_at > escalation_due_at").Count(&metrics.BreachedInPeriod).Error; err != nil {
+		return metrics, err
+	}
+	if metrics.DecidedInPeriod > 0 {
+		metrics.BreachRate = float64(metrics.BreachedInPeriod) / float64(metrics.DecidedInPeriod)
+	}
+
+	return metrics, nil
+}
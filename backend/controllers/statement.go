@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const documentTimeLayout = "2006-01-02 15:04"
+
+var statementColumns = []helpers.PDFColumn{
+	{Title: "Paid At", Width: 78},
+	{Title: "Expense", Width: 46},
+	{Title: "Description", Width: 150},
+	{Title: "Category", Width: 58},
+	{Title: "Payment Reference", Width: 92},
+	{Title: "Amount", Width: helpers.PDFContentWidth - 424, Right: true},
+}
+
+var voucherHistoryColumns = []helpers.PDFColumn{
+	{Title: "At", Width: 78},
+	{Title: "From", Width: 64},
+	{Title: "To", Width: 64},
+	{Title: "By", Width: 130},
+	{Title: "Reason", Width: helpers.PDFContentWidth - 336},
+}
+
+// GetUserStatement godoc
+// @Summary Download own reimbursement statement
+// @Description PDF listing the authenticated user's expenses paid in the period with their payment references and the total paid. Amounts are IDR, times Asia/Jakarta.
+// @Tags Expenses
+// @Security CookieAuth
+// @Produce application/pdf
+// @Param from query string false "Paid at or after (RFC 3339 or YYYY-MM-DD), defaults to the start of the month of to"
+// @Param to query string false "Paid before (RFC 3339), or on or before (YYYY-MM-DD), defaults to now"
+// @Success 200 {string} string "PDF file"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /user/statement [get]
+func GetUserStatement(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	writeStatement(c, user.ID)
+}
+
+// GetStatement godoc
+// @Summary Download a user's reimbursement statement
+// @Description PDF listing the user's expenses paid in the period with their payment references and the total paid. Amounts are IDR, times Asia/Jakarta (manager only).
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Produce application/pdf
+// @Param id path int true "User ID"
+// @Param from query string false "Paid at or after (RFC 3339 or YYYY-MM-DD), defaults to the start of the month of to"
+// @Param to query string false "Paid before (RFC 3339), or on or before (YYYY-MM-DD), defaults to now"
+// @Success 200 {string} string "PDF file"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users/{id}/statement [get]
+func GetStatement(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	writeStatement(c, userID)
+}
+
+// GetUserExpenseVoucher godoc
+// @Summary Download a voucher of an own expense
+// @Description PDF voucher of one of the authenticated user's approved or paid expenses, with the approval decision, payment reference and status history. Amounts are IDR, times Asia/Jakarta.
+// @Tags Expenses
+// @Security CookieAuth
+// @Produce application/pdf
+// @Param id path int true "Expense ID"
+// @Success 200 {string} string "PDF file"
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /user/expenses/{id}/voucher [get]
+func GetUserExpenseVoucher(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	writeVoucher(c, &user.ID)
+}
+
+// GetExpenseVoucher godoc
+// @Summary Download an expense voucher
+// @Description PDF voucher of an approved or paid expense, with the approval decision, payment reference and status history. Amounts are IDR, times Asia/Jakarta (manager only).
+// @Tags ManagerExpenses
+// @Security CookieAuth
+// @Produce application/pdf
+// @Param id path int true "Expense ID"
+// @Success 200 {string} string "PDF file"
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/expenses/{id}/voucher [get]
+func GetExpenseVoucher(c *gin.Context) {
+	writeVoucher(c, nil)
+}
+
+func writeStatement(c *gin.Context, userID int64) {
+	now := time.Now().UTC()
+
+	from, to, err := helpers.GetTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if to == nil {
+		to = &now
+	}
+	if from == nil {
+		monthStart := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if !monthStart.Before(*to) {
+			monthStart = monthStart.AddDate(0, -1, 0)
+		}
+		from = &monthStart
+	}
+
+	statement, err := services.UserStatement(db.DB, userID, *from, *to, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build statement"})
+		return
+	}
+
+	doc := helpers.NewPDFDocument("Reimbursement statement")
+	doc.Heading("Reimbursement Statement")
+	doc.Field("Employee", statement.User.Name)
+	doc.Field("Email", statement.User.Email)
+	doc.Field("Period", documentTime(statement.From)+" to "+documentTime(statement.To)+" ("+helpers.ExportTimeZone+")")
+	doc.Field("Generated", documentTime(statement.GeneratedAt))
+
+	doc.Section("Payments")
+	if len(statement.Lines) == 0 {
+		doc.Text("No expenses were paid in this period.")
+	} else {
+		rows := make([][]string, len(statement.Lines))
+		for i, line := range statement.Lines {
+			rows[i] = []string{
+				documentTime(line.ProcessedAt),
+				"#" + strconv.FormatInt(line.ExpenseID, 10),
+				line.Description,
+				string(line.Category),
+				paymentReference(line.PaymentReference),
+				helpers.FormatIDR(line.AmountIDR),
+			}
+		}
+		doc.Table(statementColumns, rows)
+	}
+	doc.TableTotal(statementColumns, []string{
+		"Total paid", "", expenseCount(statement.Total.Count), "", "", helpers.FormatIDR(statement.Total.AmountIDR),
+	})
+
+	filename := "statement-" + strconv.FormatInt(userID, 10) + "-" + helpers.ExportTime(statement.From).Format("20060102") + ".pdf"
+	writePDF(c, doc, filename)
+}
+
+// writeVoucher answers with the voucher of the expense in the id parameter, ownerID
+// limits it to that user's expenses
+func writeVoucher(c *gin.Context, ownerID *int64) {
+	expenseID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		return
+	}
+
+	voucher, err := services.ExpenseVoucher(db.DB, expenseID, ownerID, time.Now().UTC())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		return
+	}
+	if errors.Is(err, rules.ErrExpenseNotApproved) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build voucher"})
+		return
+	}
+
+	expense := voucher.Expense
+	doc := helpers.NewPDFDocument("Expense voucher #" + strconv.FormatInt(expense.ID, 10))
+	doc.Heading("Expense Voucher")
+	doc.Field("Voucher", "#"+strconv.FormatInt(expense.ID, 10))
+	doc.Field("Generated", documentTime(voucher.GeneratedAt)+" ("+helpers.ExportTimeZone+")")
+
+	doc.Section("Expense")
+	if expense.User != nil {
+		doc.Field("Employee", expense.User.Name+" <"+expense.User.Email+">")
+	}
+	doc.Field("Expense UUID", expense.UUID.String())
+	doc.Field("Category", string(expense.Category))
+	doc.Field("Description", expense.Description)
+	doc.Field("Amount", helpers.FormatIDR(expense.AmountIDR))
+	doc.Field("Submitted", documentTime(expense.SubmittedAt))
+	doc.Field("Status", string(expense.Status))
+
+	doc.Section("Approval")
+	switch {
+	case expense.AutoApproved:
+		doc.Field("Decision", "Auto-approved, below the approval threshold of "+helpers.FormatIDR(constants.ApprovalThreshold))
+	case voucher.Approval != nil:
+		doc.Field("Decision", string(voucher.Approval.Status))
+	}
+	if voucher.ApproverName != nil {
+		doc.Field("Approved by", *voucher.ApproverName)
+	}
+	if voucher.OnBehalfOfName != nil {
+		doc.Field("On behalf of", *voucher.OnBehalfOfName)
+	}
+	if voucher.DecidedAt != nil {
+		doc.Field("Decided", documentTime(*voucher.DecidedAt))
+	}
+	if voucher.Approval != nil && voucher.Approval.Notes != "" {
+		doc.Field("Notes", voucher.Approval.Notes)
+	}
+
+	doc.Section("Payment")
+	if expense.ProcessedAt != nil {
+		doc.Field("Paid", documentTime(*expense.ProcessedAt))
+		doc.Field("Payment reference", paymentReference(expense.PaymentReference))
+		doc.Field("External ID", expense.UUID.String())
+	} else {
+		doc.Text("Approved, the payment has not been completed yet.")
+	}
+
+	if len(voucher.History) > 0 {
+		doc.Section("History")
+		rows := make([][]string, len(voucher.History))
+		for i, entry := range voucher.History {
+			rows[i] = []string{
+				documentTime(entry.CreatedAt),
+				string(entry.FromStatus),
+				string(entry.ToStatus),
+				historyActor(entry),
+				entry.Reason,
+			}
+		}
+		doc.Table(voucherHistoryColumns, rows)
+	}
+
+	writePDF(c, doc, "voucher-"+strconv.FormatInt(expense.ID, 10)+".pdf")
+}
+
+func writePDF(c *gin.Context, doc *helpers.PDFDocument, filename string) {
+	helpers.SetDownloadHeaders(c, filename, "application/pdf")
+	c.Status(http.StatusOK)
+	if _, err := doc.WriteTo(c.Writer); err != nil {
+		log.Printf("Failed to write %s: %v", filename, err)
+	}
+}
+
+func documentTime(t time.Time) string {
+	return helpers.ExportTime(t).Format(documentTimeLayout)
+}
+
+// paymentReference is the processor's id, payments made before it was kept have none
+func paymentReference(reference *string) string {
+	if reference == nil || *reference == "" {
+		return "-"
+	}
+	return *reference
+}
+
+func expenseCount(count int64) string {
+	if count == 1 {
+		return "1 expense"
+	}
+	return strconv.FormatInt(count, 10) + " expenses"
+}
+
+func historyActor(entry services.VoucherEntry) string {
+	if entry.ActorName == nil {
+		return "system"
+	}
+	actor := *entry.ActorName
+	if entry.OnBehalfOfName != nil {
+		actor += " for " + *entry.OnBehalfOfName
+	}
+	return actor
+}
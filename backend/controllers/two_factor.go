@@ -0,0 +1,477 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type TwoFactorChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"eyJhbGciOiJFZERTQSIsImtpZCI6..."` // send to /auth/login/2fa with the code
+	ExpiresIn   int    `json:"expires_in" example:"300"`
+}
+
+type LoginTwoFactorRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJFZERTQSIsImtpZCI6..."`
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"k7m2q-x9d4a"` // when the authenticator is lost
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Expense%20Tracker:alice@manager.com?secret=JBSWY3DPEHPK3PXP&issuer=Expense+Tracker"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code" example:"k7m2q-x9d4a"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"Expense-Tracker-7"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7m2q-x9d4a,p3n8r-w2c6e"` // only returned once
+}
+
+// LoginTwoFactor godoc
+// @Summary Complete login with a second factor
+// @Description Exchange the token returned by /auth/login and a TOTP or recovery code for a session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body LoginTwoFactorRequest true "Login token and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 429 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/login/2fa [post]
+func LoginTwoFactor(c *gin.Context) {
+	var input LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := helpers.ParseMFAToken(input.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": rules.ErrInvalidMFAToken.Error()})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": rules.ErrInvalidMFAToken.Error()})
+		return
+	}
+	if err := rules.CanAuthenticate(&user); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	// codes are short, guesses count towards the same lockout as passwords
+	if retryAfter, err := checkLoginThrottle(c, user.Email); err != nil {
+		helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultThrottled)
+		loginThrottled(c, retryAfter, err)
+		return
+	}
+
+	if err := verifySecondFactor(&user, input.Code, input.RecoveryCode); err != nil {
+		helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultInvalidSecondFactor)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now().UTC()
+	accessToken, refreshToken, err := startSessionAt(c, &user, &now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultSuccess)
+	helpers.SetAuthCookies(c, &user, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token: accessToken,
+		ID:    uint(user.ID),
+		Role:  user.Role,
+		Name:  user.Name,
+		Email: user.Email,
+	})
+}
+
+// SetupTwoFactor godoc
+// @Summary Start two-factor enrolment
+// @Description Generate a new TOTP secret, it only takes effect once confirmed with /auth/2fa/enable
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} TwoFactorSetupResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/2fa/setup [post]
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if user.HasTwoFactor() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrTwoFactorAlreadyEnabled.Error()})
+		return
+	}
+
+	secret, err := services.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	sealed, err := helpers.SealTOTPSecret(secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	if err := db.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"totp_secret":       sealed,
+		"totp_last_counter": 0,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: services.OTPAuthURI(helpers.TOTPIssuer(), user.Email, secret),
+	})
+}
+
+// EnableTwoFactor godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm the secret from /auth/2fa/setup with a code, returns the recovery codes once
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/2fa/enable [post]
+func EnableTwoFactor(c *gin.Context) {
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if user.HasTwoFactor() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrTwoFactorAlreadyEnabled.Error()})
+		return
+	}
+	if user.TOTPSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrTwoFactorNotSetUp.Error()})
+		return
+	}
+
+	if err := verifyTOTP(&user, input.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, hashes, err := helpers.NewRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	now := time.Now().UTC()
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("totp_enabled_at", now).Error; err != nil {
+			return err
+		}
+		if err := replaceRecoveryCodes(tx, user.ID, hashes); err != nil {
+			return err
+		}
+		// the session that enrolled just proved the second factor
+		return markSessionVerified(tx, c.GetString("session_id"), now)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionTwoFactorEnable,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+	})
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// VerifyTwoFactor godoc
+// @Summary Verify the second factor for the current session
+// @Description Step-up for sessions that did not pass the second factor at login, e.g. single sign-on
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/2fa/verify [post]
+func VerifyTwoFactor(c *gin.Context) {
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if !user.HasTwoFactor() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrTwoFactorNotEnabled.Error()})
+		return
+	}
+	if err := verifySecondFactor(&user, input.Code, input.RecoveryCode); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := markSessionVerified(db.DB, c.GetString("session_id"), time.Now().UTC()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Two-factor authentication verified",
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Remove the TOTP secret and recovery codes, refused for roles that require a second factor
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body DisableTwoFactorRequest true "Password and current code"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 403 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	var input DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	err := rules.LoadTwoFactorPolicy().CanDisableTwoFactor(&user)
+	switch {
+	case errors.Is(err, rules.ErrTwoFactorMandatory):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if user.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrInvalidCurrentPassword.Error()})
+		return
+	}
+	if err := verifyTOTP(&user, input.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"totp_secret":       nil,
+			"totp_enabled_at":   nil,
+			"totp_last_counter": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.UserRecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionTwoFactorDisable,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+	})
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Two-factor authentication has been disabled",
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes, the old ones stop working
+// @Tags auth
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "Current TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /auth/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var input TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if !user.HasTwoFactor() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrTwoFactorNotEnabled.Error()})
+		return
+	}
+	if err := verifyTOTP(&user, input.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, hashes, err := helpers.NewRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, user.ID, hashes)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recovery codes"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionRecoveryCodesRegen,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+	})
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+func verifySecondFactor(user *models.User, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return useRecoveryCode(user.ID, recoveryCode)
+	}
+	return verifyTOTP(user, code)
+}
+
+// verifyTOTP checks the code and records its time step so it cannot be replayed
+func verifyTOTP(user *models.User, code string) error {
+	if user.TOTPSecret == nil {
+		return rules.ErrTwoFactorNotSetUp
+	}
+	secret, err := helpers.OpenTOTPSecret(*user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	counter, ok := services.VerifyTOTP(secret, code, time.Now().UTC())
+	if !ok || counter <= user.TOTPLastCounter {
+		return rules.ErrInvalidTwoFactorCode
+	}
+
+	// compare-and-swap so two requests racing with the same code cannot both pass
+	result := db.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_counter < ?", user.ID, counter).
+		Update("totp_last_counter", counter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return rules.ErrInvalidTwoFactorCode
+	}
+	user.TOTPLastCounter = counter
+	return nil
+}
+
+func useRecoveryCode(userID int64, code string) error {
+	result := db.DB.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, helpers.HashRecoveryCode(code)).
+		Update("used_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return rules.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID int64, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]models.UserRecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, models.UserRecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
+
+func markSessionVerified(tx *gorm.DB, sessionID string, at time.Time) error {
+	return tx.Model(&models.UserSession{}).Where("uuid = ?", sessionID).Update("two_factor_verified_at", at).Error
+}
@@ -0,0 +1,512 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type CreateUserRequest struct {
+	Email     string             `json:"email" binding:"required" example:"carol@user.com"`
+	Name      string             `json:"name" binding:"required" example:"Carol User"`
+	Role      constants.UserRole `json:"role" binding:"required" example:"user"`
+	ManagerID *int64             `json:"manager_id" example:"1"`
+	Password  string             `json:"password" example:"s3cure-passw0rd"` // optional, leave empty for SSO-only users
+}
+
+type UpdateUserRequest struct {
+	Name      string             `json:"name" binding:"required" example:"Carol User"`
+	Role      constants.UserRole `json:"role" binding:"required" example:"user"`
+	ManagerID *int64             `json:"manager_id" example:"1"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" example:"n3w-passw0rd"` // optional, a temporary password is generated when empty
+}
+
+type ResetPasswordResponse struct {
+	Message           string `json:"message" example:"Password has been reset"`
+	TemporaryPassword string `json:"temporary_password,omitempty" example:"Jx8Vb2kQ0rTz"`
+}
+
+type UsersListResponse struct {
+	Data []models.User  `json:"data"`
+	Meta PaginationMeta `json:"meta"`
+}
+
+// GetUsers godoc
+// @Summary List users
+// @Description Get paginated list of users (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param role query string false "Filter by role"
+// @Param active query bool false "Filter by active or deactivated users"
+// @Param service_account query bool false "Filter by service accounts or people"
+// @Param q query string false "Search by name or email"
+// @Success 200 {object} UsersListResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users [get]
+func GetUsers(c *gin.Context) {
+	var users []models.User
+	var total int64
+
+	page, limit, offset := helpers.GetPagination(c)
+
+	query := db.DB.Model(&models.User{})
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	switch c.Query("service_account") {
+	case "true":
+		query = query.Where("service_account = ?", true)
+	case "false":
+		query = query.Where("service_account = ?", false)
+	}
+	switch c.Query("active") {
+	case "true":
+		query = query.Where("deactivated_at IS NULL")
+	case "false":
+		query = query.Where("deactivated_at IS NOT NULL")
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + strings.ToLower(q) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+
+	// count first
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+		return
+	}
+
+	// fetch paginated data
+	if err := query.
+		Order("name ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, UsersListResponse{
+		Data: users,
+		Meta: PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetUser godoc
+// @Summary Get user
+// @Description Get a user by ID (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Router /manager/users/{id} [get]
+func GetUser(c *gin.Context) {
+	var user models.User
+	if err := db.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// CreateUser godoc
+// @Summary Create user
+// @Description Create a user directly, use invites to let people choose their own password (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateUserRequest true "User payload"
+// @Success 201 {object} models.User
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users [post]
+func CreateUser(c *gin.Context) {
+	var input CreateUserRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email, err := rules.NormalizeEmail(input.Email)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := rules.ValidateRole(input.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := models.User{
+		Email:     email,
+		Name:      strings.TrimSpace(input.Name),
+		Role:      input.Role,
+		ManagerID: input.ManagerID,
+	}
+
+	if input.Password != "" {
+		if err := checkNewPassword(input.Password, email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		user.PasswordHash = string(hash)
+	}
+
+	if err := validateManager(0, input.ManagerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	taken, err := emailTaken(email, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check email"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": rules.ErrEmailAlreadyRegistered.Error()})
+		return
+	}
+
+	if err := db.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserCreate,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		After:      user,
+	})
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser godoc
+// @Summary Update user
+// @Description Update name, role and manager of a user (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body UpdateUserRequest true "User payload"
+// @Success 200 {object} models.User
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users/{id} [put]
+func UpdateUser(c *gin.Context) {
+	var input UpdateUserRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := rules.ValidateRole(input.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateManager(user.ID, input.ManagerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := user
+	user.Name = strings.TrimSpace(input.Name)
+	user.Role = input.Role
+	user.ManagerID = input.ManagerID
+
+	if err := db.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserUpdate,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		Before:     before,
+		After:      user,
+	})
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate user
+// @Description Block the user from signing in, revoke their sessions and flag their pending expenses (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users/{id}/deactivate [post]
+func DeactivateUser(c *gin.Context) {
+	actor, ok := helpers.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.ID == actor.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrCannotDeactivateSelf.Error()})
+		return
+	}
+
+	before := user
+	if err := helpers.DeactivateUser(db.DB, &user, &actor.ID, time.Now().UTC()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserDeactivate,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		Before:     before,
+		After:      user,
+	})
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate user
+// @Description Let a deactivated user sign in again, flagged expenses stay flagged (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users/{id}/reactivate [post]
+func ReactivateUser(c *gin.Context) {
+	var user models.User
+	if err := db.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	before := user
+	if err := helpers.ReactivateUser(db.DB, &user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate user"})
+		return
+	}
+
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserReactivate,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		Before:     before,
+		After:      user,
+	})
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ResetUserPassword godoc
+// @Summary Reset user password
+// @Description Set a new password, or generate a temporary one, and sign the user out everywhere (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body ResetPasswordRequest false "New password"
+// @Success 200 {object} ResetPasswordResponse
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /manager/users/{id}/reset-password [post]
+func ResetUserPassword(c *gin.Context) {
+	var input ResetPasswordRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.ServiceAccount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rules.ErrServiceAccountSignIn.Error()})
+		return
+	}
+
+	response := ResetPasswordResponse{Message: "Password has been reset"}
+
+	password := input.Password
+	if password == "" {
+		temporary, err := services.RandomURLToken(12)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate password"})
+			return
+		}
+		password = temporary
+		response.TemporaryPassword = temporary
+	}
+
+	// generated passwords are random, only chosen ones go through the policy
+	if input.Password != "" {
+		if err := checkNewPassword(password, user.Email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setPassword(tx, &user, string(hash), time.Now().UTC()); err != nil {
+			return err
+		}
+		return helpers.RevokeUserSessions(tx, user.ID, time.Now().UTC())
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultUnlocked)
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserPasswordReset,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+		Metadata:   map[string]interface{}{"temporary_password": response.TemporaryPassword != ""},
+	})
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnlockUser godoc
+// @Summary Unlock user
+// @Description Clear the failed login counter of a user locked out by brute-force protection (manager only)
+// @Tags ManagerUsers
+// @Security CookieAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 404 {object} httputil.HTTPError
+// @Router /manager/users/{id}/unlock [post]
+func UnlockUser(c *gin.Context) {
+	var user models.User
+	if err := db.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	helpers.RecordLoginAttempt(db.DB, c, &user.ID, user.Email, constants.LoginResultUnlocked)
+	helpers.RecordAuditEvent(db.DB, c, helpers.AuditEventInput{
+		Action:     constants.AuditActionUserUnlock,
+		EntityType: constants.AuditEntityUser,
+		EntityID:   user.ID,
+	})
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Failed login attempts have been cleared",
+	})
+}
+
+// validateManager checks the manager exists and that assigning it keeps the reporting line acyclic
+func validateManager(userID int64, managerID *int64) error {
+	if managerID == nil {
+		return nil
+	}
+
+	var manager models.User
+	if err := db.DB.Select("id").First(&manager, *managerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("manager not found")
+		}
+		return err
+	}
+
+	if userID == 0 {
+		return nil
+	}
+
+	chain, err := helpers.GetManagerChain(db.DB, manager.ID)
+	if err != nil {
+		return err
+	}
+	return rules.ValidateManagerAssignment(userID, manager.ID, chain)
+}
+
+func emailTaken(email string, exceptUserID int64) (bool, error) {
+	var count int64
+	if err := db.DB.Model(&models.User{}).Where("LOWER(email) = ? AND id <> ?", email, exceptUserID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -23,72 +23,40 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/expenses": {
+        "/.well-known/jwks.json": {
             "get": {
-                "security": [
-                    {
-                        "CookieAuth": []
-                    }
-                ],
-                "description": "Get paginated list of expenses for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Public keys for validating access tokens, includes the active key and recently retired ones",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Expenses"
-                ],
-                "summary": "Get user's expenses",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by expense status",
-                        "name": "status",
-                        "in": "query"
-                    }
+                    "auth"
                 ],
+                "summary": "JSON Web Key Set",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/httputil.HTTPError"
+                            "$ref": "#/definitions/services.JWKS"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa/disable": {
             "post": {
                 "security": [
                     {
                         "CookieAuth": []
                     }
                 ],
-                "description": "Submit a new expense for reimbursement",
+                "description": "Remove the TOTP secret and recovery codes, refused for roles that require a second factor",
                 "consumes": [
                     "application/json"
                 ],
@@ -96,23 +64,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Expenses"
+                    "auth"
                 ],
-                "summary": "Create a new expense",
+                "summary": "Disable two-factor authentication",
                 "parameters": [
                     {
-                        "description": "Expense payload",
+                        "description": "Password and current code",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/actions.SubmitExpenseInput"
+                            "$ref": "#/definitions/controllers.DisableTwoFactorRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/controllers.MessageResponse"
                         }
@@ -129,6 +97,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -138,14 +112,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/expenses/{id}": {
-            "get": {
+        "/auth/2fa/enable": {
+            "post": {
                 "security": [
                     {
                         "CookieAuth": []
                     }
                 ],
-                "description": "Get detailed information of a single expense",
+                "description": "Confirm the secret from /auth/2fa/setup with a code, returns the recovery codes once",
                 "consumes": [
                     "application/json"
                 ],
@@ -153,72 +127,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Expenses"
+                    "auth"
                 ],
-                "summary": "Get expense by ID",
+                "summary": "Enable two-factor authentication",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Expense ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Code from the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorCodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Expense"
+                            "$ref": "#/definitions/controllers.RecoveryCodesResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
-                    }
-                }
-            }
-        },
-        "/health-check": {
-            "get": {
-                "description": "Check the health status of the application and database connectivity",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Health"
-                ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.HealthCheckResponse"
-                        }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/controllers.HealthCheckResponse"
+                            "$ref": "#/definitions/httputil.HTTPError"
                         }
                     }
                 }
             }
         },
-        "/login": {
+        "/auth/2fa/recovery-codes": {
             "post": {
-                "description": "Authenticate user with email and password, returns JWT token",
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Replace all recovery codes, the old ones stop working",
                 "consumes": [
                     "application/json"
                 ],
@@ -228,23 +186,15 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "User login",
+                "summary": "Regenerate recovery codes",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "input",
+                        "description": "Current TOTP code",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "email": {
-                                    "type": "string"
-                                },
-                                "password": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/controllers.TwoFactorCodeRequest"
                         }
                     }
                 ],
@@ -252,7 +202,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginResponse"
+                            "$ref": "#/definitions/controllers.RecoveryCodesResponse"
                         }
                     },
                     "400": {
@@ -276,14 +226,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/expense-logs": {
-            "get": {
+        "/auth/2fa/setup": {
+            "post": {
                 "security": [
                     {
                         "CookieAuth": []
                     }
                 ],
-                "description": "Get paginated list of audit logs for expenses status changes (manager only)",
+                "description": "Generate a new TOTP secret, it only takes effect once confirmed with /auth/2fa/enable",
                 "consumes": [
                     "application/json"
                 ],
@@ -291,45 +241,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Manager"
-                ],
-                "summary": "Get audit logs",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter by expense ID",
-                        "name": "expense_id",
-                        "in": "query"
-                    }
+                    "auth"
                 ],
+                "summary": "Start two-factor enrolment",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "data": {
-                                    "type": "array",
-                                    "items": {
-                                        "$ref": "#/definitions/models.ExpenseAuditLog"
-                                    }
-                                },
-                                "meta": {
-                                    "$ref": "#/definitions/controllers.PaginationMeta"
-                                }
-                            }
+                            "$ref": "#/definitions/controllers.TwoFactorSetupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
                     "401": {
@@ -347,14 +272,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/expenses": {
-            "get": {
+        "/auth/2fa/verify": {
+            "post": {
                 "security": [
                     {
                         "CookieAuth": []
                     }
                 ],
-                "description": "Get paginated list of all expenses (manager only)",
+                "description": "Step-up for sessions that did not pass the second factor at login, e.g. single sign-on",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,34 +287,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ManagerExpenses"
+                    "auth"
                 ],
-                "summary": "Get all expenses (manager only)",
+                "summary": "Verify the second factor for the current session",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by expense status",
-                        "name": "status",
-                        "in": "query"
+                        "description": "TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorCodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ExpensesListResponse"
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
                     "401": {
@@ -407,14 +329,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/manager/expenses/{id}/approve": {
-            "put": {
-                "security": [
-                    {
-                        "CookieAuth": []
-                    }
-                ],
-                "description": "Approve a pending expense (manager only)",
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Email a single-use reset link. The response is the same, and as fast, whether or not the email exists",
                 "consumes": [
                     "application/json"
                 ],
@@ -422,30 +339,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Manager"
+                    "auth"
                 ],
-                "summary": "Approve an expense",
+                "summary": "Forgot password",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Expense ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Approval payload",
+                        "description": "Account email",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.StatusExpenseRequest"
+                            "$ref": "#/definitions/controllers.ForgotPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "$ref": "#/definitions/controllers.MessageResponse"
                         }
@@ -455,36 +365,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/httputil.HTTPError"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/httputil.HTTPError"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/httputil.HTTPError"
-                        }
                     }
                 }
             }
         },
-        "/manager/expenses/{id}/reject": {
-            "put": {
-                "security": [
-                    {
-                        "CookieAuth": []
-                    }
-                ],
-                "description": "Reject a pending expense (manager only)",
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Exchange the token returned by /auth/login and a TOTP or recovery code for a session",
                 "consumes": [
                     "application/json"
                 ],
@@ -492,24 +379,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Manager"
+                    "auth"
                 ],
-                "summary": "Reject an expense",
+                "summary": "Complete login with a second factor",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Expense ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Rejection payload",
+                        "description": "Login token and code",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.StatusExpenseRequest"
+                            "$ref": "#/definitions/controllers.LoginTwoFactorRequest"
                         }
                     }
                 ],
@@ -517,7 +397,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.MessageResponse"
+                            "$ref": "#/definitions/controllers.LoginResponse"
                         }
                     },
                     "400": {
@@ -532,8 +412,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -546,306 +426,7174 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "actions.SubmitExpenseInput": {
-            "type": "object",
-            "properties": {
-                "amount_idr": {
-                    "type": "integer"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "receipt_url": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revoke the current session and clear auth cookies, a session named by the cookies needs the X-CSRF-Token header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Refresh token when not sent as cookie",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
                 }
             }
         },
-        "constants.ApprovalStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "approved",
-                "rejected"
-            ],
-            "x-enum-varnames": [
-                "ApprovalStatusPending",
-                "ApprovalStatusApproved",
-                "ApprovalStatusRejected"
-            ]
-        },
-        "constants.ExpenseStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "approved",
-                "rejected",
-                "completed"
-            ],
-            "x-enum-varnames": [
-                "ExpenseStatusPending",
-                "ExpenseStatusApproved",
-                "ExpenseStatusRejected",
-                "ExpenseStatusCompleted"
-            ]
-        },
-        "constants.UserRole": {
-            "type": "string",
-            "enum": [
-                "user",
-                "manager"
-            ],
-            "x-enum-varnames": [
-                "UserRoleUser",
-                "UserRoleManager"
-            ]
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Exchange the authorization code, provision the user on first login and start a session",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish single sign-on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State issued by /auth/oidc/login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
         },
-        "controllers.ExpensesListResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Expense"
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirect to the identity provider using the authorization code flow with PKCE",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start single sign-on",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
                     }
-                },
-                "meta": {
-                    "$ref": "#/definitions/controllers.PaginationMeta"
                 }
             }
         },
-        "controllers.HealthCheckResponse": {
-            "type": "object",
-            "properties": {
-                "database": {
-                    "type": "string",
-                    "example": "up"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "ok"
-                },
-                "time": {
-                    "type": "string",
-                    "example": "2023-10-05T14:48:00Z"
+        "/auth/password": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Change the authenticated user's password, other sessions are signed out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
                 }
             }
         },
-        "controllers.LoginResponse": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "john@example.com"
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token, the refresh and CSRF tokens are rotated on every call",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token when not sent as cookie",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Create the invited account with the chosen password and start a session, each invite works once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign up with an invite",
+                "parameters": [
+                    {
+                        "description": "Signup payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password with the token from the reset email and sign out every session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password with token",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordWithTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get paginated list of expenses for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Get user's expenses",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number, ignored when cursor is given",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset paging, empty for the first page then meta.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by expense status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ExpensesListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Submit a new expense for reimbursement",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Create a new expense",
+                "parameters": [
+                    {
+                        "description": "Expense payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/actions.SubmitExpenseInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/expenses/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get detailed information of a single expense",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Get expense by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Expense"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/health-check": {
+            "get": {
+                "description": "Check the health status of the application and database connectivity",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HealthCheckResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.HealthCheckResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate user with email and password, returns a short-lived JWT and sets a rotating refresh token cookie.\nUsers with two-factor authentication get a 202 with a token for /auth/login/2fa instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": {
+                                    "type": "string"
+                                },
+                                "password": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TwoFactorChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/accounting/exports": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Earlier exports, newest first (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounting"
+                ],
+                "summary": "List accounting exports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AccountingExportsListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Record a batch of the completed expenses paid in the period that no earlier export contains. Download it with /manager/accounting/exports/{id}/download (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounting"
+                ],
+                "summary": "Export paid expenses to accounting",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Paid at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Paid before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Download format, csv by default",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateAccountingExportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountingExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/accounting/exports/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "The journal of an export, one entry per expense debiting its category's GL account and crediting the payment account. Generic CSV, QuickBooks IIF or a Xero manual journal CSV, dated in Asia/Jakarta (manager only)",
+                "produces": [
+                    "text/csv",
+                    "text/plain"
+                ],
+                "tags": [
+                    "Accounting"
+                ],
+                "summary": "Download an accounting export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Accounting export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv, iif or xero, the export's format by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Journal file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/accounting/gl-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "The general ledger account every expense category is booked to in accounting exports, unmapped categories show their default (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounting"
+                ],
+                "summary": "List GL account mappings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.GLAccountMapping"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/accounting/gl-accounts/{category}": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Book the expenses of a category to this account in later downloads. QuickBooks matches accounts by name, Xero by code (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounting"
+                ],
+                "summary": "Map a category to a GL account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Expense category, e.g. travel",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "GL account",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateGLAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GLAccountMapping"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get paginated audit events, newest first: logins, user, approval, policy and delegation changes, credential changes and payment retries (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Get audit events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number, ignored when cursor is given",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset paging, empty for the first page then meta.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by action, a trailing dot matches a group, e.g. user.",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "session",
+                            "invite",
+                            "api_key",
+                            "expense",
+                            "approval",
+                            "sla_policy",
+                            "delegation"
+                        ],
+                        "type": "string",
+                        "description": "Filter by entity type",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by entity ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.AuditEvent"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/controllers.PaginationMeta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Spend analytics of expenses submitted in the period, by status, user, category and month, with approval turnaround, auto-approval and payment failure rates, plus approval SLA breach metrics (manager only). Analytics are cached per period for ANALYTICS_CACHE_TTL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Manager dashboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Submitted at or after (RFC 3339 or YYYY-MM-DD), defaults to the start of the month 11 months ago",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ManagerDashboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/delegations": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "List delegations given by or to the authenticated manager (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "List delegations",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only delegations active right now",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.ApprovalDelegation"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Let another manager act on your approvals during a date range (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Delegate approvals",
+                "parameters": [
+                    {
+                        "description": "Delegation payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateDelegationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ApprovalDelegation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/delegations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Revoke a delegation you created (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Revoke a delegation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delegation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expense-logs": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get paginated list of audit logs for expenses status changes with the actor's name and an expense summary (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Get audit logs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number, ignored when cursor is given",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset paging, empty for the first page then meta.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by expense ID",
+                        "name": "expense_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status before the change",
+                        "name": "from_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status after the change",
+                        "name": "to_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search the reason text",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "expense_id",
+                            "actor_name",
+                            "from_status",
+                            "to_status",
+                            "amount"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/controllers.ExpenseAuditLogRow"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/controllers.PaginationMeta"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expense-logs/export": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Download every audit log row matching the filters of the listing, in the same order (manager only)",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Export audit logs as CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by expense ID",
+                        "name": "expense_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status before the change",
+                        "name": "from_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status after the change",
+                        "name": "to_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search the reason text",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "expense_id",
+                            "actor_name",
+                            "from_status",
+                            "to_status",
+                            "amount"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expense-logs/verify": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Walk the hash chain of expense audit logs and report the first entry that was edited, removed or inserted, an anchor it no longer matches, or an anchor that is missing or not signed by the anchor key (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Verify the audit log hash chain",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/helpers.AuditChainReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expenses": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Search all expenses (manager only). Filters combine with AND, status and category take comma separated lists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerExpenses"
+                ],
+                "summary": "Get all expenses (manager only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number, ignored when cursor is given",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Keyset paging, empty for the first page then meta.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by expense status, e.g. pending,approved",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only flagged expenses, e.g. submitter deactivated",
+                        "name": "flagged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over the description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount in IDR, inclusive",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount in IDR, inclusive",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted at or after, RFC 3339 or YYYY-MM-DD",
+                        "name": "submitted_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted before, a date includes that whole day",
+                        "name": "submitted_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Submitted by this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category, e.g. travel,meals",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Approved or rejected by this user",
+                        "name": "approver_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Waiting on this approver",
+                        "name": "assignee_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "updated_at",
+                        "description": "updated_at, submitted_at, created_at, amount, status or category",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ExpensesListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expenses/bulk-decision": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Apply the same decision to several expenses, each one goes through the regular approval rules (manager only, browser session, not API keys)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Approve or reject expenses in bulk",
+                "parameters": [
+                    {
+                        "description": "Bulk decision payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BulkDecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BulkDecisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expenses/export": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Download every expense matching the filters and sort of the manager listing. Amounts are IDR, timestamps Asia/Jakarta (manager only)",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "ManagerExpenses"
+                ],
+                "summary": "Export expenses as CSV or XLSX",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "csv or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by expense status, e.g. pending,approved",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only flagged expenses, e.g. submitter deactivated",
+                        "name": "flagged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over the description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount in IDR, inclusive",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount in IDR, inclusive",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted at or after, RFC 3339 or YYYY-MM-DD",
+                        "name": "submitted_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted before, a date includes that whole day",
+                        "name": "submitted_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Submitted by this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category, e.g. travel,meals",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Approved or rejected by this user",
+                        "name": "approver_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Waiting on this approver",
+                        "name": "assignee_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "updated_at",
+                        "description": "updated_at, submitted_at, created_at, amount, status or category",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV or XLSX file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expenses/{id}/approve": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Approve a pending expense (manager only, browser session, not API keys)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Approve an expense",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Approval payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.StatusExpenseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expenses/{id}/reject": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Reject a pending expense (manager only, browser session, not API keys)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Reject an expense",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rejection payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.StatusExpenseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/expenses/{id}/voucher": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "PDF voucher of an approved or paid expense, with the approval decision, payment reference and status history. Amounts are IDR, times Asia/Jakarta (manager only).",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "ManagerExpenses"
+                ],
+                "summary": "Download an expense voucher",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/invites": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "List signup invites (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "List invites",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only invites that can still be used",
+                        "name": "pending",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.UserInvite"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Create a single-use signup invite, earlier pending invites for the same email are revoked (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Invite a user",
+                "parameters": [
+                    {
+                        "description": "Invite payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InviteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/invites/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Revoke an invite that has not been used yet (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Revoke an invite",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/ledger/balances": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "What was expensed for, paid to and is still owed to each employee, from the entries posted in the period, largest amount owed first (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ledger"
+                ],
+                "summary": "Ledger balances per employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only this employee",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Posted at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Posted before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/helpers.EmployeeBalance"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/ledger/entries": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Ledger entries with their lines, newest first (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ledger"
+                ],
+                "summary": "List journal entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entries of this expense",
+                        "name": "expense_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries with a line for this employee",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "approval or settlement",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Posted at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Posted before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.JournalEntriesListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/ledger/trial-balance": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Debits, credits and balance of every ledger account over the entries posted in the period, all time by default. Balances are on the account's normal side, total debits always equal total credits (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ledger"
+                ],
+                "summary": "Ledger trial balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Posted at or after (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Posted before (RFC 3339), or on or before (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/helpers.TrialBalance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/login-attempts": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get paginated login successes and failures, newest first (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Get login audit trail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by user ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by client IP address",
+                        "name": "ip_address",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "invalid_credentials",
+                            "invalid_second_factor",
+                            "throttled",
+                            "deactivated",
+                            "unlocked"
+                        ],
+                        "type": "string",
+                        "description": "Filter by result",
+                        "name": "result",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.LoginAttempt"
+                                    }
+                                },
+                                "meta": {
+                                    "$ref": "#/definitions/controllers.PaginationMeta"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/service-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "List service accounts, deactivate them through /manager/users/{id}/deactivate (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerServiceAccounts"
+                ],
+                "summary": "List service accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.User"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Create a user for a machine client, it cannot sign in and authenticates with API keys (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerServiceAccounts"
+                ],
+                "summary": "Create a service account",
+                "parameters": [
+                    {
+                        "description": "Service account payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/service-accounts/{id}/keys": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "List the API keys of a service account with their last use (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerServiceAccounts"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.APIKey"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Issue a scoped, expiring API key for a service account, sent as \"Authorization: Bearer \u003ckey\u003e\" (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerServiceAccounts"
+                ],
+                "summary": "Issue an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/service-accounts/{id}/keys/{key_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Revoke an API key, requests using it are refused right away (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerServiceAccounts"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "key_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/sla-policies": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "List approval SLA policies (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "List SLA policies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/models.SLAPolicy"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/sla-policies/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Change reminder and escalation durations, applies to approvals created afterwards (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Manager"
+                ],
+                "summary": "Update an SLA policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "SLA policy ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SLA durations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateSLAPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SLAPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get paginated list of users (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by active or deactivated users",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by service accounts or people",
+                        "name": "service_account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by name or email",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UsersListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Create a user directly, use invites to let people choose their own password (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "User payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Get a user by ID (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Get user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Update name, role and manager of a user (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Block the user from signing in, revoke their sessions and flag their pending expenses (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Deactivate user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users/{id}/reactivate": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Let a deactivated user sign in again, flagged expenses stay flagged (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Reactivate user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users/{id}/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Set a new password, or generate a temporary one, and sign the user out everywhere (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New password",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users/{id}/statement": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "PDF listing the user's expenses paid in the period with their payment references and the total paid. Amounts are IDR, times Asia/Jakarta (manager only).",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Download a user's reimbursement statement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Paid at or after (RFC 3339 or YYYY-MM-DD), defaults to the start of the month of to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Paid before (RFC 3339), or on or before (YYYY-MM-DD), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/manager/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Clear the failed login counter of a user locked out by brute-force protection (manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ManagerUsers"
+                ],
+                "summary": "Unlock user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Groups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "List groups (SCIM)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCIM filter, e.g. displayName eq \\",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1-based index of the first result",
+                        "name": "startIndex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a group, members of the groups listed in SCIM_MANAGER_GROUPS get the manager role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Provision group (SCIM)",
+                "parameters": [
+                    {
+                        "description": "SCIM group",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Groups/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Get group (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMGroup"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Replace group (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SCIM group",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the group, its members keep their accounts",
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Delete group (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply PatchOp operations on displayName and members, e.g. {\"op\":\"remove\",\"path\":\"members[value eq \\\"3\\\"]\"}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Update group (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SCIM PatchOp",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMPatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/ServiceProviderConfig": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Capabilities advertised to the provisioning client",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "SCIM service provider configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List users for the provisioning client, supports eq/ne/co/sw/ew/pr filters joined by and",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "List users (SCIM)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCIM filter, e.g. userName eq \\",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1-based index of the first result",
+                        "name": "startIndex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a user, provisioned users sign in through single sign-on and get the user role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Provision user (SCIM)",
+                "parameters": [
+                    {
+                        "description": "SCIM user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Get user (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMUser"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Replace user (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SCIM user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deactivate the user, history is kept. Sessions are revoked and pending expenses flagged",
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Deprovision user (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply PatchOp operations, e.g. {\"op\":\"replace\",\"path\":\"active\",\"value\":false} to deactivate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SCIM"
+                ],
+                "summary": "Update user (SCIM)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SCIM PatchOp",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMPatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SCIMError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "List active sessions of the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/controllers.SessionResponse"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{uuid}": {
+            "delete": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Revoke one of the authenticated user's sessions, its access token stops working immediately",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Totals of the authenticated user's expenses this month, quarter and year (UTC): reimbursed by the time they were paid (processed_at), awaiting payment, pending and rejected by the time they were submitted. Also the average time to payment and the largest expense against the per-expense limits",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Personal spending summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.SpendingSummary"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/expenses/export": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "Download the authenticated user's expenses, with the filters and sort of the manager listing. Amounts are IDR, timestamps Asia/Jakarta",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Export own expenses as CSV or XLSX",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "csv or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by expense status, e.g. pending,approved",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search over the description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount in IDR, inclusive",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount in IDR, inclusive",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted at or after, RFC 3339 or YYYY-MM-DD",
+                        "name": "submitted_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Submitted before, a date includes that whole day",
+                        "name": "submitted_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category, e.g. travel,meals",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "updated_at",
+                        "description": "updated_at, submitted_at, created_at, amount, status or category",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV or XLSX file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/expenses/{id}/voucher": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "PDF voucher of one of the authenticated user's approved or paid expenses, with the approval decision, payment reference and status history. Amounts are IDR, times Asia/Jakarta.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Download a voucher of an own expense",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Expense ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/statement": {
+            "get": {
+                "security": [
+                    {
+                        "CookieAuth": []
+                    }
+                ],
+                "description": "PDF listing the authenticated user's expenses paid in the period with their payment references and the total paid. Amounts are IDR, times Asia/Jakarta.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Expenses"
+                ],
+                "summary": "Download own reimbursement statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Paid at or after (RFC 3339 or YYYY-MM-DD), defaults to the start of the month of to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Paid before (RFC 3339), or on or before (YYYY-MM-DD), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "actions.SubmitExpenseInput": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "category": {
+                    "description": "defaults to other",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.ExpenseCategory"
+                        }
+                    ],
+                    "example": "travel"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "receipt_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "constants.APIScope": {
+            "type": "string",
+            "enum": [
+                "expenses:read",
+                "expenses:write",
+                "users:read",
+                "users:write",
+                "settings:read",
+                "settings:write",
+                "audit:read",
+                "reports:read",
+                "accounting:write"
+            ],
+            "x-enum-comments": {
+                "APIScopeAccountingWrite": "journal exports, which mark expenses as exported",
+                "APIScopeExpensesWrite": "submit, approvals and rejections need a browser session",
+                "APIScopeReportsRead": "dashboards, ledger and statements",
+                "APIScopeSettingsRead": "SLA policies, delegations and GL accounts"
+            },
+            "x-enum-descriptions": [
+                "",
+                "submit, approvals and rejections need a browser session",
+                "",
+                "",
+                "SLA policies, delegations and GL accounts",
+                "",
+                "",
+                "dashboards, ledger and statements",
+                "journal exports, which mark expenses as exported"
+            ],
+            "x-enum-varnames": [
+                "APIScopeExpensesRead",
+                "APIScopeExpensesWrite",
+                "APIScopeUsersRead",
+                "APIScopeUsersWrite",
+                "APIScopeSettingsRead",
+                "APIScopeSettingsWrite",
+                "APIScopeAuditRead",
+                "APIScopeReportsRead",
+                "APIScopeAccountingWrite"
+            ]
+        },
+        "constants.AccountingFormat": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "iif",
+                "xero"
+            ],
+            "x-enum-comments": {
+                "AccountingFormatCSV": "generic journal, one row per debit or credit",
+                "AccountingFormatIIF": "QuickBooks Desktop general journal",
+                "AccountingFormatXero": "Xero manual journal import"
+            },
+            "x-enum-descriptions": [
+                "generic journal, one row per debit or credit",
+                "QuickBooks Desktop general journal",
+                "Xero manual journal import"
+            ],
+            "x-enum-varnames": [
+                "AccountingFormatCSV",
+                "AccountingFormatIIF",
+                "AccountingFormatXero"
+            ]
+        },
+        "constants.ApprovalStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "ApprovalStatusPending",
+                "ApprovalStatusApproved",
+                "ApprovalStatusRejected"
+            ]
+        },
+        "constants.AuditAction": {
+            "type": "string",
+            "enum": [
+                "auth.login",
+                "auth.password_change",
+                "auth.password_reset",
+                "auth.two_factor_enable",
+                "auth.two_factor_disable",
+                "auth.recovery_codes_regenerate",
+                "auth.session_revoke",
+                "user.create",
+                "user.update",
+                "user.deactivate",
+                "user.reactivate",
+                "user.password_reset",
+                "user.unlock",
+                "invite.create",
+                "invite.revoke",
+                "api_key.create",
+                "api_key.revoke",
+                "expense.create",
+                "approval.decide",
+                "sla_policy.update",
+                "delegation.create",
+                "delegation.revoke",
+                "payment.retry",
+                "payment.fail",
+                "payment.complete",
+                "gl_account.update",
+                "accounting.export"
+            ],
+            "x-enum-comments": {
+                "AuditActionLogin": "result in metadata, failures included",
+                "AuditActionPasswordReset": "through the emailed link",
+                "AuditActionUserPasswordReset": "by a manager"
+            },
+            "x-enum-descriptions": [
+                "result in metadata, failures included",
+                "",
+                "through the emailed link",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "by a manager",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                ""
+            ],
+            "x-enum-varnames": [
+                "AuditActionLogin",
+                "AuditActionPasswordChange",
+                "AuditActionPasswordReset",
+                "AuditActionTwoFactorEnable",
+                "AuditActionTwoFactorDisable",
+                "AuditActionRecoveryCodesRegen",
+                "AuditActionSessionRevoke",
+                "AuditActionUserCreate",
+                "AuditActionUserUpdate",
+                "AuditActionUserDeactivate",
+                "AuditActionUserReactivate",
+                "AuditActionUserPasswordReset",
+                "AuditActionUserUnlock",
+                "AuditActionInviteCreate",
+                "AuditActionInviteRevoke",
+                "AuditActionAPIKeyCreate",
+                "AuditActionAPIKeyRevoke",
+                "AuditActionExpenseCreate",
+                "AuditActionApprovalDecide",
+                "AuditActionSLAPolicyUpdate",
+                "AuditActionDelegationCreate",
+                "AuditActionDelegationRevoke",
+                "AuditActionPaymentRetry",
+                "AuditActionPaymentFail",
+                "AuditActionPaymentComplete",
+                "AuditActionGLAccountUpdate",
+                "AuditActionAccountingExport"
+            ]
+        },
+        "constants.AuditEntityType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "session",
+                "invite",
+                "api_key",
+                "expense",
+                "approval",
+                "sla_policy",
+                "delegation",
+                "gl_account",
+                "accounting_export"
+            ],
+            "x-enum-varnames": [
+                "AuditEntityUser",
+                "AuditEntitySession",
+                "AuditEntityInvite",
+                "AuditEntityAPIKey",
+                "AuditEntityExpense",
+                "AuditEntityApproval",
+                "AuditEntitySLAPolicy",
+                "AuditEntityDelegation",
+                "AuditEntityGLAccount",
+                "AuditEntityAccounting"
+            ]
+        },
+        "constants.ExpenseCategory": {
+            "type": "string",
+            "enum": [
+                "travel",
+                "meals",
+                "lodging",
+                "transport",
+                "supplies",
+                "other"
+            ],
+            "x-enum-varnames": [
+                "ExpenseCategoryTravel",
+                "ExpenseCategoryMeals",
+                "ExpenseCategoryLodging",
+                "ExpenseCategoryTransport",
+                "ExpenseCategorySupplies",
+                "ExpenseCategoryOther"
+            ]
+        },
+        "constants.ExpenseStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "completed"
+            ],
+            "x-enum-varnames": [
+                "ExpenseStatusPending",
+                "ExpenseStatusApproved",
+                "ExpenseStatusRejected",
+                "ExpenseStatusCompleted"
+            ]
+        },
+        "constants.JournalEntryKind": {
+            "type": "string",
+            "enum": [
+                "approval",
+                "settlement"
+            ],
+            "x-enum-comments": {
+                "JournalEntryKindApproval": "the company owes the employee",
+                "JournalEntryKindSettlement": "the employee was paid"
+            },
+            "x-enum-descriptions": [
+                "the company owes the employee",
+                "the employee was paid"
+            ],
+            "x-enum-varnames": [
+                "JournalEntryKindApproval",
+                "JournalEntryKindSettlement"
+            ]
+        },
+        "constants.LedgerAccount": {
+            "type": "string",
+            "enum": [
+                "1000",
+                "2100",
+                "6100"
+            ],
+            "x-enum-comments": {
+                "LedgerAccountEmployeePayable": "kept per employee through the line's user"
+            },
+            "x-enum-descriptions": [
+                "",
+                "kept per employee through the line's user",
+                ""
+            ],
+            "x-enum-varnames": [
+                "LedgerAccountCash",
+                "LedgerAccountEmployeePayable",
+                "LedgerAccountReimbursedExpense"
+            ]
+        },
+        "constants.LedgerAccountType": {
+            "type": "string",
+            "enum": [
+                "asset",
+                "liability",
+                "expense"
+            ],
+            "x-enum-varnames": [
+                "LedgerAccountTypeAsset",
+                "LedgerAccountTypeLiability",
+                "LedgerAccountTypeExpense"
+            ]
+        },
+        "constants.LoginResult": {
+            "type": "string",
+            "enum": [
+                "success",
+                "invalid_credentials",
+                "invalid_second_factor",
+                "throttled",
+                "deactivated",
+                "unlocked"
+            ],
+            "x-enum-comments": {
+                "LoginResultInvalidCredentials": "unknown email or wrong password, counted",
+                "LoginResultInvalidSecondFactor": "wrong TOTP or recovery code, counted",
+                "LoginResultThrottled": "refused before checking the password",
+                "LoginResultUnlocked": "counters reset by a manager or a password reset"
+            },
+            "x-enum-descriptions": [
+                "",
+                "unknown email or wrong password, counted",
+                "wrong TOTP or recovery code, counted",
+                "refused before checking the password",
+                "",
+                "counters reset by a manager or a password reset"
+            ],
+            "x-enum-varnames": [
+                "LoginResultSuccess",
+                "LoginResultInvalidCredentials",
+                "LoginResultInvalidSecondFactor",
+                "LoginResultThrottled",
+                "LoginResultDeactivated",
+                "LoginResultUnlocked"
+            ]
+        },
+        "constants.UserRole": {
+            "type": "string",
+            "enum": [
+                "user",
+                "manager"
+            ],
+            "x-enum-varnames": [
+                "UserRoleUser",
+                "UserRoleManager"
+            ]
+        },
+        "controllers.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/models.APIKey"
+                },
+                "key": {
+                    "description": "only returned once",
+                    "type": "string",
+                    "example": "etk_q9Xc..."
+                }
+            }
+        },
+        "controllers.AccountingExportsListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AccountingExport"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/controllers.PaginationMeta"
+                }
+            }
+        },
+        "controllers.BulkDecisionRequest": {
+            "type": "object",
+            "required": [
+                "decision",
+                "expense_ids"
+            ],
+            "properties": {
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "approve",
+                        "reject"
+                    ],
+                    "example": "approve"
+                },
+                "expense_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Approved in bulk"
+                },
+                "on_behalf_of_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.BulkDecisionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.BulkDecisionResult"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "controllers.BulkDecisionResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "expense is not pending for approval"
+                },
+                "expense_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.ExpenseStatus"
+                        }
+                    ],
+                    "example": "approved"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "controllers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "old-Passw0rd"
+                },
+                "new_password": {
+                    "type": "string",
+                    "example": "n3w-Passw0rd"
+                }
+            }
+        },
+        "controllers.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "defaults to API_KEY_TTL from now",
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly export"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/constants.APIScope"
+                    },
+                    "example": [
+                        "expenses:read",
+                        "reports:read"
+                    ]
+                }
+            }
+        },
+        "controllers.CreateAccountingExportRequest": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.AccountingFormat"
+                        }
+                    ],
+                    "example": "xero"
+                }
+            }
+        },
+        "controllers.CreateDelegationRequest": {
+            "type": "object",
+            "required": [
+                "delegate_id",
+                "ends_at",
+                "starts_at"
+            ],
+            "properties": {
+                "delegate_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "ends_at": {
+                    "type": "string",
+                    "example": "2026-01-12T00:00:00Z"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Annual leave"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2026-01-05T00:00:00Z"
+                }
+            }
+        },
+        "controllers.CreateInviteRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "dave@user.com"
+                },
+                "manager_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "description": "defaults to user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.UserRole"
+                        }
+                    ],
+                    "example": "user"
+                }
+            }
+        },
+        "controllers.CreateServiceAccountRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "manager_id": {
+                    "description": "approver of expenses it submits",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Accounting integration"
+                },
+                "role": {
+                    "description": "defaults to user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.UserRole"
+                        }
+                    ],
+                    "example": "manager"
+                }
+            }
+        },
+        "controllers.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "carol@user.com"
+                },
+                "manager_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Carol User"
+                },
+                "password": {
+                    "description": "optional, leave empty for SSO-only users",
+                    "type": "string",
+                    "example": "s3cure-passw0rd"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.UserRole"
+                        }
+                    ],
+                    "example": "user"
+                }
+            }
+        },
+        "controllers.DisableTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "Expense-Tracker-7"
+                }
+            }
+        },
+        "controllers.ExpenseAuditLogRow": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_name": {
+                    "description": "nil for background workers",
+                    "type": "string",
+                    "example": "Alice Manager"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expense": {
+                    "$ref": "#/definitions/controllers.ExpenseSummary"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "from_status": {
+                    "$ref": "#/definitions/constants.ExpenseStatus"
+                },
+                "hash": {
+                    "description": "sha256 of the entry and PrevHash",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "on_behalf_of_id": {
+                    "type": "integer"
+                },
+                "on_behalf_of_name": {
+                    "type": "string",
+                    "example": "Carol Manager"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "position in the hash chain, nil for entries written before it",
+                    "type": "integer"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/constants.ExpenseStatus"
+                }
+            }
+        },
+        "controllers.ExpenseSummary": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer",
+                    "example": 1500000
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Client dinner"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.ExpenseStatus"
+                        }
+                    ],
+                    "example": "approved"
+                },
+                "submitter_name": {
+                    "type": "string",
+                    "example": "Bob User"
+                },
+                "uuid": {
+                    "type": "string",
+                    "example": "4f9d3c2e-8a1b-4c6d-9e0f-1a2b3c4d5e6f"
+                }
+            }
+        },
+        "controllers.ExpensesListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Expense"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/controllers.PaginationMeta"
+                }
+            }
+        },
+        "controllers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "bob@user.com"
+                }
+            }
+        },
+        "controllers.HealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "database": {
+                    "type": "string",
+                    "example": "up"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2023-10-05T14:48:00Z"
+                }
+            }
+        },
+        "controllers.InviteResponse": {
+            "type": "object",
+            "properties": {
+                "invite": {
+                    "$ref": "#/definitions/models.UserInvite"
+                },
+                "signup_url": {
+                    "type": "string",
+                    "example": "http://localhost:3000/signup?token=q9Xc..."
+                },
+                "token": {
+                    "description": "only returned once",
+                    "type": "string",
+                    "example": "q9Xc..."
+                }
+            }
+        },
+        "controllers.JournalEntriesListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JournalEntry"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/controllers.PaginationMeta"
+                }
+            }
+        },
+        "controllers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.UserRole"
+                        }
+                    ],
+                    "example": "constants.UserRoleUser"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6Ikp"
+                },
+                "two_factor_setup_required": {
+                    "description": "the role requires a second factor that is not enrolled yet, approvals stay blocked until it is",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "controllers.LoginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJFZERTQSIsImtpZCI6..."
+                },
+                "recovery_code": {
+                    "description": "when the authenticator is lost",
+                    "type": "string",
+                    "example": "k7m2q-x9d4a"
+                }
+            }
+        },
+        "controllers.ManagerDashboardResponse": {
+            "type": "object",
+            "properties": {
+                "analytics": {
+                    "$ref": "#/definitions/services.SpendAnalytics"
+                },
+                "sla": {
+                    "$ref": "#/definitions/controllers.SLAMetrics"
+                }
+            }
+        },
+        "controllers.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "operation successful"
+                }
+            }
+        },
+        "controllers.PaginationMeta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "next_cursor": {
+                    "description": "nil on the last page",
+                    "type": "string",
+                    "example": "eyJzIjoidXBkYXRlZF9hdCJ9"
+                },
+                "page": {
+                    "description": "left out when paging by cursor",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "controllers.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "description": "only returned once",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k7m2q-x9d4a",
+                        "p3n8r-w2c6e"
+                    ]
+                }
+            }
+        },
+        "controllers.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "description": "optional, the refresh_token cookie is used by browsers",
+                    "type": "string",
+                    "example": "k3J9..."
+                }
+            }
+        },
+        "controllers.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "password",
+                "token"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Dave User"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "s3cure-passw0rd"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q9Xc..."
+                }
+            }
+        },
+        "controllers.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "optional, a temporary password is generated when empty",
+                    "type": "string",
+                    "example": "n3w-passw0rd"
+                }
+            }
+        },
+        "controllers.ResetPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Password has been reset"
+                },
+                "temporary_password": {
+                    "type": "string",
+                    "example": "Jx8Vb2kQ0rTz"
+                }
+            }
+        },
+        "controllers.ResetPasswordWithTokenRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "n3w-Passw0rd"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q9Xc..."
+                }
+            }
+        },
+        "controllers.SCIMEmail": {
+            "type": "object",
+            "properties": {
+                "primary": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "type": "string",
+                    "example": "work"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "bob@user.com"
+                }
+            }
+        },
+        "controllers.SCIMError": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "unsupported attribute title"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scimType": {
+                    "type": "string",
+                    "example": "invalidFilter"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "400"
+                }
+            }
+        },
+        "controllers.SCIMGroup": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string",
+                    "example": "expense-managers"
+                },
+                "externalId": {
+                    "type": "string",
+                    "example": "00g1abcd"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.SCIMMemberRef"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/controllers.SCIMMeta"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.SCIMListResponse": {
+            "type": "object",
+            "properties": {
+                "Resources": {},
+                "itemsPerPage": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "startIndex": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "totalResults": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.SCIMMemberRef": {
+            "type": "object",
+            "properties": {
+                "$ref": {
+                    "type": "string"
+                },
+                "display": {
+                    "type": "string",
+                    "example": "Bob User"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "3"
+                }
+            }
+        },
+        "controllers.SCIMMeta": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "lastModified": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "http://localhost:8080/api/scim/v2/Users/3"
+                },
+                "resourceType": {
+                    "type": "string",
+                    "example": "User"
+                }
+            }
+        },
+        "controllers.SCIMName": {
+            "type": "object",
+            "properties": {
+                "familyName": {
+                    "type": "string",
+                    "example": "User"
+                },
+                "formatted": {
+                    "type": "string",
+                    "example": "Bob User"
+                },
+                "givenName": {
+                    "type": "string",
+                    "example": "Bob"
+                }
+            }
+        },
+        "controllers.SCIMPatchOperation": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string",
+                    "example": "replace"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "value": {
+                    "type": "object"
+                }
+            }
+        },
+        "controllers.SCIMPatchRequest": {
+            "type": "object",
+            "properties": {
+                "Operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.SCIMPatchOperation"
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.SCIMUser": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "displayName": {
+                    "type": "string",
+                    "example": "Bob User"
+                },
+                "emails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.SCIMEmail"
+                    }
+                },
+                "externalId": {
+                    "type": "string",
+                    "example": "00u1abcd"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.SCIMMemberRef"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3"
+                },
+                "meta": {
+                    "$ref": "#/definitions/controllers.SCIMMeta"
+                },
+                "name": {
+                    "$ref": "#/definitions/controllers.SCIMName"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userName": {
+                    "type": "string",
+                    "example": "bob@user.com"
+                }
+            }
+        },
+        "controllers.SLAMetrics": {
+            "type": "object",
+            "properties": {
+                "breach_rate": {
+                    "type": "number",
+                    "example": 0.1
+                },
+                "breached_in_period": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "breached_pending": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "decided_in_period": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "escalated_pending": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "oldest_pending_days": {
+                    "type": "number",
+                    "example": 2.5
+                },
+                "pending_total": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "period_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "reminder_overdue": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "controllers.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "two_factor_verified_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "uuid": {
+                    "description": "carried as the sid claim of access tokens",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.StatusExpenseRequest": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "type": "string",
+                    "example": "Approved"
+                },
+                "on_behalf_of_id": {
+                    "description": "optional, manager who delegated to you",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "controllers.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6Ikp"
+                }
+            }
+        },
+        "controllers.TwoFactorChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "mfa_required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "mfa_token": {
+                    "description": "send to /auth/login/2fa with the code",
+                    "type": "string",
+                    "example": "eyJhbGciOiJFZERTQSIsImtpZCI6..."
+                }
+            }
+        },
+        "controllers.TwoFactorCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "example": "k7m2q-x9d4a"
+                }
+            }
+        },
+        "controllers.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Expense%20Tracker:alice@manager.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=Expense+Tracker"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "controllers.UpdateGLAccountRequest": {
+            "type": "object",
+            "required": [
+                "account_code",
+                "account_name"
+            ],
+            "properties": {
+                "account_code": {
+                    "type": "string",
+                    "example": "6110"
+                },
+                "account_name": {
+                    "type": "string",
+                    "example": "Travel"
+                }
+            }
+        },
+        "controllers.UpdateSLAPolicyRequest": {
+            "type": "object",
+            "required": [
+                "escalate_after_minutes",
+                "reminder_after_minutes"
+            ],
+            "properties": {
+                "escalate_after_minutes": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1440
+                },
+                "reminder_after_minutes": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 480
+                }
+            }
+        },
+        "controllers.UpdateUserRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "manager_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Carol User"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.UserRole"
+                        }
+                    ],
+                    "example": "user"
+                }
+            }
+        },
+        "controllers.UsersListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/controllers.PaginationMeta"
+                }
+            }
+        },
+        "helpers.AuditChainBreak": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "audit entry was modified after it was written"
+                },
+                "id": {
+                    "description": "0 when the break is past the last entry",
+                    "type": "integer",
+                    "example": 42
+                },
+                "seq": {
+                    "type": "integer",
+                    "example": 17
+                }
+            }
+        },
+        "helpers.AuditChainReport": {
+            "type": "object",
+            "properties": {
+                "anchor_public_key": {
+                    "type": "string",
+                    "example": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+                },
+                "anchors": {
+                    "description": "anchors matched against the chain",
+                    "type": "integer",
+                    "example": 24
+                },
+                "broken_at": {
+                    "$ref": "#/definitions/helpers.AuditChainBreak"
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "expense_audit_logs"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "entries": {
+                    "description": "chained entries checked",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "exported_anchors": {
+                    "description": "copies in AUDIT_ANCHOR_EXPORT_FILE found in the database",
+                    "type": "integer",
+                    "example": 24
+                },
+                "head_hash": {
+                    "type": "string",
+                    "example": "9f2c..."
+                },
+                "head_seq": {
+                    "description": "as recorded in audit_chain_heads",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "unchained": {
+                    "description": "written before the chain existed, not covered",
+                    "type": "integer",
+                    "example": 35
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "helpers.EmployeeBalance": {
+            "type": "object",
+            "properties": {
+                "expensed_idr": {
+                    "description": "approved reimbursements",
+                    "type": "integer",
+                    "example": 5000000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Bob User"
+                },
+                "owed_idr": {
+                    "description": "payable balance, approved but not paid yet",
+                    "type": "integer",
+                    "example": 1500000
+                },
+                "paid_idr": {
+                    "description": "settled payments",
+                    "type": "integer",
+                    "example": 3500000
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "helpers.TrialBalance": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/helpers.TrialBalanceAccount"
+                    }
+                },
+                "balanced": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_credit_idr": {
+                    "type": "integer",
+                    "example": 8500000
+                },
+                "total_debit_idr": {
+                    "type": "integer",
+                    "example": 8500000
+                }
+            }
+        },
+        "helpers.TrialBalanceAccount": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.LedgerAccount"
+                        }
+                    ],
+                    "example": "2100"
+                },
+                "balance_idr": {
+                    "type": "integer",
+                    "example": 1500000
+                },
+                "credit_idr": {
+                    "type": "integer",
+                    "example": 5000000
+                },
+                "debit_idr": {
+                    "type": "integer",
+                    "example": 3500000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Employee reimbursements payable"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.LedgerAccountType"
+                        }
+                    ],
+                    "example": "liability"
+                }
+            }
+        },
+        "httputil.HTTPError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "message": {
+                    "type": "string",
+                    "example": "status bad request"
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "first characters of the key, to recognise it",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/constants.APIScope"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "the service account",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AccountingExport": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expense_count": {
+                    "type": "integer"
+                },
+                "format": {
+                    "$ref": "#/definitions/constants.AccountingFormat"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "paid_from": {
+                    "type": "string"
+                },
+                "paid_to": {
+                    "type": "string"
+                },
+                "total_idr": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Approval": {
+            "type": "object",
+            "properties": {
+                "approver_id": {
+                    "type": "integer"
+                },
+                "assignee_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "escalated_at": {
+                    "type": "string"
+                },
+                "escalation_due_at": {
+                    "type": "string"
+                },
+                "escalation_level": {
+                    "type": "integer"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "on_behalf_of_id": {
+                    "description": "set when a delegate decided for the approver",
+                    "type": "integer"
+                },
+                "reminded_at": {
+                    "type": "string"
+                },
+                "reminder_due_at": {
+                    "type": "string"
+                },
+                "sla_policy_id": {
+                    "description": "SLA tracking, the assignee moves up the manager chain on escalation",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/constants.ApprovalStatus"
+                },
+                "step": {
+                    "description": "step waiting for a decision, see rules.RequiredApprovalSteps",
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApprovalStep"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApprovalDelegation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "delegate": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "delegate_id": {
+                    "type": "integer"
+                },
+                "delegator": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "delegator_id": {
+                    "type": "integer"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApprovalStep": {
+            "type": "object",
+            "properties": {
+                "approval_id": {
+                    "type": "integer"
+                },
+                "approver_id": {
+                    "type": "integer"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "on_behalf_of_id": {
+                    "description": "set when a delegate approved for the approver",
+                    "type": "integer"
+                },
+                "step": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuditChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {}
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/constants.AuditAction"
+                },
+                "actor_id": {
+                    "description": "nil for background workers and SCIM",
+                    "type": "integer"
+                },
+                "api_key_id": {
+                    "description": "set when the actor used an API key",
+                    "type": "integer"
+                },
+                "changes": {
+                    "description": "changed fields only",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.AuditChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "$ref": "#/definitions/constants.AuditEntityType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Expense": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer"
+                },
+                "approval": {
+                    "$ref": "#/definitions/models.Approval"
+                },
+                "auto_approved": {
+                    "type": "boolean"
+                },
+                "category": {
+                    "$ref": "#/definitions/constants.ExpenseCategory"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "flag_reason": {
+                    "type": "string"
+                },
+                "flagged": {
+                    "description": "needs attention, e.g. the submitter was deactivated",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payment_reference": {
+                    "description": "the payment processor's id of the payment",
+                    "type": "string"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "receipt_url": {
+                    "type": "string"
+                },
+                "requires_approval": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/constants.ExpenseStatus"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GLAccountMapping": {
+            "type": "object",
+            "properties": {
+                "account_code": {
+                    "type": "string",
+                    "example": "6110"
+                },
+                "account_name": {
+                    "description": "QuickBooks matches accounts by name",
+                    "type": "string",
+                    "example": "Travel"
+                },
+                "category": {
+                    "$ref": "#/definitions/constants.ExpenseCategory"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JournalEntry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expense_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/constants.JournalEntryKind"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JournalLine"
+                    }
+                },
+                "memo": {
+                    "type": "string"
+                },
+                "posted_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JournalLine": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/constants.LedgerAccount"
+                },
+                "credit_idr": {
+                    "type": "integer"
+                },
+                "debit_idr": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "journal_entry_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "description": "the employee the amount belongs to",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoginAttempt": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "as typed, lower-cased",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "result": {
+                    "$ref": "#/definitions/constants.LoginResult"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "nil when the email is unknown",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SLAPolicy": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "escalate_after_minutes": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_amount_idr": {
+                    "description": "applies to expenses at or above this amount",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reminder_after_minutes": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deactivated_at": {
+                    "description": "deactivated users cannot authenticate",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "description": "id in the provisioning (SCIM) client",
+                    "type": "string"
                 },
                 "id": {
-                    "type": "integer",
-                    "example": 1
+                    "type": "integer"
+                },
+                "manager_id": {
+                    "description": "direct superior, nil at the top of the chain",
+                    "type": "integer"
                 },
                 "name": {
-                    "type": "string",
-                    "example": "John Doe"
+                    "type": "string"
+                },
+                "password_changed_at": {
+                    "type": "string"
                 },
                 "role": {
+                    "description": "\"user\" or \"manager\"",
                     "allOf": [
                         {
                             "$ref": "#/definitions/constants.UserRole"
                         }
-                    ],
-                    "example": "constants.UserRoleUser"
+                    ]
                 },
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6Ikp"
+                "service_account": {
+                    "description": "machine client, authenticates with API keys only",
+                    "type": "boolean"
+                },
+                "totp_enabled_at": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "controllers.MessageResponse": {
+        "models.UserInvite": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "operation successful"
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invited_by_id": {
+                    "type": "integer"
+                },
+                "manager_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/constants.UserRole"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "used_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "account created from the invite",
+                    "type": "integer"
                 }
             }
         },
-        "controllers.PaginationMeta": {
+        "services.CategorySpend": {
             "type": "object",
             "properties": {
-                "limit": {
+                "amount_idr": {
                     "type": "integer",
-                    "example": 10
+                    "example": 8400000
                 },
-                "page": {
-                    "type": "integer",
-                    "example": 1
+                "category": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/constants.ExpenseCategory"
+                        }
+                    ],
+                    "example": "travel"
                 },
-                "total": {
+                "count": {
                     "type": "integer",
-                    "example": 42
+                    "example": 12
                 }
             }
         },
-        "controllers.StatusExpenseRequest": {
+        "services.JWK": {
             "type": "object",
             "properties": {
-                "approver_id": {
-                    "type": "integer",
-                    "example": 99
+                "alg": {
+                    "type": "string",
+                    "example": "EdDSA"
                 },
-                "notes": {
+                "crv": {
                     "type": "string",
-                    "example": "Approved"
+                    "example": "Ed25519"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "b7f3c1d2e4a5f607"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "OKP"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string",
+                    "example": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
                 }
             }
         },
-        "httputil.HTTPError": {
+        "services.JWKS": {
             "type": "object",
             "properties": {
-                "code": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.JWK"
+                    }
+                }
+            }
+        },
+        "services.MonthSpend": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
                     "type": "integer",
-                    "example": 400
+                    "example": 8400000
                 },
-                "message": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "month": {
                     "type": "string",
-                    "example": "status bad request"
+                    "example": "2026-01"
                 }
             }
         },
-        "models.Approval": {
+        "services.SpendAnalytics": {
             "type": "object",
             "properties": {
-                "approver_id": {
-                    "type": "integer"
+                "auto_approval_rate": {
+                    "type": "number",
+                    "example": 0.4
                 },
-                "created_at": {
-                    "type": "string"
+                "avg_approval_turnaround_hours": {
+                    "type": "number",
+                    "example": 18.5
                 },
-                "expense_id": {
-                    "type": "integer"
+                "by_category": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CategorySpend"
+                    }
                 },
-                "id": {
-                    "type": "integer"
+                "by_month": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.MonthSpend"
+                    }
                 },
-                "notes": {
+                "by_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StatusSpend"
+                    }
+                },
+                "by_user": {
+                    "description": "top spenders by amount",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.UserSpend"
+                    }
+                },
+                "decided_count": {
+                    "description": "manager decisions behind the turnaround",
+                    "type": "integer",
+                    "example": 30
+                },
+                "from": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/constants.ApprovalStatus"
+                "generated_at": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "payment_failure_rate": {
+                    "type": "number",
+                    "example": 0.02
+                },
+                "payments_failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
                     "type": "string"
+                },
+                "totals": {
+                    "$ref": "#/definitions/services.SpendBucket"
                 }
             }
         },
-        "models.Expense": {
+        "services.SpendBucket": {
             "type": "object",
             "properties": {
                 "amount_idr": {
-                    "type": "integer"
-                },
-                "approval": {
-                    "$ref": "#/definitions/models.Approval"
-                },
-                "auto_approved": {
-                    "type": "boolean"
+                    "type": "integer",
+                    "example": 8400000
                 },
-                "created_at": {
-                    "type": "string"
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "services.SpendingLimits": {
+            "type": "object",
+            "properties": {
+                "approval_threshold_idr": {
+                    "type": "integer",
+                    "example": 1000000
                 },
-                "description": {
-                    "type": "string"
+                "largest_expense_idr": {
+                    "type": "integer",
+                    "example": 3500000
                 },
-                "id": {
-                    "type": "integer"
+                "largest_share_of_max": {
+                    "description": "largest expense / max expense",
+                    "type": "number",
+                    "example": 0.07
                 },
-                "processed_at": {
-                    "type": "string"
+                "max_expense_idr": {
+                    "type": "integer",
+                    "example": 50000000
                 },
-                "receipt_url": {
-                    "type": "string"
+                "min_expense_idr": {
+                    "type": "integer",
+                    "example": 10000
                 },
-                "requires_approval": {
-                    "type": "boolean"
+                "needed_approval": {
+                    "description": "expenses at or above the approval threshold",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "services.SpendingPeriod": {
+            "type": "object",
+            "properties": {
+                "avg_hours_to_payment": {
+                    "description": "submission to payment, over the Reimbursed expenses",
+                    "type": "number",
+                    "example": 52.5
                 },
-                "status": {
-                    "$ref": "#/definitions/constants.ExpenseStatus"
+                "awaiting_payment": {
+                    "description": "approved, payment not completed yet",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.SpendingTotals"
+                        }
+                    ]
                 },
-                "submitted_at": {
+                "from": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "pending": {
+                    "description": "waiting for a manager",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.SpendingTotals"
+                        }
+                    ]
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
+                "reimbursed": {
+                    "description": "paid out in the period, whenever submitted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.SpendingTotals"
+                        }
+                    ]
                 },
-                "user_id": {
-                    "type": "integer"
+                "rejected": {
+                    "$ref": "#/definitions/services.SpendingTotals"
                 },
-                "uuid": {
-                    "type": "string"
+                "submitted": {
+                    "$ref": "#/definitions/services.SpendingTotals"
                 }
             }
         },
-        "models.ExpenseAuditLog": {
+        "services.SpendingSummary": {
             "type": "object",
             "properties": {
-                "actor_id": {
-                    "type": "integer"
-                },
-                "created_at": {
+                "generated_at": {
                     "type": "string"
                 },
-                "expense_id": {
-                    "type": "integer"
+                "limits": {
+                    "$ref": "#/definitions/services.SpendingLimits"
                 },
-                "from_status": {
-                    "$ref": "#/definitions/constants.ExpenseStatus"
+                "month": {
+                    "$ref": "#/definitions/services.SpendingPeriod"
                 },
-                "id": {
-                    "type": "integer"
+                "quarter": {
+                    "$ref": "#/definitions/services.SpendingPeriod"
                 },
-                "reason": {
-                    "type": "string"
+                "user_id": {
+                    "type": "integer",
+                    "example": 7
                 },
-                "to_status": {
-                    "$ref": "#/definitions/constants.ExpenseStatus"
+                "year": {
+                    "$ref": "#/definitions/services.SpendingPeriod"
                 }
             }
         },
-        "models.User": {
+        "services.SpendingTotals": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
+                "amount_idr": {
+                    "type": "integer",
+                    "example": 1250000
                 },
-                "id": {
-                    "type": "integer"
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "services.StatusSpend": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer",
+                    "example": 8400000
                 },
-                "name": {
-                    "type": "string"
+                "count": {
+                    "type": "integer",
+                    "example": 12
                 },
-                "role": {
-                    "description": "\"user\" or \"manager\"",
+                "status": {
                     "allOf": [
                         {
-                            "$ref": "#/definitions/constants.UserRole"
+                            "$ref": "#/definitions/constants.ExpenseStatus"
                         }
-                    ]
+                    ],
+                    "example": "approved"
+                }
+            }
+        },
+        "services.UserSpend": {
+            "type": "object",
+            "properties": {
+                "amount_idr": {
+                    "type": "integer",
+                    "example": 8400000
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Bob User"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 7
                 }
             }
         }
     },
     "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
         "CookieAuth": {
             "type": "apiKey",
             "name": "access_token",
@@ -0,0 +1,226 @@
+package helpers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultXeroTaxRate = "Tax Exempt"
+
+// AccountingEntry is a paid expense as a journal entry: the category's account is
+// debited and the payment account credited with the amount
+type AccountingEntry struct {
+	Number           string // EXP-12, the same in every format
+	Date             time.Time
+	Employee         string
+	Description      string
+	ExpenseUUID      string
+	PaymentReference string
+	AmountIDR        int64
+	Debit            constants.GLAccount
+	Credit           constants.GLAccount
+}
+
+// Memo is the line text accounting sees
+func (e AccountingEntry) Memo() string {
+	return e.Employee + ": " + e.Description
+}
+
+// JournalWriter streams entries of a journal download
+type JournalWriter interface {
+	WriteEntry(entry AccountingEntry) error
+	Close() error
+}
+
+// GetGLAccounts returns the account of every category, a category without a mapping
+// books to its constants.DefaultGLAccounts account
+func GetGLAccounts(db *gorm.DB) ([]models.GLAccountMapping, error) {
+	var mapped []models.GLAccountMapping
+	if err := db.Find(&mapped).Error; err != nil {
+		return nil, err
+	}
+
+	accounts := make([]models.GLAccountMapping, 0, len(constants.ExpenseCategories))
+	for _, category := range constants.ExpenseCategories {
+		account := models.GLAccountMapping{
+			Category:    category,
+			AccountCode: constants.DefaultGLAccounts[category].Code,
+			AccountName: constants.DefaultGLAccounts[category].Name,
+		}
+		for _, mapping := range mapped {
+			if mapping.Category == category {
+				account = mapping
+			}
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// GLAccountsByCategory is GetGLAccounts keyed by category
+func GLAccountsByCategory(db *gorm.DB) (map[constants.ExpenseCategory]constants.GLAccount, error) {
+	mappings, err := GetGLAccounts(db)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[constants.ExpenseCategory]constants.GLAccount, len(mappings))
+	for _, mapping := range mappings {
+		accounts[mapping.Category] = constants.GLAccount{Code: mapping.AccountCode, Name: mapping.AccountName}
+	}
+	return accounts, nil
+}
+
+// GLPaymentAccount is the account payments are credited to, set with
+// ACCOUNTING_PAYMENT_ACCOUNT_CODE and ACCOUNTING_PAYMENT_ACCOUNT_NAME
+func GLPaymentAccount() constants.GLAccount {
+	account := constants.DefaultGLPaymentAccount
+	if code := strings.TrimSpace(os.Getenv("ACCOUNTING_PAYMENT_ACCOUNT_CODE")); code != "" {
+		account.Code = code
+	}
+	if name := strings.TrimSpace(os.Getenv("ACCOUNTING_PAYMENT_ACCOUNT_NAME")); name != "" {
+		account.Name = name
+	}
+	return account
+}
+
+// NewJournalWriter starts a journal download named basename in format and writes its header
+func NewJournalWriter(c *gin.Context, format constants.AccountingFormat, basename string) (JournalWriter, error) {
+	switch format {
+	case constants.AccountingFormatIIF:
+		SetDownloadHeaders(c, basename+".iif", "text/plain; charset=utf-8")
+		c.Status(200)
+		writer := &iifJournalWriter{bufio.NewWriter(c.Writer)}
+		return writer, writer.header()
+	case constants.AccountingFormatXero:
+		SetCSVHeaders(c, basename+"-xero.csv")
+		c.Status(200)
+		taxRate := strings.TrimSpace(os.Getenv("XERO_TAX_RATE"))
+		if taxRate == "" {
+			taxRate = defaultXeroTaxRate
+		}
+		writer := &xeroJournalWriter{csv.NewWriter(c.Writer), taxRate}
+		return writer, writer.writer.Write([]string{
+			"*Narration", "*Date", "Description", "*AccountCode", "*TaxRate", "*Amount",
+			"TrackingName1", "TrackingOption1", "TrackingName2", "TrackingOption2",
+		})
+	default:
+		SetCSVHeaders(c, basename+".csv")
+		c.Status(200)
+		writer := &csvJournalWriter{csv.NewWriter(c.Writer)}
+		return writer, writer.writer.Write([]string{
+			"Journal", "Date", "Account Code", "Account Name", "Description",
+			"Debit (IDR)", "Credit (IDR)", "Employee", "Expense UUID", "Payment Reference",
+		})
+	}
+}
+
+// csvJournalWriter writes a generic journal, a debit row and a credit row per entry
+type csvJournalWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvJournalWriter) WriteEntry(entry AccountingEntry) error {
+	date := ExportTime(entry.Date).Format("2006-01-02")
+	amount := strconv.FormatInt(entry.AmountIDR, 10)
+	for _, side := range []struct {
+		account       constants.GLAccount
+		debit, credit string
+	}{
+		{entry.Debit, amount, ""},
+		{entry.Credit, "", amount},
+	} {
+		if err := w.writer.Write([]string{
+			entry.Number, date, CSVCell(side.account.Code), CSVCell(side.account.Name), CSVCell(entry.Memo()),
+			side.debit, side.credit, CSVCell(entry.Employee), entry.ExpenseUUID, CSVCell(entry.PaymentReference),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvJournalWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// iifJournalWriter writes QuickBooks general journal transactions. QuickBooks matches
+// accounts by name, a positive amount is a debit.
+type iifJournalWriter struct {
+	writer *bufio.Writer
+}
+
+func (w *iifJournalWriter) header() error {
+	return w.lines(
+		[]string{"!TRNS", "TRNSTYPE", "DATE", "ACCNT", "AMOUNT", "DOCNUM", "MEMO"},
+		[]string{"!SPL", "TRNSTYPE", "DATE", "ACCNT", "AMOUNT", "DOCNUM", "MEMO"},
+		[]string{"!ENDTRNS"},
+	)
+}
+
+func (w *iifJournalWriter) WriteEntry(entry AccountingEntry) error {
+	date := ExportTime(entry.Date).Format("01/02/2006")
+	amount := strconv.FormatInt(entry.AmountIDR, 10)
+	return w.lines(
+		[]string{"TRNS", "GENERAL JOURNAL", date, iifField(entry.Debit.Name), amount, entry.Number, iifField(entry.Memo())},
+		[]string{"SPL", "GENERAL JOURNAL", date, iifField(entry.Credit.Name), "-" + amount, entry.Number, iifField(entry.Memo())},
+		[]string{"ENDTRNS"},
+	)
+}
+
+func (w *iifJournalWriter) Close() error {
+	return w.writer.Flush()
+}
+
+func (w *iifJournalWriter) lines(lines ...[]string) error {
+	for _, fields := range lines {
+		if _, err := w.writer.WriteString(strings.Join(fields, "\t") + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iifField keeps a value on its line and in its column
+func iifField(value string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(value)
+}
+
+// xeroJournalWriter writes Xero's manual journal import, lines with the same narration
+// and date form one journal and a positive amount is a debit
+type xeroJournalWriter struct {
+	writer  *csv.Writer
+	taxRate string
+}
+
+func (w *xeroJournalWriter) WriteEntry(entry AccountingEntry) error {
+	narration := CSVCell(entry.Number + " " + entry.Employee)
+	date := ExportTime(entry.Date).Format("02/01/2006")
+	amount := strconv.FormatInt(entry.AmountIDR, 10)
+	for _, line := range [][2]string{
+		{entry.Debit.Code, amount},
+		{entry.Credit.Code, "-" + amount},
+	} {
+		if err := w.writer.Write([]string{
+			narration, date, CSVCell(entry.Memo()), CSVCell(line[0]), w.taxRate, line[1], "", "", "", "",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *xeroJournalWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
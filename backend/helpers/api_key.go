@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/services"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix tells API keys apart from other bearer tokens and makes leaked keys easy to scan for
+	APIKeyPrefix = "etk_"
+
+	DefaultAPIKeyTTL    = 90 * 24 * time.Hour
+	DefaultAPIKeyMaxTTL = 365 * 24 * time.Hour
+
+	apiKeyDisplayLength = 12
+	apiKeyTouchInterval = time.Minute
+)
+
+func APIKeyTTL() time.Duration {
+	return services.DurationFromEnv("API_KEY_TTL", DefaultAPIKeyTTL)
+}
+
+func APIKeyMaxTTL() time.Duration {
+	return services.DurationFromEnv("API_KEY_MAX_TTL", DefaultAPIKeyMaxTTL)
+}
+
+// NewAPIKey returns the key for the client, its display prefix and the hash we store
+func NewAPIKey() (key, prefix, hash string, err error) {
+	secret, err := services.RandomURLToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + secret
+	return key, key[:apiKeyDisplayLength], HashToken(key), nil
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// TouchAPIKey records the last use, at most once a minute per key to spare the database
+func TouchAPIKey(db *gorm.DB, key *models.APIKey, ip string, now time.Time) {
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < apiKeyTouchInterval && key.LastUsedIP == ip {
+		return
+	}
+	if err := db.Model(&models.APIKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ip,
+	}).Error; err != nil {
+		log.Printf("Failed to record use of API key %d: %v", key.ID, err)
+	}
+}
@@ -0,0 +1,394 @@
+package helpers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+
+	"gorm.io/gorm"
+)
+
+// ExpenseAuditChain names the chain of expense_audit_logs in heads and anchors
+const ExpenseAuditChain = "expense_audit_logs"
+
+const auditVerifyBatchSize = 500
+
+// auditEntryContent is what an entry hash covers, changing it breaks existing chains
+type auditEntryContent struct {
+	Seq          int64                   `json:"seq"`
+	PrevHash     string                  `json:"prev_hash"`
+	ExpenseID    int64                   `json:"expense_id"`
+	ActorID      *int64                  `json:"actor_id"`
+	OnBehalfOfID *int64                  `json:"on_behalf_of_id"`
+	FromStatus   constants.ExpenseStatus `json:"from_status"`
+	ToStatus     constants.ExpenseStatus `json:"to_status"`
+	Reason       string                  `json:"reason"`
+	CreatedAt    string                  `json:"created_at"`
+}
+
+// auditAnchorContent is what an anchor signature covers
+type auditAnchorContent struct {
+	Chain     string `json:"chain"`
+	Number    int64  `json:"number"`
+	Seq       int64  `json:"seq"`
+	Hash      string `json:"hash"`
+	CreatedAt string `json:"created_at"`
+}
+
+// exportedAuditAnchor is one line of AUDIT_ANCHOR_EXPORT_FILE
+type exportedAuditAnchor struct {
+	models.AuditAnchor
+	PublicKey string `json:"public_key"`
+}
+
+type AuditChainBreak struct {
+	ID    int64  `json:"id" example:"42"` // 0 when the break is past the last entry
+	Seq   int64  `json:"seq" example:"17"`
+	Error string `json:"error" example:"audit entry was modified after it was written"`
+}
+
+type AuditChainReport struct {
+	Chain           string           `json:"chain" example:"expense_audit_logs"`
+	Valid           bool             `json:"valid" example:"true"`
+	Entries         int64            `json:"entries" example:"1200"`  // chained entries checked
+	Unchained       int64            `json:"unchained" example:"35"`  // written before the chain existed, not covered
+	HeadSeq         int64            `json:"head_seq" example:"1200"` // as recorded in audit_chain_heads
+	HeadHash        string           `json:"head_hash" example:"9f2c..."`
+	Anchors         int              `json:"anchors" example:"24"`          // anchors matched against the chain
+	ExportedAnchors int              `json:"exported_anchors" example:"24"` // copies in AUDIT_ANCHOR_EXPORT_FILE found in the database
+	AnchorPublicKey string           `json:"anchor_public_key" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+	BrokenAt        *AuditChainBreak `json:"broken_at,omitempty"`
+	CheckedAt       time.Time        `json:"checked_at"`
+}
+
+// HashExpenseAuditLog hashes the content of an entry together with its position and PrevHash
+func HashExpenseAuditLog(entry *models.ExpenseAuditLog) string {
+	content := auditEntryContent{
+		ExpenseID:    entry.ExpenseID,
+		ActorID:      entry.ActorID,
+		OnBehalfOfID: entry.OnBehalfOfID,
+		FromStatus:   entry.FromStatus,
+		ToStatus:     entry.ToStatus,
+		Reason:       entry.Reason,
+		CreatedAt:    entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if entry.Seq != nil {
+		content.Seq = *entry.Seq
+	}
+	if entry.PrevHash != nil {
+		content.PrevHash = *entry.PrevHash
+	}
+
+	data, _ := json.Marshal(content)
+	return HashToken(string(data))
+}
+
+// AppendExpenseAuditLog links the entry to the chain head and stores it. It runs in
+// its own transaction, or a savepoint of tx, so a failed append leaves the head alone.
+func AppendExpenseAuditLog(tx *gorm.DB, entry *models.ExpenseAuditLog) error {
+	return tx.Transaction(func(tx *gorm.DB) error {
+		// postgres keeps microseconds, truncate so the hash survives the round trip
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		head, err := lockAuditChainHead(tx, ExpenseAuditChain, now)
+		if err != nil {
+			return err
+		}
+
+		seq := head.Seq + 1
+		prevHash := head.Hash
+		entry.Seq = &seq
+		entry.PrevHash = &prevHash
+		entry.CreatedAt = now
+		hash := HashExpenseAuditLog(entry)
+		entry.Hash = &hash
+
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.AuditChainHead{}).
+			Where("chain = ?", ExpenseAuditChain).
+			Updates(map[string]interface{}{"seq": seq, "hash": hash, "updated_at": now}).Error
+	})
+}
+
+// lockAuditChainHead writes the head row first so concurrent appends wait for this
+// transaction and then read the head it leaves behind
+func lockAuditChainHead(tx *gorm.DB, chain string, now time.Time) (*models.AuditChainHead, error) {
+	result := tx.Model(&models.AuditChainHead{}).Where("chain = ?", chain).Update("updated_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// the migration creates the head, this covers databases built without it
+		if err := tx.Create(&models.AuditChainHead{Chain: chain, UpdatedAt: now}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var head models.AuditChainHead
+	if err := tx.First(&head, "chain = ?", chain).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// VerifyExpenseAuditChain walks the chain from the first entry and reports the first
+// broken link: an edited, deleted or inserted entry, a truncated tail, an anchor the
+// chain no longer matches or an anchor that is missing or not signed by the anchor key
+func VerifyExpenseAuditChain(db *gorm.DB) (*AuditChainReport, error) {
+	report := &AuditChainReport{Chain: ExpenseAuditChain, CheckedAt: time.Now().UTC()}
+
+	anchorKey, err := services.LoadAuditAnchorKey()
+	if err != nil {
+		return nil, err
+	}
+	report.AnchorPublicKey = anchorKey.PublicKey()
+
+	var heads []models.AuditChainHead
+	if err := db.Where("chain = ?", ExpenseAuditChain).Limit(1).Find(&heads).Error; err != nil {
+		return nil, err
+	}
+	if len(heads) > 0 {
+		report.HeadSeq = heads[0].Seq
+		report.HeadHash = heads[0].Hash
+	}
+
+	if err := db.Model(&models.ExpenseAuditLog{}).Where("seq IS NULL").Count(&report.Unchained).Error; err != nil {
+		return nil, err
+	}
+
+	var anchors []models.AuditAnchor
+	if err := db.Where("chain = ?", ExpenseAuditChain).Order("number ASC").Find(&anchors).Error; err != nil {
+		return nil, err
+	}
+	exported, err := readExportedAuditAnchors(ExpenseAuditChain)
+	if err != nil {
+		return nil, err
+	}
+	if broken := checkAuditAnchors(anchorKey, anchors, exported); broken != nil {
+		report.BrokenAt = broken
+		return report, nil
+	}
+	report.ExportedAnchors = len(exported)
+
+	anchorsBySeq := map[int64][]models.AuditAnchor{}
+	var lastAnchorSeq int64
+	for _, anchor := range anchors {
+		anchorsBySeq[anchor.Seq] = append(anchorsBySeq[anchor.Seq], anchor)
+		lastAnchorSeq = max(lastAnchorSeq, anchor.Seq)
+	}
+
+	expectedSeq := int64(1)
+	prevHash := ""
+	for {
+		var entries []models.ExpenseAuditLog
+		if err := db.Where("seq >= ?", expectedSeq).
+			Order("seq ASC").
+			Limit(auditVerifyBatchSize).
+			Find(&entries).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			err := rules.CheckAuditLink(*entry.Seq, expectedSeq, stringValue(entry.PrevHash), prevHash, stringValue(entry.Hash), HashExpenseAuditLog(entry))
+			if err == nil {
+				for _, anchor := range anchorsBySeq[*entry.Seq] {
+					if anchor.Hash != *entry.Hash {
+						err = rules.ErrAuditAnchorMismatch
+						break
+					}
+					report.Anchors++
+				}
+			}
+			if err != nil {
+				report.BrokenAt = &AuditChainBreak{ID: entry.ID, Seq: *entry.Seq, Error: err.Error()}
+				return report, nil
+			}
+
+			prevHash = *entry.Hash
+			expectedSeq++
+			report.Entries++
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	// entries removed from the end leave no gap, only the head and anchors remember them
+	switch {
+	case report.Entries < report.HeadSeq || report.Entries < lastAnchorSeq:
+		report.BrokenAt = &AuditChainBreak{Seq: report.Entries + 1, Error: rules.ErrAuditChainTruncated.Error()}
+	case report.Entries > report.HeadSeq || prevHash != report.HeadHash:
+		report.BrokenAt = &AuditChainBreak{Seq: report.HeadSeq, Error: rules.ErrAuditHeadMismatch.Error()}
+	default:
+		report.Valid = true
+	}
+
+	return report, nil
+}
+
+// AnchorAuditChain signs a verified chain head with the audit anchor key and stores it
+// under the next number. Anchors are checked by VerifyExpenseAuditChain, a rewrite of the
+// chain would also need the anchor key.
+func AnchorAuditChain(db *gorm.DB, chain string, seq int64, hash string, now time.Time) (*models.AuditAnchor, error) {
+	anchorKey, err := services.LoadAuditAnchorKey()
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := LatestAuditAnchor(db, chain)
+	if err != nil {
+		return nil, err
+	}
+	number := int64(1)
+	if latest != nil {
+		number = latest.Number + 1
+	}
+
+	// postgres keeps microseconds, truncate so the signature survives the round trip
+	anchor := models.AuditAnchor{
+		Chain:     chain,
+		Number:    number,
+		Seq:       seq,
+		Hash:      hash,
+		CreatedAt: now.UTC().Truncate(time.Microsecond),
+	}
+	anchor.Signature = anchorKey.Sign(auditAnchorMessage(&anchor))
+
+	// the unique (chain, number) index turns a concurrent anchor into an error
+	if err := db.Create(&anchor).Error; err != nil {
+		return nil, err
+	}
+	return &anchor, nil
+}
+
+// LatestAuditAnchor returns nil when the chain was never anchored
+func LatestAuditAnchor(db *gorm.DB, chain string) (*models.AuditAnchor, error) {
+	var anchors []models.AuditAnchor
+	if err := db.Where("chain = ?", chain).Order("number DESC").Limit(1).Find(&anchors).Error; err != nil {
+		return nil, err
+	}
+	if len(anchors) == 0 {
+		return nil, nil
+	}
+	return &anchors[0], nil
+}
+
+// ExportAuditAnchor appends the anchor and the public key as a JSON line to
+// AUDIT_ANCHOR_EXPORT_FILE. It should live where database users cannot write, e.g. an
+// append-only mount, verification then also catches anchors removed from the database.
+func ExportAuditAnchor(anchor *models.AuditAnchor) error {
+	path := os.Getenv("AUDIT_ANCHOR_EXPORT_FILE")
+	if path == "" {
+		return nil
+	}
+
+	anchorKey, err := services.LoadAuditAnchorKey()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(exportedAuditAnchor{AuditAnchor: *anchor, PublicKey: anchorKey.PublicKey()})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit anchor export: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit anchor export: %w", err)
+	}
+	return file.Close()
+}
+
+// readExportedAuditAnchors returns the exported anchors of chain, none when nothing was exported yet
+func readExportedAuditAnchors(chain string) ([]models.AuditAnchor, error) {
+	path := os.Getenv("AUDIT_ANCHOR_EXPORT_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit anchor export: %w", err)
+	}
+	defer file.Close()
+
+	var anchors []models.AuditAnchor
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var exported exportedAuditAnchor
+		if err := json.Unmarshal(scanner.Bytes(), &exported); err != nil {
+			return nil, fmt.Errorf("failed to read audit anchor export: %w", err)
+		}
+		if exported.Chain == chain {
+			anchors = append(anchors, exported.AuditAnchor)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit anchor export: %w", err)
+	}
+	return anchors, nil
+}
+
+// checkAuditAnchors breaks on an anchor the anchor key did not sign, a gap in the numbers
+// and an exported anchor the database no longer has. anchors are ordered by number.
+func checkAuditAnchors(anchorKey *services.AuditAnchorKey, anchors, exported []models.AuditAnchor) *AuditChainBreak {
+	byNumber := map[int64]*models.AuditAnchor{}
+	for i := range anchors {
+		anchor := &anchors[i]
+		if !anchorKey.Verify(auditAnchorMessage(anchor), anchor.Signature) {
+			return &AuditChainBreak{Seq: anchor.Seq, Error: rules.ErrAuditAnchorForged.Error()}
+		}
+		if anchor.Number != int64(i+1) {
+			return &AuditChainBreak{Seq: anchor.Seq, Error: rules.ErrAuditAnchorMissing.Error()}
+		}
+		byNumber[anchor.Number] = anchor
+	}
+
+	for _, copied := range exported {
+		stored, ok := byNumber[copied.Number]
+		if !ok {
+			return &AuditChainBreak{Seq: copied.Seq, Error: rules.ErrAuditAnchorMissing.Error()}
+		}
+		if stored.Signature != copied.Signature {
+			return &AuditChainBreak{Seq: copied.Seq, Error: rules.ErrAuditAnchorMismatch.Error()}
+		}
+	}
+	return nil
+}
+
+func auditAnchorMessage(anchor *models.AuditAnchor) []byte {
+	data, _ := json.Marshal(auditAnchorContent{
+		Chain:     anchor.Chain,
+		Number:    anchor.Number,
+		Seq:       anchor.Seq,
+		Hash:      anchor.Hash,
+		CreatedAt: anchor.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	return data
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fields that change on every save and say nothing about the edit
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// AuditEventInput describes one event, Before and After are diffed field by field.
+// Leave Before nil for a creation and After nil for a deletion.
+type AuditEventInput struct {
+	Action     constants.AuditAction
+	EntityType constants.AuditEntityType
+	EntityID   interface{}
+	ActorID    *int64 // defaults to the authenticated user of the request
+	Before     interface{}
+	After      interface{}
+	Metadata   map[string]interface{}
+}
+
+// RecordAuditEvent appends to the audit trail. The actor, API key, IP and user agent come
+// from the request, workers pass a nil context. A failed write is logged but never blocks
+// the change it describes.
+func RecordAuditEvent(db *gorm.DB, c *gin.Context, input AuditEventInput) {
+	event := models.AuditEvent{
+		ActorID:    input.ActorID,
+		Action:     input.Action,
+		EntityType: input.EntityType,
+		Changes:    AuditDiff(input.Before, input.After),
+		Metadata:   input.Metadata,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if input.EntityID != nil {
+		event.EntityID = fmt.Sprint(input.EntityID)
+	}
+
+	if c != nil {
+		event.IPAddress = c.ClientIP()
+		event.UserAgent = c.Request.UserAgent()
+		if event.ActorID == nil {
+			if user, ok := CurrentUser(c); ok {
+				event.ActorID = &user.ID
+			}
+		}
+		if value, exists := c.Get("api_key"); exists {
+			if key, ok := value.(models.APIKey); ok {
+				event.APIKeyID = &key.ID
+			}
+		}
+	}
+
+	if err := db.Create(&event).Error; err != nil {
+		log.Printf("Failed to record audit event %s on %s %s: %v", event.Action, event.EntityType, event.EntityID, err)
+	}
+}
+
+// AuditDiff compares the JSON form of two values and keeps the fields that differ.
+// Fields hidden from JSON, like password hashes and secrets, never reach the trail.
+func AuditDiff(before, after interface{}) map[string]models.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := map[string]models.AuditChange{}
+	for field, value := range afterFields {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		old, existed := beforeFields[field]
+		if existed && reflect.DeepEqual(old, value) {
+			continue
+		}
+		changes[field] = models.AuditChange{Before: old, After: value}
+	}
+	for field, old := range beforeFields {
+		if _, exists := afterFields[field]; !exists && !auditIgnoredFields[field] {
+			changes[field] = models.AuditChange{Before: old}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func auditFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if value == nil {
+		return fields
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return map[string]interface{}{}
+	}
+	return fields
+}
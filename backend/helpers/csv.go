@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSVCell keeps spreadsheet apps from running cell text as a formula
+func CSVCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// SetCSVHeaders makes the response a CSV download named filename
+func SetCSVHeaders(c *gin.Context, filename string) {
+	SetDownloadHeaders(c, filename, "text/csv; charset=utf-8")
+}
+
+// SetDownloadHeaders makes the response a download of contentType named filename
+func SetDownloadHeaders(c *gin.Context, filename, contentType string) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+}
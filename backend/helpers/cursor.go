@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// kinds of sort values a cursor can carry, the value is bound back with its own type
+const (
+	cursorKindTime   = "t"
+	cursorKindInt    = "n"
+	cursorKindString = "s"
+)
+
+// Cursor is the position of the last row of a page: its sort value and id
+type Cursor struct {
+	Value interface{} // time.Time, int64 or string
+	ID    int64
+}
+
+// cursorToken is the JSON inside the opaque cursor, the sort is kept so a cursor
+// cannot be replayed against a different order
+type cursorToken struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Kind  string `json:"k"`
+	Value string `json:"v"`
+	ID    int64  `json:"i"`
+}
+
+// GetCursor reads the cursor query parameter. keyset is false when the client pages
+// with page instead, an empty cursor asks for the first page of keyset paging.
+func GetCursor(c *gin.Context, sort Sort) (cursor *Cursor, keyset bool, err error) {
+	value, keyset := c.GetQuery("cursor")
+	if !keyset || value == "" {
+		return nil, keyset, nil
+	}
+
+	cursor, err = DecodeCursor(value, sort)
+	if err != nil {
+		return nil, true, err
+	}
+	return cursor, true, nil
+}
+
+// EncodeCursor returns the opaque cursor of a row at value and id in sort
+func EncodeCursor(sort Sort, value interface{}, id int64) string {
+	token := cursorToken{Sort: sort.Key, Desc: sort.Desc, ID: id}
+	token.Kind, token.Value = encodeCursorValue(value)
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns rules.ErrInvalidCursor for a cursor that is not ours or was
+// issued for another sort
+func DecodeCursor(value string, sort Sort) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, rules.ErrInvalidCursor
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, rules.ErrInvalidCursor
+	}
+	if token.Sort != sort.Key || token.Desc != sort.Desc {
+		return nil, rules.ErrInvalidCursor
+	}
+
+	cursor := &Cursor{ID: token.ID}
+	switch token.Kind {
+	case cursorKindTime:
+		at, err := time.Parse(time.RFC3339Nano, token.Value)
+		if err != nil {
+			return nil, rules.ErrInvalidCursor
+		}
+		cursor.Value = at
+	case cursorKindInt:
+		n, err := strconv.ParseInt(token.Value, 10, 64)
+		if err != nil {
+			return nil, rules.ErrInvalidCursor
+		}
+		cursor.Value = n
+	case cursorKindString:
+		cursor.Value = token.Value
+	default:
+		return nil, rules.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// ApplyCursor keeps the rows that come after the cursor in sort, which must be
+// ordered with sort.OrderBy(idColumn). The sort column must not be NULL.
+func ApplyCursor(query *gorm.DB, sort Sort, idColumn string, cursor *Cursor) *gorm.DB {
+	if cursor == nil {
+		return query
+	}
+
+	op := ">"
+	if sort.Desc {
+		op = "<"
+	}
+	condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", sort.Column, op, sort.Column, idColumn, op)
+	return query.Where(condition, cursor.Value, cursor.Value, cursor.ID)
+}
+
+// NextCursor trims a page fetched with limit+1 rows to limit and returns the cursor
+// of the page after it, nil when this was the last page
+func NextCursor[T any](rows []T, limit int, sort Sort, position func(T) (interface{}, int64)) ([]T, *string) {
+	if len(rows) <= limit {
+		return rows, nil
+	}
+
+	rows = rows[:limit]
+	value, id := position(rows[limit-1])
+	next := EncodeCursor(sort, value, id)
+	return rows, &next
+}
+
+func encodeCursorValue(value interface{}) (string, string) {
+	if at, ok := value.(time.Time); ok {
+		return cursorKindTime, at.UTC().Format(time.RFC3339Nano)
+	}
+
+	// typed strings and integers, e.g. constants.ExpenseStatus
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorKindInt, strconv.FormatInt(v.Int(), 10)
+	case reflect.String:
+		return cursorKindString, v.String()
+	}
+	return cursorKindString, fmt.Sprint(value)
+}
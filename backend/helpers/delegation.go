@@ -0,0 +1,30 @@
+package helpers
+
+import (
+	"time"
+
+	"backend/models"
+	"backend/rules"
+
+	"gorm.io/gorm"
+)
+
+// FindActiveDelegation returns the delegation letting delegateID act for delegatorID at the given time
+func FindActiveDelegation(db *gorm.DB, delegatorID, delegateID int64, at time.Time) (*models.ApprovalDelegation, error) {
+	var delegations []models.ApprovalDelegation
+	if err := db.Preload("Delegator", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name")
+	}).
+		Where("delegator_id = ? AND delegate_id = ? AND revoked_at IS NULL", delegatorID, delegateID).
+		Order("created_at DESC").
+		Find(&delegations).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range delegations {
+		if rules.IsDelegationActive(&delegations[i], at) {
+			return &delegations[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
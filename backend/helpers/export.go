@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// ExportTimeZone is the zone timestamps are written in, Jakarta keeps UTC+7 all year
+const ExportTimeZone = "Asia/Jakarta"
+
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+var exportLocation = loadExportLocation()
+
+// ExportCell is one value of an export row, Amount and Time keep their type so XLSX
+// stores them as numbers that still sum and sort
+type ExportCell struct {
+	Text   string
+	Amount *int64     // IDR
+	Time   *time.Time // written in ExportTimeZone
+}
+
+func TextCell(value string) ExportCell {
+	return ExportCell{Text: value}
+}
+
+func AmountCell(amount int64) ExportCell {
+	return ExportCell{Amount: &amount}
+}
+
+// TimeCell leaves the cell empty for a nil time
+func TimeCell(at *time.Time) ExportCell {
+	return ExportCell{Time: at}
+}
+
+// ExportWriter streams rows of a CSV or XLSX download
+type ExportWriter interface {
+	WriteRow(cells []ExportCell) error
+	Close() error
+}
+
+// GetExportFormat reads the format query parameter, csv by default
+func GetExportFormat(c *gin.Context) (ExportFormat, error) {
+	format := ExportFormat(c.DefaultQuery("format", string(ExportFormatCSV)))
+	if format != ExportFormatCSV && format != ExportFormatXLSX {
+		return "", rules.ErrInvalidExportFormat
+	}
+	return format, nil
+}
+
+// NewExportWriter starts a download named basename plus the extension of format and
+// writes the header row. Headers are sent here, errors later can only cut the file short.
+func NewExportWriter(c *gin.Context, format ExportFormat, basename, sheetName string, header []string) (ExportWriter, error) {
+	cells := make([]ExportCell, len(header))
+	for i, title := range header {
+		cells[i] = TextCell(title)
+	}
+
+	if format == ExportFormatXLSX {
+		SetDownloadHeaders(c, basename+".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Status(200)
+		writer, err := newXLSXWriter(c.Writer, sheetName)
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxExportWriter{writer}, writer.writeRow(cells, true)
+	}
+
+	SetCSVHeaders(c, basename+".csv")
+	c.Status(200)
+	writer := &csvExportWriter{csv.NewWriter(c.Writer)}
+	return writer, writer.WriteRow(cells)
+}
+
+// FormatIDR writes an amount the Indonesian way, Rp 1.500.000
+func FormatIDR(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	grouped := make([]byte, 0, len(digits)+len(digits)/3)
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped = append(grouped, '.')
+		}
+		grouped = append(grouped, digits[i])
+	}
+	return sign + "Rp " + string(grouped)
+}
+
+// ExportTime returns t in ExportTimeZone
+func ExportTime(t time.Time) time.Time {
+	return t.In(exportLocation)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvExportWriter) WriteRow(cells []ExportCell) error {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		switch {
+		case cell.Amount != nil:
+			record[i] = FormatIDR(*cell.Amount)
+		case cell.Time != nil:
+			record[i] = ExportTime(*cell.Time).Format(exportTimeLayout)
+		default:
+			record[i] = CSVCell(cell.Text)
+		}
+	}
+	return w.writer.Write(record)
+}
+
+func (w *csvExportWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type xlsxExportWriter struct {
+	writer *xlsxWriter
+}
+
+func (w *xlsxExportWriter) WriteRow(cells []ExportCell) error {
+	return w.writer.writeRow(cells, false)
+}
+
+func (w *xlsxExportWriter) Close() error {
+	return w.writer.close()
+}
+
+// containers without tzdata still get the right offset, Jakarta has no daylight saving
+func loadExportLocation() *time.Location {
+	if location, err := time.LoadLocation(ExportTimeZone); err == nil {
+		return location
+	}
+	return time.FixedZone("WIB", 7*60*60)
+}
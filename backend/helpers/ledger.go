@@ -0,0 +1,178 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+	"backend/rules"
+
+	"gorm.io/gorm"
+)
+
+// TrialBalanceAccount is the activity and balance of one account, BalanceIDR is on
+// the account's normal side so a positive balance is the usual one
+type TrialBalanceAccount struct {
+	Account    constants.LedgerAccount     `json:"account" example:"2100"`
+	Name       string                      `json:"name" example:"Employee reimbursements payable"`
+	Type       constants.LedgerAccountType `json:"type" example:"liability"`
+	DebitIDR   int64                       `json:"debit_idr" example:"3500000"`
+	CreditIDR  int64                       `json:"credit_idr" example:"5000000"`
+	BalanceIDR int64                       `json:"balance_idr" example:"1500000"`
+}
+
+type TrialBalance struct {
+	From           *time.Time            `json:"from"`
+	To             *time.Time            `json:"to"`
+	Accounts       []TrialBalanceAccount `json:"accounts"`
+	TotalDebitIDR  int64                 `json:"total_debit_idr" example:"8500000"`
+	TotalCreditIDR int64                 `json:"total_credit_idr" example:"8500000"`
+	Balanced       bool                  `json:"balanced" example:"true"`
+}
+
+// EmployeeBalance is what the company expensed for, paid to and still owes one employee
+type EmployeeBalance struct {
+	UserID      int64  `json:"user_id" example:"7"`
+	Name        string `json:"name" example:"Bob User"`
+	ExpensedIDR int64  `json:"expensed_idr" gorm:"column:expensed_idr" example:"5000000"` // approved reimbursements
+	PaidIDR     int64  `json:"paid_idr" gorm:"column:paid_idr" example:"3500000"`         // settled payments
+	OwedIDR     int64  `json:"owed_idr" gorm:"column:owed_idr" example:"1500000"`         // payable balance, approved but not paid yet
+}
+
+// PostJournalEntry checks that entry balances and stores it with its lines, in its own
+// transaction or a savepoint of tx
+func PostJournalEntry(tx *gorm.DB, entry *models.JournalEntry) error {
+	if err := rules.ValidateJournalEntry(entry.Lines); err != nil {
+		return err
+	}
+	return tx.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(entry).Error
+	})
+}
+
+// PostExpenseApproval posts the liability of an approved expense: the expense account is
+// debited and the employee's payable credited. An expense is only posted once.
+func PostExpenseApproval(tx *gorm.DB, expense *models.Expense, at time.Time) error {
+	posted, err := expensePosted(tx, expense.ID, constants.JournalEntryKindApproval)
+	if err != nil || posted {
+		return err
+	}
+
+	return PostJournalEntry(tx, &models.JournalEntry{
+		ExpenseID: &expense.ID,
+		Kind:      constants.JournalEntryKindApproval,
+		Memo:      fmt.Sprintf("Expense #%d approved: %s", expense.ID, expense.Description),
+		PostedAt:  at,
+		Lines: []models.JournalLine{
+			{Account: constants.LedgerAccountReimbursedExpense, UserID: &expense.UserID, DebitIDR: expense.AmountIDR},
+			{Account: constants.LedgerAccountEmployeePayable, UserID: &expense.UserID, CreditIDR: expense.AmountIDR},
+		},
+	})
+}
+
+// PostExpenseSettlement posts the payment of an expense: the employee's payable is
+// debited and cash credited. Expenses approved before the ledger existed get their
+// approval posted first so the payable never goes negative.
+func PostExpenseSettlement(tx *gorm.DB, expense *models.Expense, at time.Time) error {
+	if err := PostExpenseApproval(tx, expense, at); err != nil {
+		return err
+	}
+
+	posted, err := expensePosted(tx, expense.ID, constants.JournalEntryKindSettlement)
+	if err != nil || posted {
+		return err
+	}
+
+	memo := fmt.Sprintf("Expense #%d paid", expense.ID)
+	if expense.PaymentReference != nil {
+		memo += ", payment " + *expense.PaymentReference
+	}
+	return PostJournalEntry(tx, &models.JournalEntry{
+		ExpenseID: &expense.ID,
+		Kind:      constants.JournalEntryKindSettlement,
+		Memo:      memo,
+		PostedAt:  at,
+		Lines: []models.JournalLine{
+			{Account: constants.LedgerAccountEmployeePayable, UserID: &expense.UserID, DebitIDR: expense.AmountIDR},
+			{Account: constants.LedgerAccountCash, CreditIDR: expense.AmountIDR},
+		},
+	})
+}
+
+// GetTrialBalance sums every account over the entries posted in [from, to), either
+// end may be open. The totals only differ if lines were written around PostJournalEntry.
+func GetTrialBalance(db *gorm.DB, from, to *time.Time) (*TrialBalance, error) {
+	var rows []struct {
+		Account   constants.LedgerAccount
+		DebitIDR  int64 `gorm:"column:debit_idr"`
+		CreditIDR int64 `gorm:"column:credit_idr"`
+	}
+	if err := postedLines(db, from, to).
+		Select("journal_lines.account, COALESCE(SUM(journal_lines.debit_idr), 0) AS debit_idr, COALESCE(SUM(journal_lines.credit_idr), 0) AS credit_idr").
+		Group("journal_lines.account").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	balance := &TrialBalance{From: from, To: to, Accounts: []TrialBalanceAccount{}}
+	for _, account := range constants.LedgerAccounts {
+		row := TrialBalanceAccount{Account: account.Code, Name: account.Name, Type: account.Type}
+		for _, sums := range rows {
+			if sums.Account == account.Code {
+				row.DebitIDR, row.CreditIDR = sums.DebitIDR, sums.CreditIDR
+			}
+		}
+		row.BalanceIDR = row.DebitIDR - row.CreditIDR
+		if account.Type == constants.LedgerAccountTypeLiability {
+			row.BalanceIDR = -row.BalanceIDR
+		}
+
+		balance.Accounts = append(balance.Accounts, row)
+		balance.TotalDebitIDR += row.DebitIDR
+		balance.TotalCreditIDR += row.CreditIDR
+	}
+	balance.Balanced = balance.TotalDebitIDR == balance.TotalCreditIDR
+	return balance, nil
+}
+
+// GetEmployeeBalances sums the ledger per employee over the entries posted in [from, to),
+// limited to one employee when userID is set, largest amount owed first
+func GetEmployeeBalances(db *gorm.DB, userID *int64, from, to *time.Time) ([]EmployeeBalance, error) {
+	query := postedLines(db, from, to).
+		Select("journal_lines.user_id, users.name, "+
+			"COALESCE(SUM(CASE WHEN journal_lines.account = ? THEN journal_lines.debit_idr - journal_lines.credit_idr ELSE 0 END), 0) AS expensed_idr, "+
+			"COALESCE(SUM(CASE WHEN journal_lines.account = ? THEN journal_lines.debit_idr ELSE 0 END), 0) AS paid_idr, "+
+			"COALESCE(SUM(CASE WHEN journal_lines.account = ? THEN journal_lines.credit_idr - journal_lines.debit_idr ELSE 0 END), 0) AS owed_idr",
+			constants.LedgerAccountReimbursedExpense, constants.LedgerAccountEmployeePayable, constants.LedgerAccountEmployeePayable).
+		Joins("JOIN users ON users.id = journal_lines.user_id").
+		Group("journal_lines.user_id, users.name").
+		Order("owed_idr DESC, users.name ASC")
+	if userID != nil {
+		query = query.Where("journal_lines.user_id = ?", *userID)
+	}
+
+	balances := []EmployeeBalance{}
+	if err := query.Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func postedLines(db *gorm.DB, from, to *time.Time) *gorm.DB {
+	query := db.Table("journal_lines").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id")
+	if from != nil {
+		query = query.Where("journal_entries.posted_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("journal_entries.posted_at < ?", *to)
+	}
+	return query
+}
+
+func expensePosted(tx *gorm.DB, expenseID int64, kind constants.JournalEntryKind) (bool, error) {
+	var count int64
+	err := tx.Model(&models.JournalEntry{}).Where("expense_id = ? AND kind = ?", expenseID, kind).Count(&count).Error
+	return count > 0, err
+}
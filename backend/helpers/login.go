@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"log"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var countedLoginFailures = []constants.LoginResult{
+	constants.LoginResultInvalidCredentials,
+	constants.LoginResultInvalidSecondFactor,
+}
+
+var resettingLoginResults = []constants.LoginResult{
+	constants.LoginResultSuccess,
+	constants.LoginResultUnlocked,
+}
+
+// CountLoginFailures loads the counters of rules.LoginThrottlePolicy from the login audit trail,
+// a successful login or an unlock resets the counter of its email
+func CountLoginFailures(db *gorm.DB, email, ip string, since time.Time) (rules.LoginFailures, error) {
+	var failures rules.LoginFailures
+
+	var lastSuccess models.LoginAttempt
+	err := db.Where("email = ? AND result IN ? AND created_at > ?", email, resettingLoginResults, since).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&lastSuccess).Error
+	if err != nil {
+		return failures, err
+	}
+	accountSince := since
+	if lastSuccess.ID != 0 {
+		accountSince = lastSuccess.CreatedAt
+	}
+
+	account, lastAccount, err := countFailures(db.Where("email = ?", email), accountSince)
+	if err != nil {
+		return failures, err
+	}
+	address, lastAddress, err := countFailures(db.Where("ip_address = ?", ip), since)
+	if err != nil {
+		return failures, err
+	}
+
+	failures.Account, failures.LastAccountFailure = account, lastAccount
+	failures.IP, failures.LastIPFailure = address, lastAddress
+	return failures, nil
+}
+
+// RecordLoginAttempt appends to the login audit trail, a failed write is logged but never blocks the login
+func RecordLoginAttempt(db *gorm.DB, c *gin.Context, userID *int64, email string, result constants.LoginResult) {
+	attempt := models.LoginAttempt{
+		UserID:    userID,
+		Email:     email,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Result:    result,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := db.Create(&attempt).Error; err != nil {
+		log.Printf("Failed to record login attempt for %s: %v", email, err)
+	}
+
+	// unlocks are recorded by the action that caused them
+	if result == constants.LoginResultUnlocked {
+		return
+	}
+	event := AuditEventInput{
+		Action:     constants.AuditActionLogin,
+		EntityType: constants.AuditEntityUser,
+		ActorID:    userID,
+		Metadata:   map[string]interface{}{"email": email, "result": result},
+	}
+	if userID != nil {
+		event.EntityID = *userID
+	}
+	RecordAuditEvent(db, c, event)
+}
+
+func countFailures(scope *gorm.DB, since time.Time) (int, time.Time, error) {
+	query := scope.Session(&gorm.Session{}).Model(&models.LoginAttempt{}).
+		Where("result IN ? AND created_at > ?", countedLoginFailures, since)
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil || count == 0 {
+		return 0, time.Time{}, err
+	}
+
+	var last models.LoginAttempt
+	if err := query.Order("created_at DESC").First(&last).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	return int(count), last.CreatedAt, nil
+}
@@ -0,0 +1,317 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A4 in points, content flows between the margins from the top down
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 48.0
+	pdfFooterY    = 28.0
+
+	// PDFContentWidth is the room between the side margins, for sizing table columns
+	PDFContentWidth = pdfPageWidth - 2*pdfMargin
+)
+
+const (
+	pdfFontRegular = "F1" // Helvetica
+	pdfFontBold    = "F2" // Helvetica-Bold
+
+	pdfTextSize    = 9.0
+	pdfLineHeight  = 13.0
+	pdfFieldIndent = 130.0
+)
+
+// widths of the printable ASCII characters, 32 to 126, in 1/1000 of the font size
+var pdfHelveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var pdfHelveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// the Windows-1252 characters outside Latin-1 that show up in typed text
+var pdfWinAnsiExtras = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// PDFColumn is a column of PDFDocument.Table, Right aligns amounts
+type PDFColumn struct {
+	Title string
+	Width float64
+	Right bool
+}
+
+// PDFDocument lays out a plain A4 report with the standard Helvetica fonts, so nothing
+// is embedded and no external renderer is needed. Text is WinAnsi, other characters
+// print as a question mark.
+type PDFDocument struct {
+	title string
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // baseline of the next line, from the bottom of the page
+}
+
+func NewPDFDocument(title string) *PDFDocument {
+	d := &PDFDocument{title: title}
+	d.addPage()
+	return d
+}
+
+// Heading writes the document title
+func (d *PDFDocument) Heading(text string) {
+	d.ensure(24)
+	d.text(pdfMargin, d.y-6, 16, true, text)
+	d.y -= 28
+}
+
+// Section starts a titled block with a rule under the title
+func (d *PDFDocument) Section(text string) {
+	d.ensure(4 * pdfLineHeight)
+	d.y -= 8
+	d.text(pdfMargin, d.y, 11, true, text)
+	d.line(pdfMargin, d.y-4, pdfPageWidth-pdfMargin, d.y-4)
+	d.y -= 18
+}
+
+// Text writes a line of text, cut to the page width
+func (d *PDFDocument) Text(text string) {
+	d.ensure(pdfLineHeight)
+	d.text(pdfMargin, d.y, pdfTextSize, false, fitPDFText(text, pdfTextSize, false, PDFContentWidth))
+	d.y -= pdfLineHeight
+}
+
+// Field writes a bold label with its value beside it, a long value wraps
+func (d *PDFDocument) Field(label, value string) {
+	d.ensure(pdfLineHeight)
+	d.text(pdfMargin, d.y, pdfTextSize, true, label)
+	for i, line := range wrapPDFText(value, pdfTextSize, PDFContentWidth-pdfFieldIndent) {
+		if i > 0 {
+			d.ensure(pdfLineHeight)
+		}
+		d.text(pdfMargin+pdfFieldIndent, d.y, pdfTextSize, false, line)
+		d.y -= pdfLineHeight
+	}
+}
+
+// Table writes rows under a header, the header is repeated on every page the table
+// runs onto. Cells are cut to their column width.
+func (d *PDFDocument) Table(columns []PDFColumn, rows [][]string) {
+	d.ensure(3 * pdfLineHeight)
+	d.tableHeader(columns)
+	for _, row := range rows {
+		if d.y < pdfMargin+pdfLineHeight {
+			d.addPage()
+			d.tableHeader(columns)
+		}
+		d.tableRow(columns, row, false)
+	}
+	d.y -= 4
+}
+
+// TableTotal writes a bold row under a rule, for the totals of a table
+func (d *PDFDocument) TableTotal(columns []PDFColumn, row []string) {
+	d.ensure(2 * pdfLineHeight)
+	d.line(pdfMargin, d.y+pdfLineHeight-3, pdfPageWidth-pdfMargin, d.y+pdfLineHeight-3)
+	d.tableRow(columns, row, true)
+}
+
+// WriteTo writes the finished document, pages are numbered here once their count is known
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3 and 4 fonts, 5 info, then a page and its content per page
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = strconv.Itoa(firstPage+2*i) + " 0 R"
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Title (" + pdfString(d.title) + ") /Producer (Reimbursement service) >>")
+
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		writePDFText(page, pdfMargin, pdfFooterY, 8, false, d.title)
+		writePDFText(page, pdfPageWidth-pdfMargin-pdfTextWidth(footer, 8, false), pdfFooterY, 8, false, footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+func (d *PDFDocument) addPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page unless height fits above the bottom margin
+func (d *PDFDocument) ensure(height float64) {
+	if d.y-height < pdfMargin {
+		d.addPage()
+	}
+}
+
+func (d *PDFDocument) tableHeader(columns []PDFColumn) {
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		cells[i] = column.Title
+	}
+	d.tableRow(columns, cells, true)
+	d.line(pdfMargin, d.y+pdfLineHeight-3, pdfPageWidth-pdfMargin, d.y+pdfLineHeight-3)
+}
+
+func (d *PDFDocument) tableRow(columns []PDFColumn, cells []string, bold bool) {
+	x := pdfMargin
+	for i, column := range columns {
+		if i < len(cells) && cells[i] != "" {
+			// a little padding keeps neighbouring cells apart
+			text := fitPDFText(cells[i], pdfTextSize, bold, column.Width-6)
+			if column.Right {
+				d.text(x+column.Width-pdfTextWidth(text, pdfTextSize, bold), d.y, pdfTextSize, bold, text)
+			} else {
+				d.text(x, d.y, pdfTextSize, bold, text)
+			}
+		}
+		x += column.Width
+	}
+	d.y -= pdfLineHeight
+}
+
+func (d *PDFDocument) text(x, y, size float64, bold bool, text string) {
+	writePDFText(d.page, x, y, size, bold, text)
+}
+
+func (d *PDFDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+func writePDFText(page *bytes.Buffer, x, y, size float64, bold bool, text string) {
+	font := pdfFontRegular
+	if bold {
+		font = pdfFontBold
+	}
+	fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// pdfWinAnsi encodes text for the WinAnsi fonts
+func pdfWinAnsi(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		case r == '\t' || r == '\n' || r == '\r':
+			encoded = append(encoded, ' ')
+		default:
+			if b, ok := pdfWinAnsiExtras[r]; ok {
+				encoded = append(encoded, b)
+			} else {
+				encoded = append(encoded, '?')
+			}
+		}
+	}
+	return encoded
+}
+
+// pdfString is text as the inside of a PDF literal string
+func pdfString(text string) string {
+	var escaped strings.Builder
+	for _, b := range pdfWinAnsi(text) {
+		if b == '\\' || b == '(' || b == ')' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(b)
+	}
+	return escaped.String()
+}
+
+// pdfTextWidth is the width of text in points, characters outside ASCII are
+// counted as wide as a digit
+func pdfTextWidth(text string, size float64, bold bool) float64 {
+	widths := &pdfHelveticaWidths
+	if bold {
+		widths = &pdfHelveticaBoldWidths
+	}
+	total := 0
+	for _, b := range pdfWinAnsi(text) {
+		if b >= 32 && b <= 126 {
+			total += widths[b-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// wrapPDFText breaks regular text into lines of at most width, a word longer than a
+// line is cut
+func wrapPDFText(text string, size, width float64) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && pdfTextWidth(line+" "+word, size, false) <= width {
+			line += " " + word
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = fitPDFText(word, size, false, width)
+	}
+	return append(lines, line)
+}
+
+// fitPDFText cuts text to width, ending it with three dots when cut
+func fitPDFText(text string, size float64, bold bool, width float64) string {
+	if pdfTextWidth(text, size, bold) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimRight(string(runes), " ") + "..."
+		if pdfTextWidth(cut, size, bold) <= width {
+			return cut
+		}
+	}
+	return ""
+}
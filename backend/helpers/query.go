@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+)
+
+const queryDateLayout = "2006-01-02"
+
+// GetTimeRange reads the from and to query parameters. Both accept RFC 3339 or a date,
+// from is inclusive and to exclusive, except that a date-only to covers that whole day.
+func GetTimeRange(c *gin.Context) (from, to *time.Time, err error) {
+	return GetTimeRangeOf(c, "from", "to")
+}
+
+// GetTimeRangeOf is GetTimeRange for differently named parameters
+func GetTimeRangeOf(c *gin.Context, fromParam, toParam string) (from, to *time.Time, err error) {
+	if value := c.Query(fromParam); value != "" {
+		at, _, err := parseQueryTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", fromParam, err)
+		}
+		from = &at
+	}
+	if value := c.Query(toParam); value != "" {
+		at, dateOnly, err := parseQueryTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", toParam, err)
+		}
+		if dateOnly {
+			at = at.AddDate(0, 0, 1)
+		}
+		to = &at
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, rules.ErrInvalidTimeRange
+	}
+	return from, to, nil
+}
+
+// Sort is a validated sort of a listing, Column is a trusted column expression
+type Sort struct {
+	Key    string
+	Column string
+	Desc   bool
+}
+
+// OrderBy sorts on Column and breaks ties on idColumn in the same direction. The tie
+// breaker keeps pages stable and gives every row a unique cursor position.
+func (s Sort) OrderBy(idColumn string) string {
+	direction := " ASC"
+	if s.Desc {
+		direction = " DESC"
+	}
+	return s.Column + direction + ", " + idColumn + direction
+}
+
+// GetSort reads the sort and order query parameters.
+// sort must be a key of columns, which maps it to a trusted column expression.
+func GetSort(c *gin.Context, columns map[string]string, defaultSort, defaultOrder string) (Sort, error) {
+	key := c.DefaultQuery("sort", defaultSort)
+	column, ok := columns[key]
+	if !ok {
+		return Sort{}, rules.ErrInvalidSortField
+	}
+
+	order := strings.ToLower(c.DefaultQuery("order", defaultOrder))
+	if order != "asc" && order != "desc" {
+		return Sort{}, rules.ErrInvalidSortOrder
+	}
+
+	return Sort{Key: key, Column: column, Desc: order == "desc"}, nil
+}
+
+// GetListQuery splits a comma separated parameter, status=pending,approved
+func GetListQuery(c *gin.Context, param string) []string {
+	var values []string
+	for _, value := range strings.Split(c.Query(param), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseQueryTime(value string) (time.Time, bool, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.UTC(), false, nil
+	}
+	if at, err := time.Parse(queryDateLayout, value); err == nil {
+		return at.UTC(), true, nil
+	}
+	return time.Time{}, false, rules.ErrInvalidTime
+}
@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSCIMFilter = errors.New("invalid SCIM filter")
+
+// SCIMColumn maps a SCIM attribute to a column. NullMeansTrue exposes a nullable
+// timestamp as a boolean, e.g. active is true while deactivated_at is NULL.
+type SCIMColumn struct {
+	Column        string
+	NullMeansTrue bool
+}
+
+// ParseSCIMFilter turns a SCIM filter such as `userName eq "bob@user.com" and active eq true`
+// into a SQL clause. Only "and" joined comparisons are supported (eq, ne, co, sw, ew, pr).
+// columns maps lower-cased SCIM attributes to columns, anything else is rejected.
+func ParseSCIMFilter(filter string, columns map[string]SCIMColumn) (string, []interface{}, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return "", nil, nil
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	for _, part := range splitSCIMAnd(filter) {
+		attr, op, value, err := scanSCIMComparison(part)
+		if err != nil {
+			return "", nil, err
+		}
+
+		mapping, ok := columns[strings.ToLower(attr)]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unsupported attribute %s", ErrInvalidSCIMFilter, attr)
+		}
+		column := mapping.Column
+
+		if mapping.NullMeansTrue {
+			b, ok := value.(bool)
+			if !ok || (op != "eq" && op != "ne") {
+				return "", nil, fmt.Errorf("%w: %s only supports eq/ne with a boolean", ErrInvalidSCIMFilter, attr)
+			}
+			if (op == "eq") == b {
+				clauses = append(clauses, column+" IS NULL")
+			} else {
+				clauses = append(clauses, column+" IS NOT NULL")
+			}
+			continue
+		}
+
+		switch op {
+		case "pr":
+			clauses = append(clauses, column+" IS NOT NULL")
+			continue
+		case "eq":
+			if value == nil {
+				clauses = append(clauses, column+" IS NULL")
+				continue
+			}
+			if s, ok := value.(string); ok {
+				clauses = append(clauses, "LOWER("+column+") = LOWER(?)")
+				args = append(args, s)
+				continue
+			}
+			clauses = append(clauses, column+" = ?")
+		case "ne":
+			if value == nil {
+				clauses = append(clauses, column+" IS NOT NULL")
+				continue
+			}
+			if s, ok := value.(string); ok {
+				clauses = append(clauses, "LOWER("+column+") <> LOWER(?)")
+				args = append(args, s)
+				continue
+			}
+			clauses = append(clauses, column+" <> ?")
+		case "co", "sw", "ew":
+			s, ok := value.(string)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %s needs a string", ErrInvalidSCIMFilter, op)
+			}
+			pattern := map[string]string{"co": "%" + s + "%", "sw": s + "%", "ew": "%" + s}[op]
+			clauses = append(clauses, "LOWER("+column+") LIKE LOWER(?)")
+			args = append(args, pattern)
+			continue
+		default:
+			return "", nil, fmt.Errorf("%w: unsupported operator %s", ErrInvalidSCIMFilter, op)
+		}
+		args = append(args, value)
+	}
+
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// splitSCIMAnd splits on " and " outside of quoted values
+func splitSCIMAnd(filter string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	lower := strings.ToLower(filter)
+
+	for i := 0; i < len(filter); i++ {
+		switch {
+		case filter[i] == '"' && (i == 0 || filter[i-1] != '\\'):
+			inQuotes = !inQuotes
+		case !inQuotes && strings.HasPrefix(lower[i:], " and "):
+			parts = append(parts, strings.TrimSpace(filter[start:i]))
+			start = i + len(" and ")
+			i = start - 1
+		}
+	}
+	return append(parts, strings.TrimSpace(filter[start:]))
+}
+
+func scanSCIMComparison(expr string) (attr, op string, value interface{}, err error) {
+	fields := strings.SplitN(expr, " ", 3)
+	if len(fields) == 2 && strings.EqualFold(fields[1], "pr") {
+		return fields[0], "pr", nil, nil
+	}
+	if len(fields) != 3 {
+		return "", "", nil, fmt.Errorf("%w: %q", ErrInvalidSCIMFilter, expr)
+	}
+
+	attr, op = fields[0], strings.ToLower(fields[1])
+	raw := strings.TrimSpace(fields[2])
+
+	switch {
+	case strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) && len(raw) >= 2:
+		unquoted, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("%w: %q", ErrInvalidSCIMFilter, raw)
+		}
+		return attr, op, unquoted, nil
+	case raw == "true" || raw == "false":
+		return attr, op, raw == "true", nil
+	case raw == "null":
+		return attr, op, nil, nil
+	}
+
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return attr, op, n, nil
+	}
+	return "", "", nil, fmt.Errorf("%w: %q", ErrInvalidSCIMFilter, raw)
+}
@@ -0,0 +1,29 @@
+package helpers
+
+import (
+	"time"
+
+	"backend/services"
+)
+
+const (
+	DefaultInviteTTL        = 72 * time.Hour
+	DefaultPasswordResetTTL = 30 * time.Minute
+)
+
+func InviteTTL() time.Duration {
+	return services.DurationFromEnv("INVITE_TTL", DefaultInviteTTL)
+}
+
+func PasswordResetTTL() time.Duration {
+	return services.DurationFromEnv("PASSWORD_RESET_TTL", DefaultPasswordResetTTL)
+}
+
+// NewSingleUseToken returns a token for an emailed link (invite, password reset) and the hash we store
+func NewSingleUseToken() (token, hash string, err error) {
+	token, err = services.RandomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
@@ -0,0 +1,130 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	AccessTokenCookie  = "token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFTokenCookie    = "csrf_token" // readable by the frontend, echoed in CSRFTokenHeader
+	CSRFTokenHeader    = "X-CSRF-Token"
+
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+func AccessTokenTTL() time.Duration {
+	return services.DurationFromEnv("ACCESS_TOKEN_TTL", DefaultAccessTokenTTL)
+}
+
+func RefreshTokenTTL() time.Duration {
+	return services.DurationFromEnv("REFRESH_TOKEN_TTL", DefaultRefreshTokenTTL)
+}
+
+// IssueAccessToken signs a short-lived token bound to a server-side session
+func IssueAccessToken(user *models.User, sessionID uuid.UUID) (string, error) {
+	keys := services.Keys()
+	if keys == nil {
+		return "", errors.New("signing keys not initialised")
+	}
+
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    user.Role,
+		"sid":     sessionID.String(),
+		"exp":     time.Now().Add(AccessTokenTTL()).Unix(),
+	}
+
+	return keys.Sign(claims)
+}
+
+func ParseAccessToken(tokenString string) (jwt.MapClaims, error) {
+	keys := services.Keys()
+	if keys == nil {
+		return nil, errors.New("signing keys not initialised")
+	}
+
+	token, err := keys.Parse(tokenString)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// NewRefreshToken returns an opaque token for the client and the hash stored server-side
+func NewRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// NewCSRFToken returns the synchronizer token of a session and the hash stored on it
+func NewCSRFToken() (token, hash string, err error) {
+	token, err = services.RandomURLToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+	return token, HashToken(token), nil
+}
+
+// CSRFTokenMatches checks the token a request echoed against the one of its session
+func CSRFTokenMatches(session models.UserSession, token string) bool {
+	return token != "" && session.CSRFTokenHash != nil &&
+		subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(*session.CSRFTokenHash)) == 1
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetAuthCookies writes the token cookies and the user info cookies read by the frontend
+func SetAuthCookies(c *gin.Context, user *models.User, accessToken, refreshToken string) {
+	accessMaxAge := int(AccessTokenTTL().Seconds())
+	sessionMaxAge := int(RefreshTokenTTL().Seconds())
+
+	c.SetCookie(AccessTokenCookie, accessToken, accessMaxAge, "/", "", false, true)
+	c.SetCookie(RefreshTokenCookie, refreshToken, sessionMaxAge, "/", "", false, true)
+
+	// Set user info cookies (accessible by frontend)
+	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("user_id=%d; Path=/; Max-Age=%d; SameSite=Lax", user.ID, sessionMaxAge))
+	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("role=%s; Path=/; Max-Age=%d; SameSite=Lax", user.Role, sessionMaxAge))
+	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("user_name=%s; Path=/; Max-Age=%d; SameSite=Lax", user.Name, sessionMaxAge))
+}
+
+// SetCSRFCookie hands the session's CSRF token to the frontend, which sends it back in CSRFTokenHeader
+func SetCSRFCookie(c *gin.Context, csrfToken string) {
+	c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("%s=%s; Path=/; Max-Age=%d; SameSite=Lax", CSRFTokenCookie, csrfToken, int(RefreshTokenTTL().Seconds())))
+}
+
+func ClearAuthCookies(c *gin.Context) {
+	c.SetCookie(AccessTokenCookie, "", -1, "/", "", false, true)
+	c.SetCookie(RefreshTokenCookie, "", -1, "/", "", false, true)
+
+	for _, name := range []string{"user_id", "role", "user_name", CSRFTokenCookie} {
+		c.Writer.Header().Add("Set-Cookie", fmt.Sprintf("%s=; Path=/; Max-Age=0; SameSite=Lax", name))
+	}
+}
@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"backend/services"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultMFATokenTTL = 5 * time.Minute
+	RecoveryCodeCount  = 10
+
+	mfaTokenType = "mfa"
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func MFATokenTTL() time.Duration {
+	return services.DurationFromEnv("MFA_TOKEN_TTL", DefaultMFATokenTTL)
+}
+
+func TOTPIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Expense Tracker"
+}
+
+// IssueMFAToken proves the password step of a login. It carries no session,
+// so JWTAuthMiddleware never accepts it as an access token.
+func IssueMFAToken(userID int64) (string, error) {
+	keys := services.Keys()
+	if keys == nil {
+		return "", errors.New("signing keys not initialised")
+	}
+
+	return keys.Sign(jwt.MapClaims{
+		"typ": mfaTokenType,
+		"sub": userID,
+		"exp": time.Now().Add(MFATokenTTL()).Unix(),
+	})
+}
+
+func ParseMFAToken(tokenString string) (int64, error) {
+	claims, err := ParseAccessToken(tokenString)
+	if err != nil {
+		return 0, err
+	}
+	if typ, _ := claims["typ"].(string); typ != mfaTokenType {
+		return 0, errors.New("not a two-factor login token")
+	}
+	userID, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, errors.New("invalid subject in token")
+	}
+	return int64(userID), nil
+}
+
+// SealTOTPSecret encrypts the seed before it is stored
+func SealTOTPSecret(secret string) (string, error) {
+	keys := services.Keys()
+	if keys == nil {
+		return "", errors.New("signing keys not initialised")
+	}
+	return keys.Seal([]byte(secret))
+}
+
+func OpenTOTPSecret(sealed string) (string, error) {
+	keys := services.Keys()
+	if keys == nil {
+		return "", errors.New("signing keys not initialised")
+	}
+	secret, err := keys.Open(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// NewRecoveryCodes returns codes formatted for the user (xxxxx-xxxxx) and the hashes we store
+func NewRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 6)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, HashRecoveryCode(raw))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode ignores case, spaces and dashes so codes can be typed loosely
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return HashToken(code)
+}
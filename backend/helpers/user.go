@@ -0,0 +1,136 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+
+	"backend/actions"
+	"backend/constants"
+	"backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxManagerChainDepth guards against cycles in the reporting lines
+const maxManagerChainDepth = 20
+
+// CurrentUser returns the user stored by JWTAuthMiddleware
+func CurrentUser(c *gin.Context) (models.User, bool) {
+	value, exists := c.Get("user")
+	if !exists {
+		return models.User{}, false
+	}
+	user, ok := value.(models.User)
+	return user, ok
+}
+
+// CurrentSession returns the session of the access token, stored by JWTAuthMiddleware
+func CurrentSession(c *gin.Context) (models.UserSession, bool) {
+	value, exists := c.Get("session")
+	if !exists {
+		return models.UserSession{}, false
+	}
+	session, ok := value.(models.UserSession)
+	return session, ok
+}
+
+// GetManagerChain returns the IDs of the user's superiors, nearest first
+func GetManagerChain(db *gorm.DB, userID int64) ([]int64, error) {
+	var chain []int64
+	seen := map[int64]bool{userID: true}
+
+	currentID := userID
+	for depth := 0; depth < maxManagerChainDepth; depth++ {
+		var user models.User
+		if err := db.Select("id", "manager_id").First(&user, currentID).Error; err != nil {
+			return nil, err
+		}
+		if user.ManagerID == nil || seen[*user.ManagerID] {
+			break
+		}
+
+		chain = append(chain, *user.ManagerID)
+		seen[*user.ManagerID] = true
+		currentID = *user.ManagerID
+	}
+
+	return chain, nil
+}
+
+// DeactivateUser blocks the user from authenticating, revokes their sessions and flags
+// their pending expenses for review. It is a no-op for users already deactivated.
+func DeactivateUser(db *gorm.DB, user *models.User, actorID *int64, at time.Time) error {
+	if !user.IsActive() {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("deactivated_at", at).Error; err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+
+		if err := RevokeUserSessions(tx, user.ID, at); err != nil {
+			return err
+		}
+
+		var pending []models.Expense
+		if err := tx.Where("user_id = ? AND status = ? AND flagged = ?", user.ID, constants.ExpenseStatusPending, false).
+			Find(&pending).Error; err != nil {
+			return fmt.Errorf("failed to load pending expenses: %w", err)
+		}
+
+		reason := "Submitter deactivated"
+		for _, expense := range pending {
+			if err := tx.Model(&models.Expense{}).Where("id = ?", expense.ID).
+				Updates(map[string]interface{}{"flagged": true, "flag_reason": reason}).Error; err != nil {
+				return fmt.Errorf("failed to flag expense %d: %w", expense.ID, err)
+			}
+
+			audit, err := actions.ExpenseAuditLog(actions.ExpenseAuditLogInput{
+				ExpenseID:  expense.ID,
+				ActorID:    actorID,
+				FromStatus: expense.Status,
+				ToStatus:   expense.Status,
+				Reason:     "Flagged: " + reason,
+			})
+			if err != nil {
+				return err
+			}
+			if err := AppendExpenseAuditLog(tx, audit); err != nil {
+				return fmt.Errorf("failed to record flag for expense %d: %w", expense.ID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	user.DeactivatedAt = &at
+	return nil
+}
+
+// RevokeUserSessions signs the user out everywhere, access tokens stop working right away
+func RevokeUserSessions(db *gorm.DB, userID int64, at time.Time) error {
+	if err := db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error; err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// ReactivateUser lets a deactivated user authenticate again, flagged expenses stay flagged
+func ReactivateUser(db *gorm.DB, user *models.User) error {
+	if user.IsActive() {
+		return nil
+	}
+	if err := db.Model(&models.User{}).Where("id = ?", user.ID).Update("deactivated_at", nil).Error; err != nil {
+		return err
+	}
+
+	user.DeactivatedAt = nil
+	return nil
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -11,7 +14,10 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"backend/db"
+	"backend/helpers"
 	"backend/routes"
+	"backend/services"
+	"backend/workers"
 
 	"github.com/joho/godotenv"
 )
@@ -33,6 +39,10 @@ import (
 // @securityDefinitions.apikey CookieAuth
 // @in cookie
 // @name access_token
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	if _, ok := os.LookupEnv("DATABASE_URL"); !ok {
 		envPath := filepath.Join("..", ".env") // adjust relative path
@@ -45,18 +55,41 @@ func main() {
 
 	db.Connect()
 
+	keys, err := services.InitKeySet(db.DB)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+	if _, err := services.LoadAuditAnchorKey(); err != nil {
+		log.Fatalf("Failed to load audit anchor key: %v", err)
+	}
+
+	// "./app verify-audit-log" checks the audit hash chain and exits, non-zero when it is broken
+	if len(os.Args) > 1 && os.Args[1] == "verify-audit-log" {
+		os.Exit(verifyAuditLog())
+	}
+
+	workers.NewKeyRotationWorker(keys).Start(context.Background())
+
+	// approval SLA reminders and escalation
+	workers.NewSLAWorker().Start(context.Background())
+
+	// periodic verification and anchoring of the audit hash chain
+	workers.NewAuditAnchorWorker().Start(context.Background())
+
 	router := gin.Default()
 
 	// CORS configuration
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{os.Getenv("NUXT_URL")},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Cookie"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Cookie", "X-CSRF-Token"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           24 * time.Hour,
 	}))
 
+	routes.WellKnownRoutes(&router.RouterGroup)
+
 	// API routes
 	apiGroup := router.Group("/api")
 	routes.AuthRoutes(apiGroup)
@@ -76,3 +109,19 @@ func main() {
 	}
 
 }
+
+func verifyAuditLog() int {
+	report, err := helpers.VerifyExpenseAuditChain(db.DB)
+	if err != nil {
+		log.Printf("Failed to verify audit log: %v", err)
+		return 2
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
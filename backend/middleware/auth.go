@@ -1,53 +1,68 @@
 package middleware
 
 import (
-	"errors"
 	"net/http"
-	"os"
+	"time"
 
 	"backend/db"
+	"backend/helpers"
 	"backend/models"
+	"backend/rules"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// AuthMethodCookie marks requests authenticated by the httpOnly token cookie, the only ones a
+	// browser attaches on its own and so the only ones exposed to cross-site request forgery
+	AuthMethodCookie = "cookie"
+	AuthMethodAPIKey = "api_key"
+)
+
+// JWTAuthMiddleware authenticates browser sessions by the token cookie and
+// service accounts by an API key in the Authorization header
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if header := c.GetHeader("Authorization"); header != "" {
+			authenticateAPIKey(c, header)
+			return
+		}
+
 		// Get token from cookie
-		cookie, err := c.Cookie("token")
+		cookie, err := c.Cookie(helpers.AccessTokenCookie)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing auth token"})
 			return
 		}
-		tokenString := cookie
 
 		// Parse token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			secret := os.Getenv("JWT_SECRET")
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := helpers.ParseAccessToken(cookie)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
+		// Extract user_id
+		userIDFloat, ok := claims["user_id"].(float64)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in token"})
 			return
 		}
 
-		// Extract user_id
-		userIDFloat, ok := claims["user_id"].(float64)
+		// Revoked or expired sessions lose access right away
+		sessionID, ok := claims["sid"].(string)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user_id in token"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid session in token"})
+			return
+		}
+
+		var session models.UserSession
+		if err := db.DB.First(&session, "uuid = ? AND user_id = ?", sessionID, int64(userIDFloat)).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session not found"})
+			return
+		}
+		if err := rules.ValidateSession(&session, time.Now().UTC()); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
@@ -56,6 +71,10 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			return
 		}
+		if err := rules.CanAuthenticate(&user); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
 
 		// Extract role (optional)
 		role, _ := claims["role"].(string) // silently skip if missing
@@ -64,6 +83,9 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", uint(userIDFloat))
 		c.Set("role", role)
 		c.Set("user", user)
+		c.Set("session_id", sessionID)
+		c.Set("session", session)
+		c.Set("auth_method", AuthMethodCookie)
 
 		c.Next()
 	}
@@ -80,3 +102,25 @@ func RequireRole(role string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireTwoFactor guards actions that move money, see rules.TwoFactorPolicy
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := helpers.CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+			return
+		}
+		// API keys have no session and so never pass a second factor
+		session, _ := helpers.CurrentSession(c)
+
+		if err := rules.LoadTwoFactorPolicy().CheckSession(&user, &session); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":              err.Error(),
+				"two_factor_enabled": user.HasTwoFactor(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"backend/helpers"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFMiddleware checks the X-CSRF-Token header of state-changing requests against the token
+// of the session (synchronizer pattern). Runs after JWTAuthMiddleware. Requests that are not
+// cookie-authenticated (API keys) are let through, every cookie request is checked whatever its path.
+func CSRFMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if c.GetString("auth_method") != AuthMethodCookie {
+			c.Next()
+			return
+		}
+
+		session, ok := helpers.CurrentSession(c)
+		if !ok || !helpers.CSRFTokenMatches(session, c.GetHeader(helpers.CSRFTokenHeader)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": rules.ErrInvalidCSRFToken.Error()})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SCIMAuthMiddleware authenticates the provisioning client with the static SCIM_BEARER_TOKEN.
+// SCIM is disabled when the variable is empty.
+func SCIMAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("SCIM_BEARER_TOKEN")
+		if expected == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "SCIM provisioning is not configured"})
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="scim"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid SCIM bearer token"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+-- --------------------
+-- Reporting lines for separation of duties
+-- --------------------
+ALTER TABLE users ADD COLUMN IF NOT EXISTS manager_id BIGINT NULL REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_users_manager_id ON users(manager_id);
+
+-- Employees report to Alice
+UPDATE users
+SET manager_id = (SELECT id FROM users WHERE email='alice@manager.com')
+WHERE role = 'user';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_manager_id;
+ALTER TABLE users DROP COLUMN IF EXISTS manager_id;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- --------------------
+-- Out-of-office approval delegation
+-- --------------------
+CREATE TABLE IF NOT EXISTS approval_delegations (
+    id BIGSERIAL PRIMARY KEY,
+    delegator_id BIGINT NOT NULL REFERENCES users(id),
+    delegate_id BIGINT NOT NULL REFERENCES users(id),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegator_id ON approval_delegations(delegator_id);
+CREATE INDEX IF NOT EXISTS idx_approval_delegations_delegate_id ON approval_delegations(delegate_id);
+
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS on_behalf_of_id BIGINT NULL REFERENCES users(id);
+ALTER TABLE expense_audit_logs ADD COLUMN IF NOT EXISTS on_behalf_of_id BIGINT NULL REFERENCES users(id);
+
+-- +goose Down
+ALTER TABLE expense_audit_logs DROP COLUMN IF EXISTS on_behalf_of_id;
+ALTER TABLE approvals DROP COLUMN IF EXISTS on_behalf_of_id;
+DROP TABLE IF EXISTS approval_delegations;
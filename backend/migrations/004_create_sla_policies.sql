@@ -0,0 +1,59 @@
+-- +goose Up
+-- --------------------
+-- Approval SLA policies and escalation tracking
+-- --------------------
+CREATE TABLE IF NOT EXISTS sla_policies (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    min_amount_idr BIGINT NOT NULL DEFAULT 0,
+    reminder_after_minutes INT NOT NULL,
+    escalate_after_minutes INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS sla_policy_id BIGINT NULL REFERENCES sla_policies(id);
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS assignee_id BIGINT NULL REFERENCES users(id);
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS reminder_due_at TIMESTAMP NULL;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalation_due_at TIMESTAMP NULL;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP NULL;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP NULL;
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS escalation_level INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_status_escalation_due_at ON approvals(status, escalation_due_at);
+
+-- -----------------------
+-- Seed policies: large expenses get a tighter SLA
+-- -----------------------
+INSERT INTO sla_policies (name, min_amount_idr, reminder_after_minutes, escalate_after_minutes)
+VALUES
+('Standard', 0, 1440, 2880),
+('Large expense', 10000000, 480, 1440);
+
+-- Backfill pending approvals with the matching policy and the submitter's manager
+UPDATE approvals a
+SET sla_policy_id = p.id,
+    assignee_id = u.manager_id,
+    reminder_due_at = a.created_at + make_interval(mins => p.reminder_after_minutes),
+    escalation_due_at = a.created_at + make_interval(mins => p.escalate_after_minutes)
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN LATERAL (
+    SELECT * FROM sla_policies sp
+    WHERE sp.min_amount_idr <= e.amount_idr
+    ORDER BY sp.min_amount_idr DESC
+    LIMIT 1
+) p ON true
+WHERE a.expense_id = e.id
+  AND a.status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_approvals_status_escalation_due_at;
+ALTER TABLE approvals DROP COLUMN IF EXISTS escalation_level;
+ALTER TABLE approvals DROP COLUMN IF EXISTS escalated_at;
+ALTER TABLE approvals DROP COLUMN IF EXISTS reminded_at;
+ALTER TABLE approvals DROP COLUMN IF EXISTS escalation_due_at;
+ALTER TABLE approvals DROP COLUMN IF EXISTS reminder_due_at;
+ALTER TABLE approvals DROP COLUMN IF EXISTS assignee_id;
+ALTER TABLE approvals DROP COLUMN IF EXISTS sla_policy_id;
+DROP TABLE IF EXISTS sla_policies;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- --------------------
+-- Asymmetric JWT signing keys, private keys are sealed by the application
+-- --------------------
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id BIGSERIAL PRIMARY KEY,
+    kid VARCHAR(64) UNIQUE NOT NULL,
+    algorithm VARCHAR(16) NOT NULL,
+    public_key TEXT NOT NULL,
+    private_key TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_status ON signing_keys(status);
+
+-- +goose Down
+DROP TABLE IF EXISTS signing_keys;
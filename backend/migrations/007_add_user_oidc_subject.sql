@@ -0,0 +1,8 @@
+-- +goose Up
+-- --------------------
+-- Link users to their identity provider subject for single sign-on
+-- --------------------
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_subject VARCHAR(255) NULL UNIQUE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS oidc_subject;
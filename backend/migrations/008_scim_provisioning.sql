@@ -0,0 +1,36 @@
+-- +goose Up
+-- --------------------
+-- SCIM provisioning: deactivation, groups and flagged expenses
+-- --------------------
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id VARCHAR(255) NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE TABLE IF NOT EXISTS user_groups (
+    id BIGSERIAL PRIMARY KEY,
+    display_name VARCHAR(255) UNIQUE NOT NULL,
+    external_id VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_group_members (
+    group_id BIGINT NOT NULL REFERENCES user_groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    PRIMARY KEY (group_id, user_id)
+);
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS flag_reason TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_group_members_user_id ON user_group_members(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_group_members_user_id;
+ALTER TABLE expenses DROP COLUMN IF EXISTS flag_reason;
+ALTER TABLE expenses DROP COLUMN IF EXISTS flagged;
+DROP TABLE IF EXISTS user_group_members;
+DROP TABLE IF EXISTS user_groups;
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- --------------------
+-- Invite-based signup, only the token hash is stored
+-- --------------------
+CREATE TABLE IF NOT EXISTS user_invites (
+    id BIGSERIAL PRIMARY KEY,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role TEXT NOT NULL DEFAULT 'user',
+    manager_id BIGINT NULL REFERENCES users(id),
+    invited_by_id BIGINT NOT NULL REFERENCES users(id),
+    user_id BIGINT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_invites_email ON user_invites(email);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_invites;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- --------------------
+-- TOTP second factor, the seed is sealed with the key encryption secret
+-- --------------------
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_counter BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS two_factor_verified_at TIMESTAMP NULL;
+
+-- one-time codes for a lost authenticator, only the hash is stored
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_recovery_codes;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS two_factor_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_counter;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
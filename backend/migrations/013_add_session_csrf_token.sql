@@ -0,0 +1,9 @@
+-- +goose Up
+-- --------------------
+-- Synchronizer CSRF token of each session, only the hash is stored.
+-- Sessions created before this migration have none and must sign in again.
+-- --------------------
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS csrf_token_hash VARCHAR(64) NULL;
+
+-- +goose Down
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS csrf_token_hash;
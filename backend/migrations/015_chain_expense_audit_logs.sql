@@ -0,0 +1,58 @@
+-- +goose Up
+-- --------------------
+-- Hash chain over the expense audit log. Every entry stores the hash of its own
+-- content and of the entry before it, so editing, deleting or inserting a row
+-- breaks every later link. Rows written before this migration stay unchained.
+-- --------------------
+ALTER TABLE expense_audit_logs ADD COLUMN IF NOT EXISTS seq BIGINT NULL;
+ALTER TABLE expense_audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64) NULL;
+ALTER TABLE expense_audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64) NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_audit_logs_seq ON expense_audit_logs(seq);
+
+-- last link of each chain, the row lock serialises appends
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+    chain VARCHAR(64) PRIMARY KEY,
+    seq BIGINT NOT NULL DEFAULT 0,
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO audit_chain_heads (chain) VALUES ('expense_audit_logs') ON CONFLICT DO NOTHING;
+
+-- chain heads signed with the active signing key at regular intervals
+CREATE TABLE IF NOT EXISTS audit_anchors (
+    id BIGSERIAL PRIMARY KEY,
+    chain VARCHAR(64) NOT NULL,
+    seq BIGINT NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_anchors_chain_seq ON audit_anchors(chain, seq);
+
+-- the application only ever appends, edits fail loudly. The table owner can still drop
+-- the trigger, the hash chain is what catches that.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION expense_audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'expense_audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_expense_audit_logs_append_only ON expense_audit_logs;
+CREATE TRIGGER trg_expense_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON expense_audit_logs
+    FOR EACH ROW EXECUTE FUNCTION expense_audit_logs_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_expense_audit_logs_append_only ON expense_audit_logs;
+DROP FUNCTION IF EXISTS expense_audit_logs_append_only();
+DROP TABLE IF EXISTS audit_anchors;
+DROP TABLE IF EXISTS audit_chain_heads;
+DROP INDEX IF EXISTS idx_expense_audit_logs_seq;
+ALTER TABLE expense_audit_logs DROP COLUMN IF EXISTS hash;
+ALTER TABLE expense_audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE expense_audit_logs DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- --------------------
+-- General audit trail: logins, edits of users, approvals, policies and delegations,
+-- credential changes and payment retries. Changes keep only the fields that changed.
+-- --------------------
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT NULL REFERENCES users(id),
+    api_key_id BIGINT NULL REFERENCES api_keys(id),
+    action VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(64) NOT NULL DEFAULT '',
+    changes TEXT NULL,  -- JSON object of {"field": {"before": ..., "after": ...}}
+    metadata TEXT NULL, -- JSON object
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- --------------------
+-- Expense search: a category per expense, a full-text vector over the description with
+-- trigram matching for partial words, and indexes behind the manager list filters and sorts.
+-- --------------------
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS category VARCHAR(32) NOT NULL DEFAULT 'other';
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(description, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_expenses_search_vector ON expenses USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_expenses_description_trgm ON expenses USING GIN (description gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_expenses_category ON expenses(category);
+CREATE INDEX IF NOT EXISTS idx_expenses_amount_idr ON expenses(amount_idr);
+CREATE INDEX IF NOT EXISTS idx_expenses_submitted_at ON expenses(submitted_at);
+CREATE INDEX IF NOT EXISTS idx_expenses_updated_at ON expenses(updated_at);
+CREATE INDEX IF NOT EXISTS idx_approvals_approver_id ON approvals(approver_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_assignee_id ON approvals(assignee_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_approvals_assignee_id;
+DROP INDEX IF EXISTS idx_approvals_approver_id;
+DROP INDEX IF EXISTS idx_expenses_updated_at;
+DROP INDEX IF EXISTS idx_expenses_submitted_at;
+DROP INDEX IF EXISTS idx_expenses_amount_idr;
+DROP INDEX IF EXISTS idx_expenses_category;
+DROP INDEX IF EXISTS idx_expenses_description_trgm;
+DROP INDEX IF EXISTS idx_expenses_search_vector;
+ALTER TABLE expenses DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE expenses DROP COLUMN IF EXISTS category;
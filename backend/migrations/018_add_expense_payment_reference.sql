@@ -0,0 +1,9 @@
+-- +goose Up
+-- --------------------
+-- The payment processor's id of the payment behind a completed expense, printed on
+-- statements and vouchers. Expenses paid before it was kept are left NULL.
+-- --------------------
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS payment_reference VARCHAR(128);
+
+-- +goose Down
+ALTER TABLE expenses DROP COLUMN IF EXISTS payment_reference;
@@ -0,0 +1,106 @@
+-- +goose Up
+-- --------------------
+-- Double-entry ledger of reimbursements. Approving an expense debits the expense account
+-- and credits the employee's payable, paying it debits the payable and credits cash.
+-- Lines are one-sided and every entry balances, checked when the transaction commits.
+-- --------------------
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    expense_id BIGINT NULL REFERENCES expenses(id),
+    kind VARCHAR(32) NOT NULL,
+    memo TEXT NOT NULL DEFAULT '',
+    posted_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- an expense is approved and settled once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journal_entries_expense_kind ON journal_entries(expense_id, kind);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_posted_at ON journal_entries(posted_at);
+
+CREATE TABLE IF NOT EXISTS journal_lines (
+    id BIGSERIAL PRIMARY KEY,
+    journal_entry_id BIGINT NOT NULL REFERENCES journal_entries(id),
+    account VARCHAR(16) NOT NULL,
+    user_id BIGINT NULL REFERENCES users(id),
+    debit_idr BIGINT NOT NULL DEFAULT 0,
+    credit_idr BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT chk_journal_lines_one_side CHECK (debit_idr >= 0 AND credit_idr >= 0 AND (debit_idr = 0) <> (credit_idr = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_lines_entry ON journal_lines(journal_entry_id);
+CREATE INDEX IF NOT EXISTS idx_journal_lines_account_user ON journal_lines(account, user_id);
+
+-- opening entries for expenses approved or paid before the ledger, dated by the approval
+-- in the audit log (submission for auto-approved ones) and by the payment
+INSERT INTO journal_entries (expense_id, kind, memo, posted_at)
+SELECT e.id, 'approval', 'Expense #' || e.id || ' approved: ' || e.description,
+       COALESCE((SELECT MIN(l.created_at) FROM expense_audit_logs l WHERE l.expense_id = e.id AND l.to_status = 'approved'), e.submitted_at)
+FROM expenses e
+WHERE e.status IN ('approved', 'completed')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO journal_entries (expense_id, kind, memo, posted_at)
+SELECT e.id, 'settlement', 'Expense #' || e.id || ' paid' || COALESCE(', payment ' || e.payment_reference, ''),
+       COALESCE(e.processed_at, e.updated_at)
+FROM expenses e
+WHERE e.status = 'completed'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO journal_lines (journal_entry_id, account, user_id, debit_idr, credit_idr)
+SELECT j.id, '6100', e.user_id, e.amount_idr, 0 FROM journal_entries j JOIN expenses e ON e.id = j.expense_id WHERE j.kind = 'approval'
+UNION ALL
+SELECT j.id, '2100', e.user_id, 0, e.amount_idr FROM journal_entries j JOIN expenses e ON e.id = j.expense_id WHERE j.kind = 'approval'
+UNION ALL
+SELECT j.id, '2100', e.user_id, e.amount_idr, 0 FROM journal_entries j JOIN expenses e ON e.id = j.expense_id WHERE j.kind = 'settlement'
+UNION ALL
+SELECT j.id, '1000', NULL, 0, e.amount_idr FROM journal_entries j JOIN expenses e ON e.id = j.expense_id WHERE j.kind = 'settlement';
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION journal_lines_balanced() RETURNS trigger AS $$
+DECLARE
+    difference BIGINT;
+BEGIN
+    SELECT COALESCE(SUM(debit_idr), 0) - COALESCE(SUM(credit_idr), 0) INTO difference
+    FROM journal_lines WHERE journal_entry_id = NEW.journal_entry_id;
+    IF difference <> 0 THEN
+        RAISE EXCEPTION 'journal entry % does not balance, debits exceed credits by %', NEW.journal_entry_id, difference;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- deferred so the lines of an entry are checked together at commit
+DROP TRIGGER IF EXISTS trg_journal_lines_balanced ON journal_lines;
+CREATE CONSTRAINT TRIGGER trg_journal_lines_balanced
+    AFTER INSERT ON journal_lines
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION journal_lines_balanced();
+
+-- posted entries are final, a correction is a new entry
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_journal_entries_append_only ON journal_entries;
+CREATE TRIGGER trg_journal_entries_append_only
+    BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+DROP TRIGGER IF EXISTS trg_journal_lines_append_only ON journal_lines;
+CREATE TRIGGER trg_journal_lines_append_only
+    BEFORE UPDATE OR DELETE ON journal_lines
+    FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_journal_lines_append_only ON journal_lines;
+DROP TRIGGER IF EXISTS trg_journal_entries_append_only ON journal_entries;
+DROP TRIGGER IF EXISTS trg_journal_lines_balanced ON journal_lines;
+DROP FUNCTION IF EXISTS ledger_append_only();
+DROP FUNCTION IF EXISTS journal_lines_balanced();
+DROP TABLE IF EXISTS journal_lines;
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- --------------------
+-- Accounting exports: the GL account each expense category is booked to, categories
+-- without a row use the defaults in constants.DefaultGLAccounts, and the batches of
+-- completed expenses handed to accounting. An expense is exported once, with the
+-- accounts it was booked to so a later mapping change does not alter the download.
+-- --------------------
+CREATE TABLE IF NOT EXISTS gl_account_mappings (
+    category VARCHAR(32) PRIMARY KEY,
+    account_code VARCHAR(64) NOT NULL,
+    account_name VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS accounting_exports (
+    id BIGSERIAL PRIMARY KEY,
+    format VARCHAR(16) NOT NULL,
+    actor_id BIGINT NULL REFERENCES users(id),
+    paid_from TIMESTAMP NULL,
+    paid_to TIMESTAMP NULL,
+    expense_count BIGINT NOT NULL DEFAULT 0,
+    total_idr BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS accounting_export_items (
+    id BIGSERIAL PRIMARY KEY,
+    accounting_export_id BIGINT NOT NULL REFERENCES accounting_exports(id),
+    expense_id BIGINT NOT NULL REFERENCES expenses(id),
+    debit_account_code VARCHAR(64) NOT NULL,
+    debit_account_name VARCHAR(255) NOT NULL,
+    credit_account_code VARCHAR(64) NOT NULL,
+    credit_account_name VARCHAR(255) NOT NULL,
+    CONSTRAINT uq_accounting_export_items_expense UNIQUE (expense_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounting_export_items_export ON accounting_export_items(accounting_export_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS accounting_export_items;
+DROP TABLE IF EXISTS accounting_exports;
+DROP TABLE IF EXISTS gl_account_mappings;
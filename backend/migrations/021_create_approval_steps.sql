@@ -0,0 +1,24 @@
+-- +goose Up
+-- --------------------
+-- Expenses at or above the second approval threshold are approved by two managers.
+-- approvals.step is the step waiting for a decision, approval_steps keeps who approved
+-- the earlier ones so separation of duties can keep them off the next step.
+-- --------------------
+ALTER TABLE approvals ADD COLUMN IF NOT EXISTS step INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS approval_steps (
+    id BIGSERIAL PRIMARY KEY,
+    approval_id BIGINT NOT NULL REFERENCES approvals(id),
+    step INT NOT NULL,
+    approver_id BIGINT NOT NULL REFERENCES users(id),
+    on_behalf_of_id BIGINT NULL REFERENCES users(id),
+    notes TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP NOT NULL
+);
+
+-- each step is approved once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_approval_steps_approval_step ON approval_steps(approval_id, step);
+
+-- +goose Down
+DROP TABLE IF EXISTS approval_steps;
+ALTER TABLE approvals DROP COLUMN IF EXISTS step;
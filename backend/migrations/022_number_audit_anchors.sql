@@ -0,0 +1,18 @@
+-- +goose Up
+-- --------------------
+-- Anchors were signed with the rotating JWT keys and could no longer be verified once
+-- the key was retired. They are now signed with a dedicated key (AUDIT_ANCHOR_KEY) and
+-- numbered per chain, a removed anchor shows as a gap. The old anchors cannot be re-signed
+-- here, they are kept aside for reference and the worker anchors the head on its next run.
+-- --------------------
+CREATE TABLE IF NOT EXISTS audit_anchors_jwt AS SELECT * FROM audit_anchors;
+DELETE FROM audit_anchors;
+
+ALTER TABLE audit_anchors ADD COLUMN IF NOT EXISTS number BIGINT NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_anchors_chain_number ON audit_anchors(chain, number);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_audit_anchors_chain_number;
+ALTER TABLE audit_anchors DROP COLUMN IF EXISTS number;
+DROP TABLE IF EXISTS audit_anchors_jwt;
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+// GLAccountMapping books the expenses of a category to an account of the accounting software
+type GLAccountMapping struct {
+	Category    constants.ExpenseCategory `json:"category" gorm:"primaryKey;type:text"`
+	AccountCode string                    `json:"account_code" example:"6110"`
+	AccountName string                    `json:"account_name" example:"Travel"` // QuickBooks matches accounts by name
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+func (GLAccountMapping) TableName() string {
+	return "gl_account_mappings"
+}
+
+// AccountingExport is a batch of completed expenses handed to accounting. Every expense
+// belongs to one export at most, so a batch never repeats an expense of an earlier one.
+type AccountingExport struct {
+	ID           int64                      `json:"id" gorm:"primaryKey"`
+	Format       constants.AccountingFormat `json:"format" gorm:"type:text"`
+	ActorID      *int64                     `json:"actor_id"`
+	PaidFrom     *time.Time                 `json:"paid_from"`
+	PaidTo       *time.Time                 `json:"paid_to"`
+	ExpenseCount int64                      `json:"expense_count"`
+	TotalIDR     int64                      `json:"total_idr" gorm:"column:total_idr"`
+	CreatedAt    time.Time                  `json:"created_at"`
+}
+
+// AccountingExportItem is an expense of an export with the accounts it was booked to
+// when the export was created, downloads repeat them even after the mapping changed
+type AccountingExportItem struct {
+	ID                 int64  `json:"id" gorm:"primaryKey"`
+	AccountingExportID int64  `json:"accounting_export_id" gorm:"index"`
+	ExpenseID          int64  `json:"expense_id" gorm:"uniqueIndex"`
+	DebitAccountCode   string `json:"debit_account_code" example:"6110"`
+	DebitAccountName   string `json:"debit_account_name" example:"Travel"`
+	CreditAccountCode  string `json:"credit_account_code" example:"1010"`
+	CreditAccountName  string `json:"credit_account_name" example:"Operating Bank Account"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+type APIKey struct {
+	ID          int64                `json:"id" gorm:"primaryKey"`
+	UserID      int64                `json:"user_id"` // the service account
+	Name        string               `json:"name"`
+	Prefix      string               `json:"prefix"`               // first characters of the key, to recognise it
+	KeyHash     string               `json:"-" gorm:"uniqueIndex"` // sha256 of the key, which is shown once
+	Scopes      []constants.APIScope `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt   *time.Time           `json:"expires_at"`
+	LastUsedAt  *time.Time           `json:"last_used_at"`
+	LastUsedIP  string               `json:"last_used_ip"`
+	RevokedAt   *time.Time           `json:"revoked_at"`
+	CreatedByID *int64               `json:"created_by_id"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (k *APIKey) HasScope(scope constants.APIScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AuditChainHead is the last link of a hash chained log
+type AuditChainHead struct {
+	Chain     string    `json:"chain" gorm:"primaryKey"`
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AuditAnchor is a chain head signed with the audit anchor key, a rewritten
+// chain no longer matches the anchors taken before the rewrite
+type AuditAnchor struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	Chain     string    `json:"chain"`
+	Number    int64     `json:"number"` // 1, 2, 3... per chain, a gap is a removed anchor
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	Signature string    `json:"signature"` // Ed25519 over chain, number, seq, hash and created_at
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+// AuditEvent records who did what to which entity, beyond the status
+// transitions kept in ExpenseAuditLog
+type AuditEvent struct {
+	ID         int64                     `json:"id" gorm:"primaryKey"`
+	ActorID    *int64                    `json:"actor_id"`   // nil for background workers and SCIM
+	APIKeyID   *int64                    `json:"api_key_id"` // set when the actor used an API key
+	Action     constants.AuditAction     `json:"action" gorm:"type:text"`
+	EntityType constants.AuditEntityType `json:"entity_type" gorm:"type:text"`
+	EntityID   string                    `json:"entity_id"`
+	Changes    map[string]AuditChange    `json:"changes" gorm:"serializer:json"` // changed fields only
+	Metadata   map[string]interface{}    `json:"metadata" gorm:"serializer:json"`
+	IPAddress  string                    `json:"ip_address"`
+	UserAgent  string                    `json:"user_agent"`
+	CreatedAt  time.Time                 `json:"created_at"`
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
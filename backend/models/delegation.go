@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type ApprovalDelegation struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	DelegatorID int64      `json:"delegator_id"`
+	DelegateID  int64      `json:"delegate_id"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	Reason      string     `json:"reason"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Delegator *User `json:"delegator,omitempty" gorm:"foreignKey:DelegatorID;references:ID"`
+	Delegate  *User `json:"delegate,omitempty" gorm:"foreignKey:DelegateID;references:ID"`
+}
@@ -8,32 +8,61 @@ import (
 )
 
 type Expense struct {
-	ID               int64                   `json:"id" gorm:"primaryKey"`
-	UUID             uuid.UUID               `json:"uuid" gorm:"type:uuid"`
-	UserID           int64                   `json:"user_id"`
-	AmountIDR        int64                   `json:"amount_idr" gorm:"column:amount_idr"`
-	Description      string                  `json:"description"`
-	ReceiptURL       string                  `json:"receipt_url"`
-	Status           constants.ExpenseStatus `json:"status" gorm:"type:text"`
-	RequiresApproval bool                    `json:"requires_approval"`
-	AutoApproved     bool                    `json:"auto_approved"`
-	CreatedAt        time.Time               `json:"created_at"`
-	UpdatedAt        time.Time               `json:"updated_at"`
-	SubmittedAt      time.Time               `json:"submitted_at"`
-	ProcessedAt      *time.Time              `json:"processed_at"`
+	ID               int64                     `json:"id" gorm:"primaryKey"`
+	UUID             uuid.UUID                 `json:"uuid" gorm:"type:uuid"`
+	UserID           int64                     `json:"user_id"`
+	AmountIDR        int64                     `json:"amount_idr" gorm:"column:amount_idr"`
+	Description      string                    `json:"description"`
+	Category         constants.ExpenseCategory `json:"category" gorm:"type:text;default:other"`
+	ReceiptURL       string                    `json:"receipt_url"`
+	Status           constants.ExpenseStatus   `json:"status" gorm:"type:text"`
+	RequiresApproval bool                      `json:"requires_approval"`
+	AutoApproved     bool                      `json:"auto_approved"`
+	Flagged          bool                      `json:"flagged"` // needs attention, e.g. the submitter was deactivated
+	FlagReason       *string                   `json:"flag_reason"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	UpdatedAt        time.Time                 `json:"updated_at"`
+	SubmittedAt      time.Time                 `json:"submitted_at"`
+	ProcessedAt      *time.Time                `json:"processed_at"`
+	PaymentReference *string                   `json:"payment_reference"` // the payment processor's id of the payment
 
 	User     *User     `json:"user" gorm:"foreignKey:UserID;references:ID"`
 	Approval *Approval `json:"approval" gorm:"foreignKey:ExpenseID"`
 }
 
 type Approval struct {
-	ID         int64                    `json:"id" gorm:"primaryKey"`
-	ExpenseID  int64                    `json:"expense_id"`
-	ApproverID *int64                   `json:"approver_id"`
-	Status     constants.ApprovalStatus `json:"status" gorm:"type:text"`
-	Notes      string                   `json:"notes"`
-	CreatedAt  time.Time                `json:"created_at"`
-	UpdatedAt  time.Time                `json:"updated_at"`
+	ID           int64                    `json:"id" gorm:"primaryKey"`
+	ExpenseID    int64                    `json:"expense_id"`
+	ApproverID   *int64                   `json:"approver_id"`
+	OnBehalfOfID *int64                   `json:"on_behalf_of_id"` // set when a delegate decided for the approver
+	Status       constants.ApprovalStatus `json:"status" gorm:"type:text"`
+	Notes        string                   `json:"notes"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+
+	// SLA tracking, the assignee moves up the manager chain on escalation
+	SLAPolicyID     *int64     `json:"sla_policy_id" gorm:"column:sla_policy_id"`
+	AssigneeID      *int64     `json:"assignee_id"`
+	ReminderDueAt   *time.Time `json:"reminder_due_at"`
+	EscalationDueAt *time.Time `json:"escalation_due_at"`
+	RemindedAt      *time.Time `json:"reminded_at"`
+	EscalatedAt     *time.Time `json:"escalated_at"`
+	EscalationLevel int        `json:"escalation_level"`
+
+	// step waiting for a decision, see rules.RequiredApprovalSteps
+	Step  int            `json:"step" gorm:"not null;default:1"`
+	Steps []ApprovalStep `json:"steps,omitempty" gorm:"foreignKey:ApprovalID"`
 
 	Expense Expense `json:"-" gorm:"foreignKey:ExpenseID"`
 }
+
+// ApprovalStep records the approval of an earlier step of a multi-step approval
+type ApprovalStep struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	ApprovalID   int64     `json:"approval_id"`
+	Step         int       `json:"step"`
+	ApproverID   int64     `json:"approver_id"`
+	OnBehalfOfID *int64    `json:"on_behalf_of_id"` // set when a delegate approved for the approver
+	Notes        string    `json:"notes"`
+	DecidedAt    time.Time `json:"decided_at"`
+}
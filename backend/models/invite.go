@@ -0,0 +1,21 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+type UserInvite struct {
+	ID          int64              `json:"id" gorm:"primaryKey"`
+	TokenHash   string             `json:"-" gorm:"uniqueIndex"` // sha256 of the token sent to the invitee
+	Email       string             `json:"email"`
+	Role        constants.UserRole `json:"role"`
+	ManagerID   *int64             `json:"manager_id"`
+	InvitedByID int64              `json:"invited_by_id"`
+	UserID      *int64             `json:"user_id"` // account created from the invite
+	ExpiresAt   time.Time          `json:"expires_at"`
+	UsedAt      *time.Time         `json:"used_at"`
+	RevokedAt   *time.Time         `json:"revoked_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+// JournalEntry is one balanced posting to the ledger. Entries are never edited, a
+// correction is posted as a new entry.
+type JournalEntry struct {
+	ID        int64                      `json:"id" gorm:"primaryKey"`
+	ExpenseID *int64                     `json:"expense_id" gorm:"uniqueIndex:idx_journal_entries_expense_kind"`
+	Kind      constants.JournalEntryKind `json:"kind" gorm:"type:text;uniqueIndex:idx_journal_entries_expense_kind"`
+	Memo      string                     `json:"memo"`
+	PostedAt  time.Time                  `json:"posted_at"`
+	CreatedAt time.Time                  `json:"created_at"`
+
+	Lines []JournalLine `json:"lines" gorm:"foreignKey:JournalEntryID"`
+}
+
+// JournalLine debits or credits one account, never both
+type JournalLine struct {
+	ID             int64                   `json:"id" gorm:"primaryKey"`
+	JournalEntryID int64                   `json:"journal_entry_id"`
+	Account        constants.LedgerAccount `json:"account" gorm:"type:text"`
+	UserID         *int64                  `json:"user_id"` // the employee the amount belongs to
+	DebitIDR       int64                   `json:"debit_idr" gorm:"column:debit_idr"`
+	CreditIDR      int64                   `json:"credit_idr" gorm:"column:credit_idr"`
+}
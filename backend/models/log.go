@@ -6,11 +6,15 @@ import (
 )
 
 type ExpenseAuditLog struct {
-	ID         int64                   `json:"id"`
-	ExpenseID  int64                   `json:"expense_id"`
-	ActorID    *int64                  `json:"actor_id"`
-	FromStatus constants.ExpenseStatus `json:"from_status"`
-	ToStatus   constants.ExpenseStatus `json:"to_status"`
-	Reason     string                  `json:"reason"`
-	CreatedAt  time.Time               `json:"created_at"`
+	ID           int64                   `json:"id"`
+	ExpenseID    int64                   `json:"expense_id"`
+	ActorID      *int64                  `json:"actor_id"`
+	OnBehalfOfID *int64                  `json:"on_behalf_of_id"`
+	FromStatus   constants.ExpenseStatus `json:"from_status"`
+	ToStatus     constants.ExpenseStatus `json:"to_status"`
+	Reason       string                  `json:"reason"`
+	Seq          *int64                  `json:"seq" gorm:"uniqueIndex"` // position in the hash chain, nil for entries written before it
+	PrevHash     *string                 `json:"prev_hash"`
+	Hash         *string                 `json:"hash"` // sha256 of the entry and PrevHash
+	CreatedAt    time.Time               `json:"created_at"`
 }
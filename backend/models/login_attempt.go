@@ -0,0 +1,17 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+// LoginAttempt is the login audit trail, failed rows also drive the brute-force counters
+type LoginAttempt struct {
+	ID        int64                 `json:"id" gorm:"primaryKey"`
+	UserID    *int64                `json:"user_id"` // nil when the email is unknown
+	Email     string                `json:"email"`   // as typed, lower-cased
+	IPAddress string                `json:"ip_address"`
+	UserAgent string                `json:"user_agent"`
+	Result    constants.LoginResult `json:"result"`
+	CreatedAt time.Time             `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type PasswordResetToken struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	UserID      int64      `json:"user_id"`
+	TokenHash   string     `json:"-" gorm:"uniqueIndex"` // sha256 of the token sent by email
+	RequestedIP string     `json:"requested_ip"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
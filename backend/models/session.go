@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserSession struct {
+	ID                  int64      `json:"id" gorm:"primaryKey"`
+	UUID                uuid.UUID  `json:"uuid" gorm:"type:uuid"` // carried as the sid claim of access tokens
+	UserID              int64      `json:"user_id"`
+	RefreshTokenHash    string     `json:"-"`
+	PreviousTokenHash   *string    `json:"-"`                               // last rotated token, presenting it again means the token leaked
+	CSRFTokenHash       *string    `json:"-" gorm:"column:csrf_token_hash"` // must match the X-CSRF-Token header of mutating requests
+	UserAgent           string     `json:"user_agent"`
+	IPAddress           string     `json:"ip_address"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	LastUsedAt          time.Time  `json:"last_used_at"`
+	RevokedAt           *time.Time `json:"revoked_at"`
+	TwoFactorVerifiedAt *time.Time `json:"two_factor_verified_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"backend/constants"
+	"time"
+)
+
+type SigningKey struct {
+	ID         int64                      `json:"id" gorm:"primaryKey"`
+	KID        string                     `json:"kid" gorm:"column:kid;uniqueIndex"`
+	Algorithm  string                     `json:"algorithm"`
+	PublicKey  string                     `json:"public_key"` // PEM encoded PKIX
+	PrivateKey string                     `json:"-"`          // sealed PKCS8, see services.KeySet
+	Status     constants.SigningKeyStatus `json:"status" gorm:"type:text"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	RetiredAt  *time.Time                 `json:"retired_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type SLAPolicy struct {
+	ID                   int64     `json:"id" gorm:"primaryKey"`
+	Name                 string    `json:"name"`
+	MinAmountIDR         int64     `json:"min_amount_idr" gorm:"column:min_amount_idr"` // applies to expenses at or above this amount
+	ReminderAfterMinutes int       `json:"reminder_after_minutes"`
+	EscalateAfterMinutes int       `json:"escalate_after_minutes"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+func (SLAPolicy) TableName() string {
+	return "sla_policies"
+}
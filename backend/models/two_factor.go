@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type UserRecoveryCode struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int64      `json:"user_id"`
+	CodeHash  string     `json:"-"` // sha256 of the code shown once at enrolment
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
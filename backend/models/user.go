@@ -6,10 +6,42 @@ import (
 )
 
 type User struct {
-	ID           int64              `json:"id" gorm:"primaryKey"`
-	Email        string             `json:"email" gorm:"uniqueIndex"`
-	Name         string             `json:"name"`
-	Role         constants.UserRole `json:"role"` // "user" or "manager"
-	PasswordHash string             `json:"-"`    // Never send to frontend
-	CreatedAt    time.Time          `json:"created_at"`
+	ID                int64              `json:"id" gorm:"primaryKey"`
+	Email             string             `json:"email" gorm:"uniqueIndex"`
+	Name              string             `json:"name"`
+	Role              constants.UserRole `json:"role"`       // "user" or "manager"
+	ManagerID         *int64             `json:"manager_id"` // direct superior, nil at the top of the chain
+	PasswordHash      string             `json:"-"`          // Never send to frontend, empty for SSO-only users
+	PasswordChangedAt *time.Time         `json:"password_changed_at"`
+	OIDCSubject       *string            `json:"-" gorm:"column:oidc_subject;uniqueIndex"`
+	ExternalID        *string            `json:"external_id"`                 // id in the provisioning (SCIM) client
+	DeactivatedAt     *time.Time         `json:"deactivated_at"`              // deactivated users cannot authenticate
+	TOTPSecret        *string            `json:"-" gorm:"column:totp_secret"` // sealed, set at setup and kept once enabled
+	TOTPEnabledAt     *time.Time         `json:"totp_enabled_at" gorm:"column:totp_enabled_at"`
+	TOTPLastCounter   int64              `json:"-" gorm:"column:totp_last_counter"` // last accepted time step, a code works once
+	ServiceAccount    bool               `json:"service_account"`                   // machine client, authenticates with API keys only
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
+func (u *User) HasTwoFactor() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != nil
+}
+
+type Group struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	DisplayName string    `json:"display_name" gorm:"uniqueIndex"`
+	ExternalID  *string   `json:"external_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Members []User `json:"members" gorm:"many2many:user_group_members;joinForeignKey:GroupID;joinReferences:UserID"`
+}
+
+func (Group) TableName() string {
+	return "user_groups"
 }
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"backend/constants"
 	"backend/controllers"
 	"backend/middleware"
 
@@ -12,6 +13,11 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// WellKnownRoutes are served from the root, outside of /api
+func WellKnownRoutes(r *gin.RouterGroup) {
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+}
+
 func AuthRoutes(r *gin.RouterGroup) {
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -20,40 +26,157 @@ func AuthRoutes(r *gin.RouterGroup) {
 	auth := r.Group("/auth")
 	{
 		auth.POST("/login", controllers.Login)
+		auth.POST("/login/2fa", controllers.LoginTwoFactor)
+		auth.POST("/refresh", controllers.Refresh)
+		auth.POST("/logout", controllers.Logout) // checks the CSRF token itself, it must work once the access token expired
+		auth.POST("/register", controllers.Register)
+		auth.POST("/forgot-password", controllers.ForgotPassword)
+		auth.POST("/reset-password", controllers.ResetPassword)
+		auth.GET("/oidc/login", controllers.OIDCLogin)
+		auth.GET("/oidc/callback", controllers.OIDCCallback)
+	}
+
+	// provisioning client (SCIM 2.0), authenticated with SCIM_BEARER_TOKEN
+	scim := r.Group("/scim/v2", middleware.SCIMAuthMiddleware())
+	{
+		scim.GET("/ServiceProviderConfig", controllers.SCIMServiceProviderConfig)
+
+		scim.GET("/Users", controllers.SCIMListUsers)
+		scim.POST("/Users", controllers.SCIMCreateUser)
+		scim.GET("/Users/:id", controllers.SCIMGetUser)
+		scim.PUT("/Users/:id", controllers.SCIMReplaceUser)
+		scim.PATCH("/Users/:id", controllers.SCIMPatchUser)
+		scim.DELETE("/Users/:id", controllers.SCIMDeleteUser)
+
+		scim.GET("/Groups", controllers.SCIMListGroups)
+		scim.POST("/Groups", controllers.SCIMCreateGroup)
+		scim.GET("/Groups/:id", controllers.SCIMGetGroup)
+		scim.PUT("/Groups/:id", controllers.SCIMReplaceGroup)
+		scim.PATCH("/Groups/:id", controllers.SCIMPatchGroup)
+		scim.DELETE("/Groups/:id", controllers.SCIMDeleteGroup)
+	}
+
+	protected := r.Group("/", middleware.JWTAuthMiddleware(), middleware.CSRFMiddleware())
+
+	protected.PUT("/auth/password", middleware.DenyAPIKeys(), controllers.ChangePassword)
+
+	twoFactor := protected.Group("/auth/2fa", middleware.DenyAPIKeys())
+	{
+		twoFactor.POST("/setup", controllers.SetupTwoFactor)
+		twoFactor.POST("/enable", controllers.EnableTwoFactor)
+		twoFactor.POST("/verify", controllers.VerifyTwoFactor)
+		twoFactor.POST("/disable", controllers.DisableTwoFactor)
+		twoFactor.POST("/recovery-codes", controllers.RegenerateRecoveryCodes)
 	}
 
-	protected := r.Group("/", middleware.JWTAuthMiddleware())
+	sessions := protected.Group("/sessions", middleware.DenyAPIKeys())
+	{
+		sessions.GET("", controllers.GetSessions)
+		sessions.DELETE("/:uuid", controllers.RevokeSession)
+	}
 
 	manager := protected.Group("/manager", middleware.RequireRole("manager"))
 
-	manager.GET("/dashboard", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "Welcome Manager"})
-	})
+	manager.GET("/dashboard", middleware.RequireScope(constants.APIScopeReportsRead, ""), controllers.ManagerDashboard)
+
+	managerSLAPolicies := manager.Group("/sla-policies", middleware.RequireScope(constants.APIScopeSettingsRead, constants.APIScopeSettingsWrite))
+	{
+		managerSLAPolicies.GET("", controllers.GetSLAPolicies)
+		managerSLAPolicies.PUT("/:id", controllers.UpdateSLAPolicy)
+	}
 
-	managerExpenses := manager.Group("/expenses")
+	managerExpenses := manager.Group("/expenses", middleware.RequireScope(constants.APIScopeExpensesRead, constants.APIScopeExpensesWrite))
 	{
 		managerExpenses.GET("", controllers.GetExpenses)
+		managerExpenses.GET("/export", controllers.ExportExpenses)
+		managerExpenses.POST("/bulk-decision", middleware.DenyAPIKeys(), middleware.RequireTwoFactor(), controllers.BulkDecision)
 		managerExpenses.GET("/:id", controllers.GetExpense)
-		managerExpenses.PUT("/:id/approve", controllers.ApproveExpense)
-		managerExpenses.PUT("/:id/reject", controllers.RejectExpense)
+		managerExpenses.PUT("/:id/approve", middleware.DenyAPIKeys(), middleware.RequireTwoFactor(), controllers.ApproveExpense)
+		managerExpenses.PUT("/:id/reject", middleware.DenyAPIKeys(), middleware.RequireTwoFactor(), controllers.RejectExpense)
+		managerExpenses.GET("/:id/voucher", controllers.GetExpenseVoucher)
+	}
+
+	managerDelegations := manager.Group("/delegations", middleware.RequireScope(constants.APIScopeSettingsRead, constants.APIScopeSettingsWrite))
+	{
+		managerDelegations.GET("", controllers.GetDelegations)
+		managerDelegations.POST("", controllers.CreateDelegation)
+		managerDelegations.DELETE("/:id", controllers.RevokeDelegation)
 	}
 
-	managerLogs := manager.Group("/expense-logs")
+	managerUsers := manager.Group("/users", middleware.RequireScope(constants.APIScopeUsersRead, constants.APIScopeUsersWrite))
+	{
+		managerUsers.GET("", controllers.GetUsers)
+		managerUsers.POST("", controllers.CreateUser)
+		managerUsers.GET("/:id", controllers.GetUser)
+		managerUsers.PUT("/:id", controllers.UpdateUser)
+		managerUsers.POST("/:id/deactivate", controllers.DeactivateUser)
+		managerUsers.POST("/:id/reactivate", controllers.ReactivateUser)
+		managerUsers.POST("/:id/reset-password", middleware.DenyAPIKeys(), controllers.ResetUserPassword)
+		managerUsers.POST("/:id/unlock", controllers.UnlockUser)
+	}
+
+	managerInvites := manager.Group("/invites", middleware.RequireScope(constants.APIScopeUsersRead, constants.APIScopeUsersWrite))
+	{
+		managerInvites.GET("", controllers.GetInvites)
+		managerInvites.POST("", controllers.CreateInvite)
+		managerInvites.DELETE("/:id", controllers.RevokeInvite)
+	}
+
+	managerLogs := manager.Group("/expense-logs", middleware.RequireScope(constants.APIScopeAuditRead, ""))
 	{
 		managerLogs.GET("", controllers.GetExpenseAuditLog)
+		managerLogs.GET("/export", controllers.ExportExpenseAuditLog)
+		managerLogs.GET("/verify", controllers.VerifyExpenseAuditLog)
+	}
+
+	managerLedger := manager.Group("/ledger", middleware.RequireScope(constants.APIScopeReportsRead, ""))
+	{
+		managerLedger.GET("/trial-balance", controllers.GetTrialBalance)
+		managerLedger.GET("/balances", controllers.GetLedgerBalances)
+		managerLedger.GET("/entries", controllers.GetJournalEntries)
+	}
+
+	// a report like the ledger, outside of the users group so reports:read alone is enough
+	manager.GET("/users/:id/statement", middleware.RequireScope(constants.APIScopeReportsRead, ""), controllers.GetStatement)
+
+	managerGLAccounts := manager.Group("/accounting/gl-accounts", middleware.RequireScope(constants.APIScopeSettingsRead, constants.APIScopeSettingsWrite))
+	{
+		managerGLAccounts.GET("", controllers.GetGLAccounts)
+		managerGLAccounts.PUT("/:category", controllers.UpdateGLAccount)
+	}
+
+	managerAccountingExports := manager.Group("/accounting/exports", middleware.RequireScope(constants.APIScopeReportsRead, constants.APIScopeAccountingWrite))
+	{
+		managerAccountingExports.GET("", controllers.GetAccountingExports)
+		managerAccountingExports.POST("", controllers.CreateAccountingExport)
+		managerAccountingExports.GET("/:id/download", controllers.DownloadAccountingExport)
+	}
+
+	manager.GET("/login-attempts", middleware.RequireScope(constants.APIScopeAuditRead, ""), controllers.GetLoginAttempts)
+	manager.GET("/audit-events", middleware.RequireScope(constants.APIScopeAuditRead, ""), controllers.GetAuditEvents)
+
+	// API keys cannot mint API keys
+	managerServiceAccounts := manager.Group("/service-accounts", middleware.DenyAPIKeys())
+	{
+		managerServiceAccounts.GET("", controllers.GetServiceAccounts)
+		managerServiceAccounts.POST("", controllers.CreateServiceAccount)
+		managerServiceAccounts.GET("/:id/keys", controllers.GetAPIKeys)
+		managerServiceAccounts.POST("/:id/keys", controllers.CreateAPIKey)
+		managerServiceAccounts.DELETE("/:id/keys/:key_id", controllers.RevokeAPIKey)
 	}
 
 	user := protected.Group("/user", middleware.RequireRole("user"))
 
-	user.GET("/dashboard", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "Welcome user"})
-	})
+	user.GET("/dashboard", middleware.RequireScope(constants.APIScopeReportsRead, ""), controllers.UserDashboard)
+	user.GET("/statement", middleware.RequireScope(constants.APIScopeReportsRead, ""), controllers.GetUserStatement)
 
 	// user expense routes
-	userExpenses := user.Group("/expenses")
+	userExpenses := user.Group("/expenses", middleware.RequireScope(constants.APIScopeExpensesRead, constants.APIScopeExpensesWrite))
 	{
 		userExpenses.GET("", controllers.GetUserExpenses)
+		userExpenses.GET("/export", controllers.ExportUserExpenses)
 		userExpenses.GET("/:id", controllers.GetExpense)
+		userExpenses.GET("/:id/voucher", controllers.GetUserExpenseVoucher)
 		userExpenses.POST("", controllers.CreateExpense)
 	}
 }
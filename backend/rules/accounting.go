@@ -0,0 +1,36 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	c "backend/constants"
+)
+
+var (
+	ErrInvalidJournalFormat = errors.New("format must be csv, iif or xero")
+	ErrInvalidGLAccount     = errors.New("account code and name are required, at most 64 characters, without tabs or line breaks")
+	ErrNothingToExport      = errors.New("no completed expenses left to export in this period")
+	ErrAlreadyExported      = errors.New("some of these expenses were exported meanwhile, try again")
+)
+
+// AccountingFormat validates a journal format
+func AccountingFormat(format c.AccountingFormat) (c.AccountingFormat, error) {
+	for _, known := range c.AccountingFormats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", ErrInvalidJournalFormat
+}
+
+// ValidateGLAccount keeps account codes and names importable, IIF is tab separated
+func ValidateGLAccount(account c.GLAccount) error {
+	for _, value := range []string{account.Code, account.Name} {
+		if strings.TrimSpace(value) == "" || utf8.RuneCountInString(value) > 64 || strings.ContainsAny(value, "\t\r\n") {
+			return ErrInvalidGLAccount
+		}
+	}
+	return nil
+}
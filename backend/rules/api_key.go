@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"backend/constants"
+	"backend/models"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidAPIKey        = errors.New("invalid API key")
+	ErrAPIKeyRevoked        = errors.New("API key has been revoked")
+	ErrAPIKeyExpired        = errors.New("API key has expired")
+	ErrInvalidScope         = errors.New("unknown API key scope")
+	ErrNoScopes             = errors.New("an API key needs at least one scope")
+	ErrInsufficientScope    = errors.New("API key does not have the required scope")
+	ErrAPIKeyNotAllowed     = errors.New("this endpoint is not available to API keys")
+	ErrNotServiceAccount    = errors.New("API keys can only be issued to service accounts")
+	ErrInvalidAPIKeyExpiry  = errors.New("API key expiry must be in the future")
+	ErrAPIKeyExpiryTooLong  = errors.New("API key expiry is too far in the future")
+	ErrServiceAccountSignIn = errors.New("service accounts cannot sign in")
+)
+
+func ValidateAPIKey(key *models.APIKey, now time.Time) error {
+	if key.RevokedAt != nil {
+		return ErrAPIKeyRevoked
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return ErrAPIKeyExpired
+	}
+	return nil
+}
+
+func ValidateScopes(scopes []constants.APIScope) error {
+	if len(scopes) == 0 {
+		return ErrNoScopes
+	}
+	for _, scope := range scopes {
+		known := false
+		for _, s := range constants.APIScopes {
+			if scope == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+	return nil
+}
+
+// ValidateAPIKeyExpiry bounds key lifetime, every key expires
+func ValidateAPIKeyExpiry(expiresAt, now time.Time, maxTTL time.Duration) error {
+	if !expiresAt.After(now) {
+		return ErrInvalidAPIKeyExpiry
+	}
+	if expiresAt.After(now.Add(maxTTL)) {
+		return ErrAPIKeyExpiryTooLong
+	}
+	return nil
+}
+
+func CanIssueAPIKey(user *models.User) error {
+	if !user.ServiceAccount {
+		return ErrNotServiceAccount
+	}
+	return CanAuthenticate(user)
+}
@@ -0,0 +1,29 @@
+package rules
+
+import "errors"
+
+var (
+	ErrAuditSequenceGap    = errors.New("audit entries are missing before this entry")
+	ErrAuditLinkMismatch   = errors.New("audit entry does not link to the previous entry")
+	ErrAuditHashMismatch   = errors.New("audit entry was modified after it was written")
+	ErrAuditChainTruncated = errors.New("audit entries are missing at the end of the chain")
+	ErrAuditHeadMismatch   = errors.New("audit chain does not end at the recorded head")
+	ErrAuditAnchorMismatch = errors.New("audit chain does not match an anchor taken earlier")
+	ErrAuditAnchorForged   = errors.New("audit anchor signature is invalid")
+	ErrAuditAnchorMissing  = errors.New("an audit anchor taken earlier is missing")
+)
+
+// CheckAuditLink verifies one entry against the entry before it. hash is the
+// stored hash, computed the hash of the stored content.
+func CheckAuditLink(seq, expectedSeq int64, prevHash, expectedPrevHash, hash, computed string) error {
+	if seq != expectedSeq {
+		return ErrAuditSequenceGap
+	}
+	if prevHash != expectedPrevHash {
+		return ErrAuditLinkMismatch
+	}
+	if hash != computed {
+		return ErrAuditHashMismatch
+	}
+	return nil
+}
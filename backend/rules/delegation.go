@@ -0,0 +1,38 @@
+package rules
+
+import (
+	"backend/models"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSelfDelegation          = errors.New("cannot delegate approvals to yourself")
+	ErrInvalidDelegationWindow = errors.New("delegation must end after it starts")
+	ErrDelegationInPast        = errors.New("delegation window has already ended")
+	ErrNoActiveDelegation      = errors.New("no active delegation from this approver")
+	ErrDelegatedOwnExpense     = errors.New("a delegate cannot decide on an expense submitted by the approver they act for")
+)
+
+func ValidateDelegation(delegatorID, delegateID int64, startsAt, endsAt, now time.Time) error {
+	if delegatorID == delegateID {
+		return ErrSelfDelegation
+	}
+
+	if !endsAt.After(startsAt) {
+		return ErrInvalidDelegationWindow
+	}
+
+	if !endsAt.After(now) {
+		return ErrDelegationInPast
+	}
+
+	return nil
+}
+
+func IsDelegationActive(d *models.ApprovalDelegation, at time.Time) bool {
+	if d.RevokedAt != nil {
+		return false
+	}
+	return !at.Before(d.StartsAt) && at.Before(d.EndsAt)
+}
@@ -13,6 +13,9 @@ var (
 	ErrEmptyDesc                 = errors.New("description is required")
 	ErrExpenseAlreadyFinalized   = errors.New("expense has already been approved and finalized")
 	ErrExpenseNotPendingApproval = errors.New("expense is not pending for approval")
+	ErrInvalidCategory           = errors.New("unknown expense category")
+	ErrInvalidAmountRange        = errors.New("min_amount must not exceed max_amount")
+	ErrExpenseNotApproved        = errors.New("expense has not been approved")
 )
 
 func ValidateExpense(amount int64, description string) error {
@@ -35,10 +38,38 @@ func ValidateExpense(amount int64, description string) error {
 	return nil
 }
 
+// ExpenseCategory checks a submitted category, an empty one files the expense under other
+func ExpenseCategory(category c.ExpenseCategory) (c.ExpenseCategory, error) {
+	if category == "" {
+		return c.ExpenseCategoryOther, nil
+	}
+	if !IsExpenseCategory(category) {
+		return "", ErrInvalidCategory
+	}
+	return category, nil
+}
+
+func IsExpenseCategory(category c.ExpenseCategory) bool {
+	for _, known := range c.ExpenseCategories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
+
 func RequiresManagerApproval(amount int64) bool {
 	return amount >= c.ApprovalThreshold
 }
 
+// RequiredApprovalSteps is the number of managers approving an expense one after the other
+func RequiredApprovalSteps(amount int64) int {
+	if amount >= c.SecondApprovalThreshold {
+		return 2
+	}
+	return 1
+}
+
 func CanProceed(exp *models.Expense) error {
 	switch exp.Status {
 	case c.ExpenseStatusPending:
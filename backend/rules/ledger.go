@@ -0,0 +1,49 @@
+package rules
+
+import (
+	"errors"
+
+	"backend/constants"
+	"backend/models"
+)
+
+var (
+	ErrJournalEntryTooShort = errors.New("a journal entry needs at least two lines")
+	ErrInvalidJournalLine   = errors.New("a journal line must have either a positive debit or a positive credit")
+	ErrUnknownLedgerAccount = errors.New("unknown ledger account")
+	ErrUnbalancedEntry      = errors.New("journal entry debits and credits must be equal")
+)
+
+// ValidateJournalEntry enforces double entry: every line is one-sided on a known
+// account and the debits equal the credits
+func ValidateJournalEntry(lines []models.JournalLine) error {
+	if len(lines) < 2 {
+		return ErrJournalEntryTooShort
+	}
+
+	var debits, credits int64
+	for _, line := range lines {
+		if !IsLedgerAccount(line.Account) {
+			return ErrUnknownLedgerAccount
+		}
+		if line.DebitIDR < 0 || line.CreditIDR < 0 || (line.DebitIDR == 0) == (line.CreditIDR == 0) {
+			return ErrInvalidJournalLine
+		}
+		debits += line.DebitIDR
+		credits += line.CreditIDR
+	}
+
+	if debits != credits {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+func IsLedgerAccount(account constants.LedgerAccount) bool {
+	for _, known := range constants.LedgerAccounts {
+		if known.Code == account {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// same answer for an unknown email and a wrong password
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrLoginThrottled     = errors.New("too many failed login attempts, try again later")
+	ErrAccountLocked      = errors.New("too many failed login attempts, the account is temporarily locked")
+)
+
+// LoginThrottlePolicy slows down password guessing. Failures are counted per email,
+// known or not so the answer does not reveal which accounts exist, and per IP address.
+type LoginThrottlePolicy struct {
+	Window          time.Duration // failures older than this are forgotten
+	FreeAttempts    int           // failures allowed before delays start
+	BaseDelay       time.Duration // doubled with every further failure
+	MaxDelay        time.Duration
+	LockoutAttempts int // failures that lock the account
+	LockoutDuration time.Duration
+	IPAttempts      int // failures from one address before it is blocked for the rest of the window
+}
+
+// LoadLoginThrottlePolicy reads LOGIN_FAILURE_WINDOW (15m), LOGIN_FREE_ATTEMPTS (3),
+// LOGIN_BASE_DELAY (1s), LOGIN_MAX_DELAY (1m), LOGIN_LOCKOUT_ATTEMPTS (10),
+// LOGIN_LOCKOUT_DURATION (15m) and LOGIN_IP_ATTEMPTS (50).
+func LoadLoginThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		Window:          envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		FreeAttempts:    envInt("LOGIN_FREE_ATTEMPTS", 3),
+		BaseDelay:       envDuration("LOGIN_BASE_DELAY", time.Second),
+		MaxDelay:        envDuration("LOGIN_MAX_DELAY", time.Minute),
+		LockoutAttempts: envInt("LOGIN_LOCKOUT_ATTEMPTS", 10),
+		LockoutDuration: envDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		IPAttempts:      envInt("LOGIN_IP_ATTEMPTS", 50),
+	}
+}
+
+// LoginFailures are the counted failures inside the window, for an email since its last success
+type LoginFailures struct {
+	Account            int
+	LastAccountFailure time.Time
+	IP                 int
+	LastIPFailure      time.Time
+}
+
+// Delay is the wait imposed after the given number of consecutive failures
+func (p LoginThrottlePolicy) Delay(failures int) time.Duration {
+	if failures < p.FreeAttempts || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Check returns how long the caller must wait before the next attempt is evaluated
+func (p LoginThrottlePolicy) Check(failures LoginFailures, now time.Time) (time.Duration, error) {
+	if p.LockoutAttempts > 0 && failures.Account >= p.LockoutAttempts {
+		if wait := failures.LastAccountFailure.Add(p.LockoutDuration).Sub(now); wait > 0 {
+			return wait, ErrAccountLocked
+		}
+	}
+
+	if p.IPAttempts > 0 && failures.IP >= p.IPAttempts {
+		if wait := failures.LastIPFailure.Add(p.Window).Sub(now); wait > 0 {
+			return wait, ErrLoginThrottled
+		}
+	}
+
+	if wait := failures.LastAccountFailure.Add(p.Delay(failures.Account)).Sub(now); failures.Account > 0 && wait > 0 {
+		return wait, ErrLoginThrottled
+	}
+
+	return 0, nil
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key))); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
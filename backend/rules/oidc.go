@@ -0,0 +1,43 @@
+package rules
+
+import (
+	c "backend/constants"
+	"errors"
+	"strings"
+)
+
+var ErrOIDCEmailNotVerified = errors.New("email is not verified by the identity provider")
+
+// IsOIDCEmailVerified trusts an email only when the IdP says so, a missing claim is not verified
+func IsOIDCEmailVerified(claims map[string]interface{}) bool {
+	verified, ok := claims["email_verified"].(bool)
+	return ok && verified
+}
+
+// MapRoleFromClaims grants the manager role when the role claim, a string or a list,
+// holds one of the manager values; everybody else is a regular user
+func MapRoleFromClaims(claims map[string]interface{}, roleClaim string, managerValues []string) c.UserRole {
+	var values []string
+	switch v := claims[roleClaim].(type) {
+	case string:
+		values = strings.Fields(strings.ReplaceAll(v, ",", " "))
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	case []string:
+		values = v
+	}
+
+	for _, value := range values {
+		for _, managerValue := range managerValues {
+			if strings.EqualFold(value, managerValue) {
+				return c.UserRoleManager
+			}
+		}
+	}
+
+	return c.UserRoleUser
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"backend/models"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// bcrypt ignores everything after 72 bytes
+const maxPasswordBytes = 72
+
+var (
+	ErrPasswordTooShort       = errors.New("password is too short")
+	ErrPasswordTooLong        = errors.New("password must be at most 72 bytes")
+	ErrPasswordTooSimple      = errors.New("password does not mix enough character types")
+	ErrPasswordContainsEmail  = errors.New("password must not contain your email")
+	ErrPasswordBreached       = errors.New("password appears in a list of breached passwords, choose another one")
+	ErrPasswordUnchanged      = errors.New("new password must be different from the current one")
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	ErrInvalidResetToken      = errors.New("invalid or expired password reset token")
+	ErrResetTokenUsed         = errors.New("password reset token has already been used")
+)
+
+// PasswordPolicy holds the strength rules; the zero value accepts any password.
+type PasswordPolicy struct {
+	MinLength           int
+	MinCharacterClasses int // out of lower case, upper case, digits and symbols
+	DisallowEmail       bool
+}
+
+// LoadPasswordPolicy reads PASSWORD_MIN_LENGTH (default 10), PASSWORD_MIN_CLASSES
+// (default 2) and PASSWORD_DISALLOW_EMAIL (on unless "false").
+func LoadPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:           envInt("PASSWORD_MIN_LENGTH", 10),
+		MinCharacterClasses: envInt("PASSWORD_MIN_CLASSES", 2),
+		DisallowEmail:       envEnabled("PASSWORD_DISALLOW_EMAIL"),
+	}
+}
+
+func (p PasswordPolicy) Validate(password, email string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w, use at least %d characters", ErrPasswordTooShort, p.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+
+	if characterClasses(password) < p.MinCharacterClasses {
+		return fmt.Errorf("%w, use at least %d of lower case, upper case, digits and symbols", ErrPasswordTooSimple, p.MinCharacterClasses)
+	}
+
+	if p.DisallowEmail && email != "" {
+		local, _, _ := strings.Cut(strings.ToLower(email), "@")
+		lower := strings.ToLower(password)
+		if strings.Contains(lower, strings.ToLower(email)) || (len(local) >= 3 && strings.Contains(lower, local)) {
+			return ErrPasswordContainsEmail
+		}
+	}
+
+	return nil
+}
+
+func ValidatePasswordResetToken(token *models.PasswordResetToken, now time.Time) error {
+	if token.UsedAt != nil {
+		return ErrResetTokenUsed
+	}
+	if !now.Before(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key))); err == nil && v >= 0 {
+		return v
+	}
+	return fallback
+}
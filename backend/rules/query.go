@@ -0,0 +1,12 @@
+package rules
+
+import "errors"
+
+var (
+	ErrInvalidSortField    = errors.New("unsupported sort field")
+	ErrInvalidSortOrder    = errors.New("order must be asc or desc")
+	ErrInvalidTime         = errors.New("time must be RFC 3339 or YYYY-MM-DD")
+	ErrInvalidTimeRange    = errors.New("from must be before to")
+	ErrInvalidExportFormat = errors.New("format must be csv or xlsx")
+	ErrInvalidCursor       = errors.New("cursor is malformed or was issued for a different sort")
+)
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+var (
+	ErrSelfApproval       = errors.New("separation of duties: approver cannot decide on their own expense")
+	ErrSuperiorApproval   = errors.New("separation of duties: approver cannot decide on an expense submitted by their superior")
+	ErrSequentialApproval = errors.New("separation of duties: approver already decided on a previous step of this expense")
+)
+
+// SeparationOfDutiesPolicy toggles each guard; the zero value disables all of them.
+type SeparationOfDutiesPolicy struct {
+	BlockSelfApproval       bool
+	BlockSuperiorApproval   bool
+	BlockSequentialApproval bool
+}
+
+// LoadSeparationOfDutiesPolicy enables every guard unless switched off with
+// SOD_BLOCK_SELF_APPROVAL, SOD_BLOCK_SUPERIOR_APPROVAL or
+// SOD_BLOCK_SEQUENTIAL_APPROVAL set to "false".
+func LoadSeparationOfDutiesPolicy() SeparationOfDutiesPolicy {
+	return SeparationOfDutiesPolicy{
+		BlockSelfApproval:       envEnabled("SOD_BLOCK_SELF_APPROVAL"),
+		BlockSuperiorApproval:   envEnabled("SOD_BLOCK_SUPERIOR_APPROVAL"),
+		BlockSequentialApproval: envEnabled("SOD_BLOCK_SEQUENTIAL_APPROVAL"),
+	}
+}
+
+type SeparationOfDutiesInput struct {
+	ApproverID          int64
+	SubmitterID         int64
+	ApproverSuperiorIDs []int64 // approver's manager chain, nearest first
+	PriorApproverIDs    []int64 // approvers of earlier steps of the same expense, and who they acted for
+
+	// a delegate's decision is also checked against the approver they act for
+	PrincipalID          *int64
+	PrincipalSuperiorIDs []int64
+}
+
+func (p SeparationOfDutiesPolicy) Check(input SeparationOfDutiesInput) error {
+	if p.BlockSelfApproval && (input.ApproverID == input.SubmitterID || (input.PrincipalID != nil && *input.PrincipalID == input.SubmitterID)) {
+		return ErrSelfApproval
+	}
+
+	if p.BlockSuperiorApproval && (containsID(input.ApproverSuperiorIDs, input.SubmitterID) || containsID(input.PrincipalSuperiorIDs, input.SubmitterID)) {
+		return ErrSuperiorApproval
+	}
+
+	if p.BlockSequentialApproval && (containsID(input.PriorApproverIDs, input.ApproverID) ||
+		(input.PrincipalID != nil && containsID(input.PriorApproverIDs, *input.PrincipalID))) {
+		return ErrSequentialApproval
+	}
+
+	return nil
+}
+
+func IsSeparationOfDutiesViolation(err error) bool {
+	return errors.Is(err, ErrSelfApproval) ||
+		errors.Is(err, ErrSuperiorApproval) ||
+		errors.Is(err, ErrSequentialApproval)
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func envEnabled(key string) bool {
+	return !strings.EqualFold(strings.TrimSpace(os.Getenv(key)), "false")
+}
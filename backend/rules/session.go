@@ -0,0 +1,27 @@
+package rules
+
+import (
+	"backend/models"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrSessionExpired      = errors.New("session has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrInvalidCSRFToken    = errors.New("missing or invalid CSRF token")
+)
+
+func ValidateSession(session *models.UserSession, now time.Time) error {
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+
+	if !now.Before(session.ExpiresAt) {
+		return ErrSessionExpired
+	}
+
+	return nil
+}
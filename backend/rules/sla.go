@@ -0,0 +1,59 @@
+package rules
+
+import (
+	c "backend/constants"
+	"backend/models"
+	"time"
+)
+
+// MatchSLAPolicy picks the policy with the highest threshold the amount reaches
+func MatchSLAPolicy(policies []models.SLAPolicy, amount int64) *models.SLAPolicy {
+	var match *models.SLAPolicy
+	for i := range policies {
+		if policies[i].MinAmountIDR > amount {
+			continue
+		}
+		if match == nil || policies[i].MinAmountIDR > match.MinAmountIDR {
+			match = &policies[i]
+		}
+	}
+	return match
+}
+
+// ApplySLAPolicy starts the approval's SLA clock at the given time
+func ApplySLAPolicy(approval *models.Approval, policy *models.SLAPolicy, from time.Time) {
+	if policy == nil {
+		return
+	}
+
+	reminderDue := from.Add(time.Duration(policy.ReminderAfterMinutes) * time.Minute)
+	escalationDue := from.Add(time.Duration(policy.EscalateAfterMinutes) * time.Minute)
+
+	approval.SLAPolicyID = &policy.ID
+	approval.ReminderDueAt = &reminderDue
+	approval.EscalationDueAt = &escalationDue
+}
+
+// NextSLAAction decides what the scheduler owes a pending approval.
+// A reminder is sent once per stage, a stage restarts after each escalation.
+func NextSLAAction(approval *models.Approval, now time.Time) c.SLAAction {
+	if approval.Status != c.ApprovalStatusPending {
+		return c.SLAActionNone
+	}
+
+	if approval.EscalationDueAt != nil && !now.Before(*approval.EscalationDueAt) {
+		return c.SLAActionEscalate
+	}
+
+	if approval.ReminderDueAt != nil && !now.Before(*approval.ReminderDueAt) {
+		stageStart := approval.CreatedAt
+		if approval.EscalatedAt != nil {
+			stageStart = *approval.EscalatedAt
+		}
+		if approval.RemindedAt == nil || approval.RemindedAt.Before(stageStart) {
+			return c.SLAActionRemind
+		}
+	}
+
+	return c.SLAActionNone
+}
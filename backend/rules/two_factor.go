@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"backend/constants"
+	"backend/models"
+	"errors"
+	"os"
+	"strings"
+)
+
+var (
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this action")
+	ErrTwoFactorNotSetUp       = errors.New("start two-factor setup first")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorMandatory      = errors.New("two-factor authentication is mandatory for your role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken         = errors.New("invalid or expired two-factor login token")
+)
+
+// TwoFactorPolicy lists the roles that must use a second factor. Managers approve
+// expenses, and approval triggers the payment, so they are covered by default.
+type TwoFactorPolicy struct {
+	RequiredRoles []constants.UserRole
+}
+
+// LoadTwoFactorPolicy reads TWO_FACTOR_REQUIRED_ROLES, a comma separated list of roles
+// (default "manager"); "none" turns the requirement off.
+func LoadTwoFactorPolicy() TwoFactorPolicy {
+	value := strings.TrimSpace(os.Getenv("TWO_FACTOR_REQUIRED_ROLES"))
+	if value == "" {
+		return TwoFactorPolicy{RequiredRoles: []constants.UserRole{constants.UserRoleManager}}
+	}
+	if strings.EqualFold(value, "none") {
+		return TwoFactorPolicy{}
+	}
+
+	var roles []constants.UserRole
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, constants.UserRole(strings.ToLower(role)))
+		}
+	}
+	return TwoFactorPolicy{RequiredRoles: roles}
+}
+
+func (p TwoFactorPolicy) Requires(user *models.User) bool {
+	for _, role := range p.RequiredRoles {
+		if user.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanDisableTwoFactor refuses to drop the second factor of a role that needs it
+func (p TwoFactorPolicy) CanDisableTwoFactor(user *models.User) error {
+	if !user.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+	if p.Requires(user) {
+		return ErrTwoFactorMandatory
+	}
+	return nil
+}
+
+// CheckSession is enforced on approval routes: a user covered by the policy needs a
+// session that passed the second factor, which also means 2FA has been enrolled
+func (p TwoFactorPolicy) CheckSession(user *models.User, session *models.UserSession) error {
+	if !p.Requires(user) {
+		return nil
+	}
+	if !user.HasTwoFactor() || session.TwoFactorVerifiedAt == nil {
+		return ErrTwoFactorRequired
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package rules
+
+import (
+	c "backend/constants"
+	"backend/models"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUserDeactivated        = errors.New("user is deactivated")
+	ErrInvalidEmail           = errors.New("email is not valid")
+	ErrInvalidRole            = errors.New("role must be user or manager")
+	ErrCannotDeactivateSelf   = errors.New("cannot deactivate your own account")
+	ErrSelfManager            = errors.New("user cannot be their own manager")
+	ErrManagerCycle           = errors.New("manager would create a cycle in the reporting line")
+	ErrInviteUsed             = errors.New("invite has already been used")
+	ErrInviteExpired          = errors.New("invite has expired")
+	ErrInviteRevoked          = errors.New("invite has been revoked")
+	ErrInvalidInvite          = errors.New("invalid invite token")
+	ErrEmailAlreadyRegistered = errors.New("email is already registered")
+)
+
+func CanAuthenticate(user *models.User) error {
+	if !user.IsActive() {
+		return ErrUserDeactivated
+	}
+	return nil
+}
+
+// NormalizeEmail lower-cases and validates an address, emails are unique case-insensitively
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+func ValidateRole(role c.UserRole) error {
+	if role != c.UserRoleUser && role != c.UserRoleManager {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// ValidateManagerAssignment refuses self management and cycles, managerChain are the
+// superiors of the proposed manager, nearest first
+func ValidateManagerAssignment(userID, managerID int64, managerChain []int64) error {
+	if userID == managerID {
+		return ErrSelfManager
+	}
+	if containsID(managerChain, userID) {
+		return ErrManagerCycle
+	}
+	return nil
+}
+
+func ValidateInvite(invite *models.UserInvite, now time.Time) error {
+	switch {
+	case invite.RevokedAt != nil:
+		return ErrInviteRevoked
+	case invite.UsedAt != nil:
+		return ErrInviteUsed
+	case !now.Before(invite.ExpiresAt):
+		return ErrInviteExpired
+	}
+	return nil
+}
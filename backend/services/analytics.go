@@ -0,0 +1,233 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultAnalyticsCacheTTL = 5 * time.Minute
+	DefaultAnalyticsMonths   = 12 // period when the request names none, including the current month
+	analyticsTopUsers        = 20
+)
+
+type SpendBucket struct {
+	Count     int64 `json:"count" example:"12"`
+	AmountIDR int64 `json:"amount_idr" gorm:"column:amount_idr" example:"8400000"`
+}
+
+type StatusSpend struct {
+	Status constants.ExpenseStatus `json:"status" example:"approved"`
+	SpendBucket
+}
+
+type CategorySpend struct {
+	Category constants.ExpenseCategory `json:"category" example:"travel"`
+	SpendBucket
+}
+
+type UserSpend struct {
+	UserID int64  `json:"user_id" example:"7"`
+	Name   string `json:"name" example:"Bob User"`
+	SpendBucket
+}
+
+type MonthSpend struct {
+	Month string `json:"month" example:"2026-01"`
+	SpendBucket
+}
+
+// SpendAnalytics aggregates the expenses submitted in [From, To), To is nil for an open period
+type SpendAnalytics struct {
+	From       *time.Time      `json:"from"`
+	To         *time.Time      `json:"to"`
+	Totals     SpendBucket     `json:"totals"`
+	ByStatus   []StatusSpend   `json:"by_status"`
+	ByCategory []CategorySpend `json:"by_category"`
+	ByUser     []UserSpend     `json:"by_user"` // top spenders by amount
+	ByMonth    []MonthSpend    `json:"by_month"`
+
+	DecidedCount               int64     `json:"decided_count" example:"30"` // manager decisions behind the turnaround
+	AvgApprovalTurnaroundHours float64   `json:"avg_approval_turnaround_hours" example:"18.5"`
+	AutoApprovalRate           float64   `json:"auto_approval_rate" example:"0.4"`
+	PaymentFailureRate         float64   `json:"payment_failure_rate" example:"0.02"`
+	PaymentsFailed             int64     `json:"payments_failed" example:"1"`
+	GeneratedAt                time.Time `json:"generated_at"`
+}
+
+type analyticsCacheEntry struct {
+	analytics *SpendAnalytics
+	expiresAt time.Time
+}
+
+var (
+	analyticsCacheMu sync.Mutex
+	analyticsCache   = map[string]analyticsCacheEntry{}
+)
+
+func AnalyticsCacheTTL() time.Duration {
+	return DurationFromEnv("ANALYTICS_CACHE_TTL", DefaultAnalyticsCacheTTL)
+}
+
+// DefaultAnalyticsFrom starts the default period on the first day of the month
+// DefaultAnalyticsMonths-1 months before now
+func DefaultAnalyticsFrom(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month()-(DefaultAnalyticsMonths-1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CachedSpendAnalytics serves the analytics of a period from memory for AnalyticsCacheTTL,
+// cached reports whether they were computed by an earlier request
+func CachedSpendAnalytics(db *gorm.DB, from, to *time.Time, now time.Time) (analytics *SpendAnalytics, cached bool, err error) {
+	key := analyticsCacheKey(from, to)
+
+	analyticsCacheMu.Lock()
+	entry, ok := analyticsCache[key]
+	analyticsCacheMu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.analytics, true, nil
+	}
+
+	analytics, err = ComputeSpendAnalytics(db, from, to, now)
+	if err != nil {
+		return nil, false, err
+	}
+
+	analyticsCacheMu.Lock()
+	defer analyticsCacheMu.Unlock()
+	for key, entry := range analyticsCache {
+		if !now.Before(entry.expiresAt) {
+			delete(analyticsCache, key)
+		}
+	}
+	analyticsCache[key] = analyticsCacheEntry{analytics: analytics, expiresAt: now.Add(AnalyticsCacheTTL())}
+	return analytics, false, nil
+}
+
+// ResetAnalyticsCache drops every cached period, the next request recomputes
+func ResetAnalyticsCache() {
+	analyticsCacheMu.Lock()
+	defer analyticsCacheMu.Unlock()
+	analyticsCache = map[string]analyticsCacheEntry{}
+}
+
+// ComputeSpendAnalytics runs the aggregations of a period in the database
+func ComputeSpendAnalytics(db *gorm.DB, from, to *time.Time, now time.Time) (*SpendAnalytics, error) {
+	analytics := &SpendAnalytics{From: from, To: to, GeneratedAt: now.UTC()}
+
+	period := func() *gorm.DB {
+		query := db.Model(&models.Expense{})
+		if from != nil {
+			query = query.Where("expenses.submitted_at >= ?", *from)
+		}
+		if to != nil {
+			query = query.Where("expenses.submitted_at < ?", *to)
+		}
+		return query
+	}
+	const spend = "COUNT(*) AS count, COALESCE(SUM(expenses.amount_idr), 0) AS amount_idr"
+
+	var totals struct {
+		SpendBucket
+		AutoApproved int64
+	}
+	if err := period().
+		Select(spend + ", COALESCE(SUM(CASE WHEN expenses.auto_approved THEN 1 ELSE 0 END), 0) AS auto_approved").
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	analytics.Totals = totals.SpendBucket
+	analytics.AutoApprovalRate = rate(totals.AutoApproved, totals.Count)
+
+	if err := period().Select("expenses.status, " + spend).
+		Group("expenses.status").Order("expenses.status").
+		Scan(&analytics.ByStatus).Error; err != nil {
+		return nil, err
+	}
+	if err := period().Select("expenses.category, " + spend).
+		Group("expenses.category").Order("expenses.category").
+		Scan(&analytics.ByCategory).Error; err != nil {
+		return nil, err
+	}
+	if err := period().Select("expenses.user_id, users.name, " + spend).
+		Joins("JOIN users ON users.id = expenses.user_id").
+		Group("expenses.user_id, users.name").Order("amount_idr DESC, expenses.user_id").
+		Limit(analyticsTopUsers).
+		Scan(&analytics.ByUser).Error; err != nil {
+		return nil, err
+	}
+
+	month := "strftime('%Y-%m', expenses.submitted_at)"
+	if IsPostgres(db) {
+		month = "to_char(date_trunc('month', expenses.submitted_at), 'YYYY-MM')"
+	}
+	if err := period().Select(month + " AS month, " + spend).
+		Group(month).Order("month").
+		Scan(&analytics.ByMonth).Error; err != nil {
+		return nil, err
+	}
+
+	// turnaround runs from submission to the manager's decision in the audit log,
+	// auto-approved expenses never wait and are left out
+	seconds := SecondsBetween(db, "expenses.submitted_at", "expense_audit_logs.created_at")
+	var turnaround struct {
+		Decided    int64
+		AvgSeconds *float64
+	}
+	if err := period().
+		Joins("JOIN expense_audit_logs ON expense_audit_logs.expense_id = expenses.id").
+		Where("expense_audit_logs.from_status = ? AND expense_audit_logs.to_status IN ?",
+			constants.ExpenseStatusPending, []constants.ExpenseStatus{constants.ExpenseStatusApproved, constants.ExpenseStatusRejected}).
+		Select("COUNT(*) AS decided, AVG(" + seconds + ") AS avg_seconds").
+		Scan(&turnaround).Error; err != nil {
+		return nil, err
+	}
+	analytics.DecidedCount = turnaround.Decided
+	if turnaround.AvgSeconds != nil {
+		analytics.AvgApprovalTurnaroundHours = *turnaround.AvgSeconds / 3600
+	}
+
+	// every approved expense goes to payment, a failure leaves it approved
+	var payable int64
+	if err := period().
+		Where("expenses.status IN ?", []constants.ExpenseStatus{constants.ExpenseStatusApproved, constants.ExpenseStatusCompleted}).
+		Count(&payable).Error; err != nil {
+		return nil, err
+	}
+	// a payment that failed and then went through on a retry is not counted
+	if err := period().
+		Where("expenses.status <> ?", constants.ExpenseStatusCompleted).
+		Where("CAST(expenses.id AS TEXT) IN (?)", db.Model(&models.AuditEvent{}).
+			Select("entity_id").
+			Where("action = ? AND entity_type = ?", constants.AuditActionPaymentFail, constants.AuditEntityExpense)).
+		Count(&analytics.PaymentsFailed).Error; err != nil {
+		return nil, err
+	}
+	analytics.PaymentFailureRate = rate(analytics.PaymentsFailed, payable)
+
+	return analytics, nil
+}
+
+func analyticsCacheKey(from, to *time.Time) string {
+	key := ""
+	if from != nil {
+		key = from.UTC().Format(time.RFC3339Nano)
+	}
+	key += "|"
+	if to != nil {
+		key += to.UTC().Format(time.RFC3339Nano)
+	}
+	return key
+}
+
+func rate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
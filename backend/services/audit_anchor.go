@@ -0,0 +1,57 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+)
+
+// AuditAnchorKey signs audit chain anchors. It is only used for anchors, never rotates and
+// never touches the database, rewriting the chain together with its anchors needs this key.
+type AuditAnchorKey struct {
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+// LoadAuditAnchorKey reads AUDIT_ANCHOR_KEY, a base64 Ed25519 seed of 32 bytes. Without it
+// the seed is derived from JWT_KEY_ENCRYPTION_SECRET or JWT_SECRET, which then must not change.
+func LoadAuditAnchorKey() (*AuditAnchorKey, error) {
+	var seed []byte
+	if encoded := os.Getenv("AUDIT_ANCHOR_KEY"); encoded != "" {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) != ed25519.SeedSize {
+			return nil, errors.New("AUDIT_ANCHOR_KEY must be a base64 Ed25519 seed of 32 bytes")
+		}
+		seed = decoded
+	} else {
+		secret := os.Getenv("JWT_KEY_ENCRYPTION_SECRET")
+		if secret == "" {
+			secret = os.Getenv("JWT_SECRET")
+		}
+		if secret == "" {
+			return nil, errors.New("AUDIT_ANCHOR_KEY, JWT_KEY_ENCRYPTION_SECRET or JWT_SECRET must be set")
+		}
+		sum := sha256.Sum256([]byte("audit-anchor:" + secret))
+		seed = sum[:]
+	}
+
+	private := ed25519.NewKeyFromSeed(seed)
+	return &AuditAnchorKey{private: private, public: private.Public().(ed25519.PublicKey)}, nil
+}
+
+func (k *AuditAnchorKey) Sign(message []byte) string {
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(k.private, message))
+}
+
+func (k *AuditAnchorKey) Verify(message []byte, signature string) bool {
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	return err == nil && ed25519.Verify(k.public, message, sig)
+}
+
+// PublicKey is published with every verification report and exported anchor so copies
+// kept elsewhere can be checked without the server
+func (k *AuditAnchorKey) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(k.public)
+}
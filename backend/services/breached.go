@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//go:embed breached_passwords.txt
+var defaultBreachedPasswords string
+
+// BreachedPasswords checks candidate passwords against a local list of SHA-1 hashes,
+// nothing leaves the server
+type BreachedPasswords interface {
+	Contains(password string) bool
+}
+
+type breachedPasswordList struct {
+	hashes map[string]struct{}
+}
+
+// NewBreachedPasswords searches BREACHED_PASSWORDS_FILE when set and the embedded list of
+// common passwords otherwise. The file must be sorted by hash, like the Have I Been Pwned
+// "ordered by hash" SHA-1 download (about 40GB). It stays on disk and is binary searched,
+// only a few blocks are read per check
+func NewBreachedPasswords() (BreachedPasswords, error) {
+	path := os.Getenv("BREACHED_PASSWORDS_FILE")
+	if path == "" {
+		return NewDefaultBreachedPasswords(), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+
+	// the file stays open for the life of the process
+	return NewSortedBreachedPasswords(file, info.Size()), nil
+}
+
+// NewDefaultBreachedPasswords returns the embedded list of common passwords
+func NewDefaultBreachedPasswords() BreachedPasswords {
+	list, _ := NewBreachedPasswordsFrom(strings.NewReader(defaultBreachedPasswords))
+	return list
+}
+
+// NewBreachedPasswordsFrom reads one SHA-1 hex hash per line into memory, "HASH:count" lines
+// and # comments are accepted. Meant for short lists, use NewSortedBreachedPasswords for dumps
+func NewBreachedPasswordsFrom(r io.Reader) (BreachedPasswords, error) {
+	list := &breachedPasswordList{hashes: map[string]struct{}{}}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		list.hashes[strings.ToUpper(hash)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return list, nil
+}
+
+func (l *breachedPasswordList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	_, found := l.hashes[strings.ToUpper(hex.EncodeToString(sum[:]))]
+	return found
+}
+
+type sortedBreachedPasswords struct {
+	r    io.ReaderAt
+	size int64
+}
+
+// NewSortedBreachedPasswords searches size bytes of r, one "HASH" or "HASH:count" line per
+// hash in ascending order. Upper and lower case hex sort the same way, comments are not allowed
+func NewSortedBreachedPasswords(r io.ReaderAt, size int64) BreachedPasswords {
+	return &sortedBreachedPasswords{r: r, size: size}
+}
+
+// Contains narrows [lo, hi) to the lines that can still match, lo always starts a line.
+// Read errors count as not found, like an empty list
+func (l *sortedBreachedPasswords) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start := mid
+		if mid > 0 {
+			// the first line starting at or after mid
+			_, next, err := l.lineAt(mid - 1)
+			if err != nil {
+				return false
+			}
+			start = next
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		line, next, err := l.lineAt(start)
+		if err != nil {
+			return false
+		}
+		hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+		switch strings.Compare(strings.ToUpper(hash), target) {
+		case 0:
+			return true
+		case -1:
+			lo = next
+		default:
+			hi = mid
+		}
+	}
+	return false
+}
+
+// lineAt reads from offset up to the next newline and returns the offset after it
+func (l *sortedBreachedPasswords) lineAt(offset int64) (string, int64, error) {
+	var line []byte
+	buf := make([]byte, 128)
+	for pos := offset; pos < l.size; {
+		n, err := l.r.ReadAt(buf, pos)
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			line = append(line, buf[:i]...)
+			return string(line), pos + int64(i) + 1, nil
+		}
+		line = append(line, buf[:n]...)
+		pos += int64(n)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", 0, err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return string(line), l.size, nil
+}
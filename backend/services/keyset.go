@@ -0,0 +1,448 @@
+package services
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"backend/constants"
+	"backend/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+
+	DefaultKeyRotationInterval = 30 * 24 * time.Hour
+	DefaultKeyRetention        = 7 * 24 * time.Hour
+
+	// a token naming an unknown kid reloads the keys at most this often, another
+	// instance may have rotated since the last scheduled reload
+	unknownKeyReloadInterval = 10 * time.Second
+)
+
+var (
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrNoActiveKey          = errors.New("no active signing key")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+)
+
+// JWK is the public part of a signing key as published in the JWKS document
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"b7f3c1d2e4a5f607"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type signingKey struct {
+	kid       string
+	alg       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	public    crypto.PublicKey
+	createdAt time.Time
+}
+
+// KeySet holds the active signing key and the retired keys still accepted for verification.
+// Keys live in the signing_keys table so every instance shares them.
+type KeySet struct {
+	mu         sync.RWMutex
+	db         *gorm.DB
+	algorithm  string
+	sealKey    []byte
+	keys       map[string]*signingKey
+	currentKID string
+
+	reloadMu         sync.Mutex
+	unknownKeyLoaded time.Time
+}
+
+var (
+	defaultKeySet   *KeySet
+	defaultKeySetMu sync.RWMutex
+)
+
+// InitKeySet loads the keys from the database, creating the first one when none is active
+func InitKeySet(db *gorm.DB) (*KeySet, error) {
+	algorithm := os.Getenv("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = AlgorithmEdDSA
+	}
+	if algorithm != AlgorithmEdDSA && algorithm != AlgorithmRS256 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	secret := os.Getenv("JWT_KEY_ENCRYPTION_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	if secret == "" {
+		return nil, errors.New("JWT_KEY_ENCRYPTION_SECRET or JWT_SECRET must be set")
+	}
+	sealKey := sha256.Sum256([]byte(secret))
+
+	ks := &KeySet{
+		db:        db,
+		algorithm: algorithm,
+		sealKey:   sealKey[:],
+		keys:      map[string]*signingKey{},
+	}
+
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	if ks.currentKID == "" {
+		if _, err := ks.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	SetKeySet(ks)
+	return ks, nil
+}
+
+func SetKeySet(ks *KeySet) {
+	defaultKeySetMu.Lock()
+	defer defaultKeySetMu.Unlock()
+	defaultKeySet = ks
+}
+
+// Keys returns the key set initialised at startup
+func Keys() *KeySet {
+	defaultKeySetMu.RLock()
+	defer defaultKeySetMu.RUnlock()
+	return defaultKeySet
+}
+
+// Reload reads the active key and retired keys still inside the retention window
+func (ks *KeySet) Reload() error {
+	cutoff := time.Now().UTC().Add(-KeyRetention())
+
+	var rows []models.SigningKey
+	if err := ks.db.
+		Where("status = ? OR (status = ? AND retired_at > ?)", constants.SigningKeyStatusActive, constants.SigningKeyStatusRetired, cutoff).
+		Order("created_at DESC").
+		Find(&rows).Error; err != nil {
+		return err
+	}
+
+	keys := map[string]*signingKey{}
+	currentKID := ""
+	for _, row := range rows {
+		key, err := ks.decode(row)
+		if err != nil {
+			return fmt.Errorf("failed to load signing key %s: %w", row.KID, err)
+		}
+		keys[row.KID] = key
+		if row.Status == constants.SigningKeyStatusActive && currentKID == "" {
+			currentKID = row.KID
+		}
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.currentKID = currentKID
+	ks.mu.Unlock()
+
+	return nil
+}
+
+// Rotate creates a new active key, the previous one is retired but still verifies
+func (ks *KeySet) Rotate() (*models.SigningKey, error) {
+	row, err := ks.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = ks.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SigningKey{}).
+			Where("status = ?", constants.SigningKeyStatusActive).
+			Updates(map[string]interface{}{"status": constants.SigningKeyStatusRetired, "retired_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Create(row).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// drop keys that left the retention window for good
+	if err := ks.db.
+		Where("status = ? AND retired_at <= ?", constants.SigningKeyStatusRetired, now.Add(-KeyRetention())).
+		Delete(&models.SigningKey{}).Error; err != nil {
+		return nil, err
+	}
+
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// CurrentKeyAge tells the rotation worker how long the active key has been signing
+func (ks *KeySet) CurrentKeyAge(now time.Time) (time.Duration, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[ks.currentKID]
+	if !ok {
+		return 0, ErrNoActiveKey
+	}
+	return now.Sub(key.createdAt), nil
+}
+
+// Sign issues a token with the active key and its kid header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[ks.currentKID]
+	ks.mu.RUnlock()
+	if !ok {
+		return "", ErrNoActiveKey
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// Parse verifies a token against the key named by its kid header
+func (ks *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := ks.lookup(kid)
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+
+		// Validate signing method
+		if token.Method.Alg() != key.alg {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.public, nil
+	}, jwt.WithValidMethods([]string{AlgorithmEdDSA, AlgorithmRS256}))
+}
+
+// lookup finds a verification key, reloading once when another instance may have rotated
+func (ks *KeySet) lookup(kid string) (*signingKey, bool) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+	if ok {
+		return key, true
+	}
+
+	// concurrent misses wait for the reload in flight and look again
+	ks.reloadMu.Lock()
+	if time.Since(ks.unknownKeyLoaded) >= unknownKeyReloadInterval {
+		ks.unknownKeyLoaded = time.Now()
+		if err := ks.Reload(); err != nil {
+			log.Printf("Failed to reload signing keys for kid %q: %v", kid, err)
+		}
+	}
+	ks.reloadMu.Unlock()
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok = ks.keys[kid]
+	return key, ok
+}
+
+// JWKS publishes the public keys so other services can validate our tokens
+func (ks *KeySet) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.alg}
+		switch pub := key.public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func KeyRotationInterval() time.Duration {
+	return DurationFromEnv("JWT_KEY_ROTATION_INTERVAL", DefaultKeyRotationInterval)
+}
+
+// KeyRetention is how long a retired key keeps verifying, it must outlive the access token TTL
+func KeyRetention() time.Duration {
+	return DurationFromEnv("JWT_KEY_RETENTION", DefaultKeyRetention)
+}
+
+func (ks *KeySet) generate() (*models.SigningKey, error) {
+	var private crypto.Signer
+	switch ks.algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := ks.seal(privateDER)
+	if err != nil {
+		return nil, err
+	}
+
+	kid := make([]byte, 8)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KID:        hex.EncodeToString(kid),
+		Algorithm:  ks.algorithm,
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		PrivateKey: sealed,
+		Status:     constants.SigningKeyStatusActive,
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+func (ks *KeySet) decode(row models.SigningKey) (*signingKey, error) {
+	privateDER, err := ks.open(row.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	key := &signingKey{
+		kid:       row.KID,
+		alg:       row.Algorithm,
+		private:   private,
+		public:    private.Public(),
+		createdAt: row.CreatedAt,
+	}
+
+	switch row.Algorithm {
+	case AlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, row.Algorithm)
+	}
+
+	return key, nil
+}
+
+// seal encrypts private keys at rest with AES-GCM
+func (ks *KeySet) seal(plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(ks.sealKey)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func (ks *KeySet) open(sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(ks.sealKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("sealed key is too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// Seal encrypts other secrets at rest (TOTP seeds) with the same key as the signing keys
+func (ks *KeySet) Seal(plaintext []byte) (string, error) {
+	return ks.seal(plaintext)
+}
+
+func (ks *KeySet) Open(sealed string) ([]byte, error) {
+	return ks.open(sealed)
+}
+
+// DurationFromEnv reads a positive Go duration such as 15m, the fallback covers unset and invalid values
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"backend/models"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, recipient *models.User, subject, body string) error
+}
+
+type logNotifier struct{}
+
+// NewNotifier returns the default notifier, which only writes to the server log
+func NewNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, recipient *models.User, subject, body string) error {
+	log.Printf("Notify %s <%s>: %s - %s", recipient.Name, recipient.Email, subject, body)
+	return nil
+}
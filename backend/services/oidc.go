@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrOIDCNotConfigured = errors.New("OIDC is not configured")
+	ErrOIDCInvalidToken  = errors.New("invalid ID token")
+	ErrOIDCNonceMismatch = errors.New("ID token nonce does not match")
+)
+
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string // optional, public clients rely on PKCE only
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCIdentity is what we keep from a verified ID token
+type OIDCIdentity struct {
+	Subject string
+	Email   string
+	Name    string
+	Claims  jwt.MapClaims
+}
+
+type OIDCService interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error)
+}
+
+type oidcService struct {
+	client *http.Client
+	config OIDCConfig
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	jwks      map[string]interface{}
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewOIDCService() OIDCService {
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	return NewOIDCServiceWithConfig(OIDCConfig{
+		IssuerURL:    os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       scopes,
+	})
+}
+
+func NewOIDCServiceWithConfig(config OIDCConfig) OIDCService {
+	return &oidcService{
+		client: &http.Client{Timeout: 10 * time.Second},
+		config: config,
+	}
+}
+
+// NewPKCEVerifier returns a code verifier and its S256 challenge
+func NewPKCEVerifier() (verifier, challenge string, err error) {
+	verifier, err = RandomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func RandomURLToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (s *oidcService) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.config.ClientID},
+		"redirect_uri":          {s.config.RedirectURL},
+		"scope":                 {strings.Join(s.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+func (s *oidcService) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	discovery, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.config.RedirectURL},
+		"client_id":     {s.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if s.config.ClientSecret != "" {
+		form.Set("client_secret", s.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.IDToken == "" {
+		return nil, fmt.Errorf("token exchange failed with status %d: %s %s", resp.StatusCode, result.Error, result.ErrorDescription)
+	}
+
+	return s.verifyIDToken(ctx, discovery, result.IDToken, nonce)
+}
+
+func (s *oidcService) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, rawIDToken, nonce string) (*OIDCIdentity, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := s.lookupKey(ctx, discovery, kid)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(rawIDToken, claims, keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(s.config.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCInvalidToken, err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, ErrOIDCNonceMismatch
+	}
+
+	identity := &OIDCIdentity{Claims: claims}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub claim", ErrOIDCInvalidToken)
+	}
+
+	return identity, nil
+}
+
+func (s *oidcService) discover(ctx context.Context) (*oidcDiscovery, error) {
+	if s.config.IssuerURL == "" || s.config.ClientID == "" || s.config.RedirectURL == "" {
+		return nil, ErrOIDCNotConfigured
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.discovery != nil {
+		return s.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := s.getJSON(ctx, strings.TrimSuffix(s.config.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is incomplete")
+	}
+
+	s.discovery = &discovery
+	return s.discovery, nil
+}
+
+// lookupKey returns the IdP key for kid, refetching the JWKS once when the kid is unknown (key rotation)
+func (s *oidcService) lookupKey(ctx context.Context, discovery *oidcDiscovery, kid string) (interface{}, error) {
+	s.mu.Lock()
+	key, ok := s.jwks[kid]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := s.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch IdP keys: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, raw := range set.Keys {
+		jwk, err := parsePublicJWK(raw)
+		if err != nil {
+			continue
+		}
+		keys[jwk.kid] = jwk.key
+	}
+
+	s.mu.Lock()
+	s.jwks = keys
+	s.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (s *oidcService) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -98,6 +98,10 @@ func (s *paymentService) ProcessPayment(ctx context.Context, expense *models.Exp
 
 	expense.Status = toStatus
 	expense.ProcessedAt = &now
+	// a retry of an existing payment carries no id, the external id still finds it
+	if result.Data.ID != "" {
+		expense.PaymentReference = &result.Data.ID
+	}
 
 	return expense, approval, nil
 }
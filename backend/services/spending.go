@@ -0,0 +1,152 @@
+package services
+
+import (
+	"time"
+
+	"backend/constants"
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+type SpendingTotals struct {
+	Count     int64 `json:"count" example:"3"`
+	AmountIDR int64 `json:"amount_idr" gorm:"column:amount_idr" example:"1250000"`
+}
+
+// SpendingPeriod covers the expenses submitted, or paid for Reimbursed, from From up to the time of the summary
+type SpendingPeriod struct {
+	From            time.Time      `json:"from"`
+	Submitted       SpendingTotals `json:"submitted"`
+	Reimbursed      SpendingTotals `json:"reimbursed"`       // paid out in the period, whenever submitted
+	AwaitingPayment SpendingTotals `json:"awaiting_payment"` // approved, payment not completed yet
+	Pending         SpendingTotals `json:"pending"`          // waiting for a manager
+	Rejected        SpendingTotals `json:"rejected"`
+
+	AvgHoursToPayment float64 `json:"avg_hours_to_payment" example:"52.5"` // submission to payment, over the Reimbursed expenses
+}
+
+// SpendingLimits places the user's expenses of the year against the per-expense limits
+type SpendingLimits struct {
+	MinExpenseIDR        int64   `json:"min_expense_idr" example:"10000"`
+	MaxExpenseIDR        int64   `json:"max_expense_idr" example:"50000000"`
+	ApprovalThresholdIDR int64   `json:"approval_threshold_idr" example:"1000000"`
+	LargestExpenseIDR    int64   `json:"largest_expense_idr" example:"3500000"`
+	LargestShareOfMax    float64 `json:"largest_share_of_max" example:"0.07"` // largest expense / max expense
+	NeededApproval       int64   `json:"needed_approval" example:"2"`         // expenses at or above the approval threshold
+}
+
+type SpendingSummary struct {
+	UserID      int64          `json:"user_id" example:"7"`
+	Month       SpendingPeriod `json:"month"`
+	Quarter     SpendingPeriod `json:"quarter"`
+	Year        SpendingPeriod `json:"year"`
+	Limits      SpendingLimits `json:"limits"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// UserSpendingSummary sums one user's expenses of the current calendar month, quarter
+// and year in UTC, see UserSpendingPeriod
+func UserSpendingSummary(db *gorm.DB, userID int64, now time.Time) (*SpendingSummary, error) {
+	now = now.UTC()
+	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	quarterStart := time.Date(now.Year(), now.Month()-(now.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	summary := &SpendingSummary{UserID: userID, GeneratedAt: now}
+	for _, period := range []struct {
+		from   time.Time
+		target *SpendingPeriod
+	}{
+		{monthStart, &summary.Month},
+		{quarterStart, &summary.Quarter},
+		{yearStart, &summary.Year},
+	} {
+		result, err := UserSpendingPeriod(db, userID, period.from, now)
+		if err != nil {
+			return nil, err
+		}
+		*period.target = *result
+	}
+
+	limits, err := userSpendingLimits(db, userID, yearStart, now)
+	if err != nil {
+		return nil, err
+	}
+	summary.Limits = *limits
+
+	return summary, nil
+}
+
+// UserSpendingPeriod sums one user's expenses in [from, to), reimbursed expenses by the time
+// they were paid and every other status by the time they were submitted
+func UserSpendingPeriod(db *gorm.DB, userID int64, from, to time.Time) (*SpendingPeriod, error) {
+	period := &SpendingPeriod{From: from}
+
+	var rows []struct {
+		Status constants.ExpenseStatus
+		SpendingTotals
+	}
+	if err := db.Model(&models.Expense{}).
+		Where("user_id = ? AND submitted_at >= ? AND submitted_at < ?", userID, from, to).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(amount_idr), 0) AS amount_idr").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		period.Submitted.Count += row.Count
+		period.Submitted.AmountIDR += row.AmountIDR
+
+		switch row.Status {
+		case constants.ExpenseStatusApproved:
+			period.AwaitingPayment = row.SpendingTotals
+		case constants.ExpenseStatusPending:
+			period.Pending = row.SpendingTotals
+		case constants.ExpenseStatusRejected:
+			period.Rejected = row.SpendingTotals
+		}
+	}
+
+	// an expense submitted at the end of a month and paid in the next one is reimbursed in the next one
+	var paid struct {
+		SpendingTotals
+		AvgSeconds *float64
+	}
+	if err := db.Model(&models.Expense{}).
+		Where("user_id = ? AND status = ? AND processed_at >= ? AND processed_at < ?", userID, constants.ExpenseStatusCompleted, from, to).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount_idr), 0) AS amount_idr, AVG(" + SecondsBetween(db, "submitted_at", "processed_at") + ") AS avg_seconds").
+		Scan(&paid).Error; err != nil {
+		return nil, err
+	}
+	period.Reimbursed = paid.SpendingTotals
+	if paid.AvgSeconds != nil {
+		period.AvgHoursToPayment = *paid.AvgSeconds / 3600
+	}
+
+	return period, nil
+}
+
+func userSpendingLimits(db *gorm.DB, userID int64, from, to time.Time) (*SpendingLimits, error) {
+	limits := &SpendingLimits{
+		MinExpenseIDR:        constants.MinExpenseAmount,
+		MaxExpenseIDR:        constants.MaxExpenseAmount,
+		ApprovalThresholdIDR: constants.ApprovalThreshold,
+	}
+
+	var row struct {
+		Largest        int64
+		NeededApproval int64
+	}
+	if err := db.Model(&models.Expense{}).
+		Where("user_id = ? AND submitted_at >= ? AND submitted_at < ?", userID, from, to).
+		Select("COALESCE(MAX(amount_idr), 0) AS largest, COALESCE(SUM(CASE WHEN amount_idr >= ? THEN 1 ELSE 0 END), 0) AS needed_approval", constants.ApprovalThreshold).
+		Scan(&row).Error; err != nil {
+		return nil, err
+	}
+
+	limits.LargestExpenseIDR = row.Largest
+	limits.LargestShareOfMax = float64(row.Largest) / float64(constants.MaxExpenseAmount)
+	limits.NeededApproval = row.NeededApproval
+	return limits, nil
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// IsPostgres tells postgres apart from sqlite, which runs the tests
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// SecondsBetween returns the SQL for the seconds from timestamp column a to column b
+func SecondsBetween(db *gorm.DB, a, b string) string {
+	if IsPostgres(db) {
+		return "EXTRACT(EPOCH FROM (" + b + " - " + a + "))"
+	}
+	return "(julianday(" + b + ") - julianday(" + a + ")) * 86400"
+}
+
+// IsUniqueViolation tells a unique constraint failure apart from other errors, postgres
+// reports SQLSTATE 23505 and sqlite "UNIQUE constraint failed"
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "SQLSTATE 23505") || strings.Contains(message, "UNIQUE constraint failed")
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"time"
+
+	"backend/constants"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StatementLine is one reimbursed expense of a statement
+type StatementLine struct {
+	ExpenseID        int64 `gorm:"column:id"`
+	UUID             uuid.UUID
+	Description      string
+	Category         constants.ExpenseCategory
+	AmountIDR        int64 `gorm:"column:amount_idr"`
+	SubmittedAt      time.Time
+	ProcessedAt      time.Time
+	PaymentReference *string
+}
+
+// Statement lists what a user was paid in [From, To)
+type Statement struct {
+	User        models.User
+	From        time.Time
+	To          time.Time
+	Lines       []StatementLine
+	Total       SpendingTotals
+	GeneratedAt time.Time
+}
+
+// VoucherEntry is a status change of a voucher's expense
+type VoucherEntry struct {
+	FromStatus     constants.ExpenseStatus
+	ToStatus       constants.ExpenseStatus
+	ActorName      *string
+	OnBehalfOfName *string
+	Reason         string
+	CreatedAt      time.Time
+}
+
+// Voucher holds an approved expense with who decided on it and how it got there
+type Voucher struct {
+	Expense        models.Expense
+	Approval       *models.Approval
+	ApproverName   *string
+	OnBehalfOfName *string
+	DecidedAt      *time.Time // when the expense left pending, nil if it never waited for a manager
+	History        []VoucherEntry
+	GeneratedAt    time.Time
+}
+
+// UserStatement collects the user's completed expenses paid in [from, to), oldest payment first
+func UserStatement(db *gorm.DB, userID int64, from, to, now time.Time) (*Statement, error) {
+	statement := &Statement{From: from, To: to, GeneratedAt: now}
+	if err := db.Select("id", "name", "email").First(&statement.User, userID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&models.Expense{}).
+		Select("id, uuid, description, category, amount_idr, submitted_at, processed_at, payment_reference").
+		Where("user_id = ? AND status = ? AND processed_at >= ? AND processed_at < ?", userID, constants.ExpenseStatusCompleted, from, to).
+		Order("processed_at ASC, id ASC").
+		Scan(&statement.Lines).Error; err != nil {
+		return nil, err
+	}
+
+	for _, line := range statement.Lines {
+		statement.Total.Count++
+		statement.Total.AmountIDR += line.AmountIDR
+	}
+	return statement, nil
+}
+
+// ExpenseVoucher collects an approved or paid expense with its approval and history,
+// rules.ErrExpenseNotApproved for one that is still pending or was rejected. A non-nil
+// ownerID only finds that user's expenses.
+func ExpenseVoucher(db *gorm.DB, expenseID int64, ownerID *int64, now time.Time) (*Voucher, error) {
+	voucher := &Voucher{GeneratedAt: now}
+	query := db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name", "email")
+	}).
+		Preload("Approval")
+	if ownerID != nil {
+		query = query.Where("user_id = ?", *ownerID)
+	}
+	if err := query.First(&voucher.Expense, expenseID).Error; err != nil {
+		return nil, err
+	}
+
+	expense := &voucher.Expense
+	if expense.Status != constants.ExpenseStatusApproved && expense.Status != constants.ExpenseStatusCompleted {
+		return nil, rules.ErrExpenseNotApproved
+	}
+
+	if err := db.Table("expense_audit_logs").
+		Select("expense_audit_logs.from_status, expense_audit_logs.to_status, actors.name AS actor_name, "+
+			"principals.name AS on_behalf_of_name, expense_audit_logs.reason, expense_audit_logs.created_at").
+		Joins("LEFT JOIN users AS actors ON actors.id = expense_audit_logs.actor_id").
+		Joins("LEFT JOIN users AS principals ON principals.id = expense_audit_logs.on_behalf_of_id").
+		Where("expense_audit_logs.expense_id = ?", expense.ID).
+		Order("expense_audit_logs.created_at ASC, expense_audit_logs.id ASC").
+		Scan(&voucher.History).Error; err != nil {
+		return nil, err
+	}
+
+	for _, entry := range voucher.History {
+		if entry.FromStatus == constants.ExpenseStatusPending && entry.ToStatus == constants.ExpenseStatusApproved {
+			decidedAt := entry.CreatedAt
+			voucher.DecidedAt = &decidedAt
+		}
+	}
+
+	if approval := expense.Approval; approval != nil {
+		voucher.Approval = approval
+		var err error
+		if voucher.ApproverName, err = userName(db, approval.ApproverID); err != nil {
+			return nil, err
+		}
+		if voucher.OnBehalfOfName, err = userName(db, approval.OnBehalfOfID); err != nil {
+			return nil, err
+		}
+	}
+
+	return voucher, nil
+}
+
+func userName(db *gorm.DB, id *int64) (*string, error) {
+	if id == nil {
+		return nil, nil
+	}
+	var user models.User
+	if err := db.Select("id", "name").Limit(1).Find(&user, *id).Error; err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, nil
+	}
+	return &user.Name, nil
+}
@@ -0,0 +1,178 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAccountingExport_FormatsAndTracking(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+
+	paid := time.Date(2026, 3, 9, 20, 0, 0, 0, time.UTC) // 10 March in Jakarta
+	reference := "pay_51ab"
+	hotel := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 1500000, Description: "Hotel, Bandung", Category: constants.ExpenseCategoryLodging,
+		Status: constants.ExpenseStatusCompleted, SubmittedAt: paid, ProcessedAt: &paid, PaymentReference: &reference}
+	taxi := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 75000, Description: "=Taxi", Category: constants.ExpenseCategoryTransport,
+		Status: constants.ExpenseStatusCompleted, SubmittedAt: paid, ProcessedAt: &paid}
+	approved := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 300000, Description: "Flight", Category: constants.ExpenseCategoryTravel,
+		Status: constants.ExpenseStatusApproved, SubmittedAt: paid}
+	for _, expense := range []*models.Expense{&hotel, &taxi, &approved} {
+		db.DB.Create(expense)
+	}
+
+	// mappings
+	w := jsonRequest(router, http.MethodPut, "/api/manager/accounting/gl-accounts/lodging", map[string]string{"account_code": "6135", "account_name": "Hotels"}, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = jsonRequest(router, http.MethodPut, "/api/manager/accounting/gl-accounts/lodging", map[string]string{"account_code": "6135", "account_name": "Ho\ttels"}, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = jsonRequest(router, http.MethodPut, "/api/manager/accounting/gl-accounts/yachts", map[string]string{"account_code": "1", "account_name": "Yachts"}, cookies)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/accounting/gl-accounts", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var accounts struct {
+		Data []models.GLAccountMapping `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &accounts)
+	assert.Len(t, accounts.Data, len(constants.ExpenseCategories))
+	for _, account := range accounts.Data {
+		switch account.Category {
+		case constants.ExpenseCategoryLodging:
+			assert.Equal(t, "Hotels", account.AccountName)
+		case constants.ExpenseCategoryTransport:
+			assert.Equal(t, "6140", account.AccountCode)
+		}
+	}
+
+	// exporting
+	w = jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports", map[string]string{"format": "pdf"}, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), rules.ErrInvalidJournalFormat.Error())
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports?from=2026-03-01&to=2026-03-31", map[string]string{"format": "xero"}, cookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var export models.AccountingExport
+	json.Unmarshal(w.Body.Bytes(), &export)
+	assert.Equal(t, constants.AccountingFormatXero, export.Format)
+	assert.Equal(t, int64(2), export.ExpenseCount)
+	assert.Equal(t, int64(1575000), export.TotalIDR)
+
+	// tracked expenses are not exported twice
+	w = jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports", nil, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), rules.ErrNothingToExport.Error())
+
+	download := "/api/manager/accounting/exports/" + strconv.FormatInt(export.ID, 10) + "/download"
+	hotelNumber := "EXP-" + strconv.FormatInt(hotel.ID, 10)
+
+	w = jsonRequest(router, http.MethodGet, download, nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "journal-"+strconv.FormatInt(export.ID, 10)+"-xero.csv")
+	xero := w.Body.String()
+	assert.True(t, strings.HasPrefix(xero, "*Narration,*Date,Description,*AccountCode,*TaxRate,*Amount,"))
+	assert.Contains(t, xero, hotelNumber+" Bob User,10/03/2026,\"Bob User: Hotel, Bandung\",6135,Tax Exempt,1500000,")
+	assert.Contains(t, xero, hotelNumber+" Bob User,10/03/2026,\"Bob User: Hotel, Bandung\",1000,Tax Exempt,-1500000,")
+
+	w = jsonRequest(router, http.MethodGet, download+"?format=csv", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	generic := w.Body.String()
+	assert.True(t, strings.HasPrefix(generic, "Journal,Date,Account Code,Account Name,Description,Debit (IDR),Credit (IDR),Employee,Expense UUID,Payment Reference\n"))
+	assert.Contains(t, generic, hotelNumber+",2026-03-10,6135,Hotels,\"Bob User: Hotel, Bandung\",1500000,,Bob User,"+hotel.UUID.String()+",pay_51ab\n")
+	assert.Contains(t, generic, hotelNumber+",2026-03-10,1000,Cash,\"Bob User: Hotel, Bandung\",,1500000,Bob User,")
+	assert.Contains(t, generic, ",6140,Local Transport,Bob User: =Taxi,75000,,")
+	assert.NotContains(t, generic, "Flight") // not paid yet
+
+	// accounts changed after the export was created do not alter its download
+	t.Setenv("ACCOUNTING_PAYMENT_ACCOUNT_NAME", "Bank BCA")
+	w = jsonRequest(router, http.MethodPut, "/api/manager/accounting/gl-accounts/lodging", map[string]string{"account_code": "6136", "account_name": "Hotels Java"}, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = jsonRequest(router, http.MethodGet, download+"?format=iif", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".iif")
+	iif := w.Body.String()
+	assert.True(t, strings.HasPrefix(iif, "!TRNS\tTRNSTYPE\tDATE\tACCNT\tAMOUNT\tDOCNUM\tMEMO\r\n!SPL\t"))
+	assert.Contains(t, iif, "TRNS\tGENERAL JOURNAL\t03/10/2026\tHotels\t1500000\t"+hotelNumber+"\tBob User: Hotel, Bandung\r\n"+
+		"SPL\tGENERAL JOURNAL\t03/10/2026\tCash\t-1500000\t"+hotelNumber+"\tBob User: Hotel, Bandung\r\nENDTRNS\r\n")
+	assert.Equal(t, 3, strings.Count(iif, "ENDTRNS\r\n"))
+
+	w = jsonRequest(router, http.MethodGet, download+"?format=qbo", nil, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = jsonRequest(router, http.MethodGet, "/api/manager/accounting/exports/999999/download", nil, cookies)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// paid later, the next export only has the new expense
+	later := paid.Add(24 * time.Hour)
+	db.DB.Model(&approved).Updates(map[string]interface{}{"status": constants.ExpenseStatusCompleted, "processed_at": later})
+	w = jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports", map[string]string{"format": "iif"}, cookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &export)
+	assert.Equal(t, int64(1), export.ExpenseCount)
+
+	// the next export books to the accounts of its time
+	var item models.AccountingExportItem
+	db.DB.First(&item, "expense_id = ?", approved.ID)
+	assert.Equal(t, "Bank BCA", item.CreditAccountName)
+	assert.Equal(t, "6110", item.DebitAccountCode)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/accounting/exports", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []models.AccountingExport `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, int64(2), list.Meta.Total)
+}
+
+func TestAccountingExport_OnlyAConcurrentExportIsAConflict(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	paid := time.Date(2026, 3, 9, 20, 0, 0, 0, time.UTC)
+	taxi := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 75000, Description: "Taxi", Category: constants.ExpenseCategoryTransport,
+		Status: constants.ExpenseStatusCompleted, SubmittedAt: paid, ProcessedAt: &paid}
+	db.DB.Create(&taxi)
+
+	// the items insert fails, first for another reason and then because a concurrent
+	// export took the expense in between
+	var interfere func(tx *gorm.DB)
+	db.DB.Callback().Create().Before("gorm:create").Register("interfere_export_items", func(tx *gorm.DB) {
+		if tx.Statement.Table == "accounting_export_items" && interfere != nil {
+			interfere(tx)
+		}
+	})
+
+	interfere = func(tx *gorm.DB) { tx.AddError(errors.New("disk full")) }
+	w := jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports", nil, cookies)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	interfere = func(tx *gorm.DB) {
+		tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"INSERT INTO accounting_export_items (accounting_export_id, expense_id, debit_account_code, debit_account_name, credit_account_code, credit_account_name) VALUES (0, ?, '', '', '', '')", taxi.ID)
+	}
+	w = jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports", nil, cookies)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), rules.ErrAlreadyExported.Error())
+
+	interfere = nil
+	w = jsonRequest(router, http.MethodPost, "/api/manager/accounting/exports", nil, cookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
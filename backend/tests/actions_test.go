@@ -3,12 +3,17 @@ package actions
 import (
 	"backend/actions"
 	"backend/constants"
+	"backend/helpers"
 	"backend/models"
+	"backend/rules"
 	"backend/services"
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -154,3 +159,202 @@ func TestRejectExpense_Success(t *testing.T) {
 	assert.Equal(t, "Not allowed", rejectedApproval.Notes)
 	fmt.Println("Test for reject expense succeeded")
 }
+
+func TestApproveExpense_SeparationOfDuties(t *testing.T) {
+	policy := rules.SeparationOfDutiesPolicy{
+		BlockSelfApproval:       true,
+		BlockSuperiorApproval:   true,
+		BlockSequentialApproval: true,
+	}
+
+	newPending := func(userID int64) *models.Expense {
+		expense, approval, _ := actions.SubmitExpense(actions.SubmitExpenseInput{
+			UserID:      userID,
+			AmountIDR:   constants.ApprovalThreshold + 10000,
+			Description: "Separation of duties test",
+		})
+		expense.Approval = approval
+		return expense
+	}
+
+	_, _, err := actions.ApproveExpense(actions.ApproveExpenseInput{
+		Expense:            newPending(7),
+		ApproverID:         ptrInt64(7),
+		SeparationOfDuties: policy,
+	})
+	assert.ErrorIs(t, err, rules.ErrSelfApproval)
+
+	_, _, err = actions.RejectExpense(actions.RejectExpenseInput{
+		Expense:             newPending(1),
+		ApproverID:          ptrInt64(7),
+		SeparationOfDuties:  policy,
+		ApproverSuperiorIDs: []int64{3, 1},
+	})
+	assert.ErrorIs(t, err, rules.ErrSuperiorApproval)
+
+	_, _, err = actions.ApproveExpense(actions.ApproveExpenseInput{
+		Expense:            newPending(2),
+		ApproverID:         ptrInt64(7),
+		SeparationOfDuties: policy,
+		PriorApproverIDs:   []int64{7},
+	})
+	assert.ErrorIs(t, err, rules.ErrSequentialApproval)
+	assert.True(t, rules.IsSeparationOfDutiesViolation(err))
+
+	approved, _, err := actions.ApproveExpense(actions.ApproveExpenseInput{
+		Expense:             newPending(2),
+		ApproverID:          ptrInt64(7),
+		SeparationOfDuties:  policy,
+		ApproverSuperiorIDs: []int64{3},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ExpenseStatusApproved, approved.Status)
+}
+
+func TestDelegation_Window(t *testing.T) {
+	now := time.Now().UTC()
+
+	assert.ErrorIs(t, rules.ValidateDelegation(1, 1, now, now.Add(time.Hour), now), rules.ErrSelfDelegation)
+	assert.ErrorIs(t, rules.ValidateDelegation(1, 2, now, now, now), rules.ErrInvalidDelegationWindow)
+	assert.ErrorIs(t, rules.ValidateDelegation(1, 2, now.Add(-2*time.Hour), now.Add(-time.Hour), now), rules.ErrDelegationInPast)
+	assert.NoError(t, rules.ValidateDelegation(1, 2, now, now.Add(time.Hour), now))
+
+	delegation := &models.ApprovalDelegation{DelegatorID: 1, DelegateID: 2, StartsAt: now, EndsAt: now.Add(time.Hour)}
+	assert.True(t, rules.IsDelegationActive(delegation, now))
+	assert.False(t, rules.IsDelegationActive(delegation, now.Add(time.Hour)))
+	assert.False(t, rules.IsDelegationActive(delegation, now.Add(-time.Minute)))
+
+	delegation.RevokedAt = &now
+	assert.False(t, rules.IsDelegationActive(delegation, now))
+}
+
+func TestApproveExpense_OnBehalfOf(t *testing.T) {
+	expense, approval, _ := actions.SubmitExpense(actions.SubmitExpenseInput{
+		UserID:      4,
+		AmountIDR:   constants.ApprovalThreshold + 10000,
+		Description: "Delegated approval test",
+	})
+	expense.Approval = approval
+
+	_, updatedApproval, err := actions.ApproveExpense(actions.ApproveExpenseInput{
+		Expense:      expense,
+		ApproverID:   ptrInt64(8),
+		OnBehalfOfID: ptrInt64(1),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), *updatedApproval.ApproverID)
+	assert.Equal(t, int64(1), *updatedApproval.OnBehalfOfID)
+}
+
+func TestSLA_PolicyAndNextAction(t *testing.T) {
+	policies := []models.SLAPolicy{
+		{ID: 1, MinAmountIDR: 0, ReminderAfterMinutes: 60, EscalateAfterMinutes: 120},
+		{ID: 2, MinAmountIDR: 10000000, ReminderAfterMinutes: 30, EscalateAfterMinutes: 60},
+	}
+	assert.Equal(t, int64(1), rules.MatchSLAPolicy(policies, 2000000).ID)
+	assert.Equal(t, int64(2), rules.MatchSLAPolicy(policies, 10000000).ID)
+
+	created := time.Now().UTC()
+	approval := &models.Approval{Status: constants.ApprovalStatusPending, CreatedAt: created}
+	rules.ApplySLAPolicy(approval, rules.MatchSLAPolicy(policies, 2000000), created)
+
+	assert.Equal(t, constants.SLAActionNone, rules.NextSLAAction(approval, created.Add(59*time.Minute)))
+	assert.Equal(t, constants.SLAActionRemind, rules.NextSLAAction(approval, created.Add(61*time.Minute)))
+
+	remindedAt := created.Add(61 * time.Minute)
+	approval.RemindedAt = &remindedAt
+	assert.Equal(t, constants.SLAActionNone, rules.NextSLAAction(approval, created.Add(90*time.Minute)))
+	assert.Equal(t, constants.SLAActionEscalate, rules.NextSLAAction(approval, created.Add(120*time.Minute)))
+
+	// a new stage starts after escalation, the reminder is owed again
+	escalatedAt := created.Add(120 * time.Minute)
+	approval.EscalatedAt = &escalatedAt
+	approval.EscalationLevel = 1
+	rules.ApplySLAPolicy(approval, &policies[0], escalatedAt)
+	assert.Equal(t, constants.SLAActionRemind, rules.NextSLAAction(approval, escalatedAt.Add(61*time.Minute)))
+
+	approval.Status = constants.ApprovalStatusApproved
+	assert.Equal(t, constants.SLAActionNone, rules.NextSLAAction(approval, escalatedAt.Add(200*time.Minute)))
+}
+
+func TestSession_ValidateAndRefreshToken(t *testing.T) {
+	now := time.Now().UTC()
+	session := &models.UserSession{ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, rules.ValidateSession(session, now))
+	assert.ErrorIs(t, rules.ValidateSession(session, now.Add(time.Hour)), rules.ErrSessionExpired)
+
+	session.RevokedAt = &now
+	assert.ErrorIs(t, rules.ValidateSession(session, now), rules.ErrSessionRevoked)
+
+	token, hash, err := helpers.NewRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, hash)
+	assert.Equal(t, hash, helpers.HashToken(token))
+
+	other, _, _ := helpers.NewRefreshToken()
+	assert.NotEqual(t, token, other)
+}
+
+func TestKeySet_SignRotateAndJWKS(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	db := setupDB(t)
+	if err := db.AutoMigrate(&models.SigningKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	keys, err := services.InitKeySet(db)
+	assert.NoError(t, err)
+
+	user := &models.User{ID: 1, Role: constants.UserRoleManager}
+	oldToken, err := helpers.IssueAccessToken(user, uuid.New())
+	assert.NoError(t, err)
+
+	claims, err := helpers.ParseAccessToken(oldToken)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), claims["user_id"])
+
+	_, err = keys.Rotate()
+	assert.NoError(t, err)
+
+	// tokens signed before rotation keep verifying with the retired key
+	_, err = helpers.ParseAccessToken(oldToken)
+	assert.NoError(t, err)
+	assert.Len(t, keys.JWKS().Keys, 2)
+	assert.Equal(t, "OKP", keys.JWKS().Keys[0].Kty)
+
+	_, err = helpers.ParseAccessToken(oldToken + "x")
+	assert.Error(t, err)
+}
+
+func TestKeySet_ReloadsKeysRotatedByAnotherInstance(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	db := setupDB(t)
+	if err := db.AutoMigrate(&models.SigningKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	verifier, err := services.InitKeySet(db)
+	assert.NoError(t, err)
+	signer, err := services.InitKeySet(db)
+	assert.NoError(t, err)
+
+	// the other instance rotates, its new kid verifies here without waiting for the scheduled reload
+	_, err = signer.Rotate()
+	assert.NoError(t, err)
+	token, err := signer.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+	_, err = verifier.Parse(token)
+	assert.NoError(t, err)
+
+	// unknown kids reload at most once per interval
+	_, err = signer.Rotate()
+	assert.NoError(t, err)
+	token, _ = signer.Sign(jwt.MapClaims{"user_id": 1})
+	_, err = verifier.Parse(token)
+	assert.ErrorIs(t, err, services.ErrUnknownKey)
+
+	assert.NoError(t, verifier.Reload())
+	_, err = verifier.Parse(token)
+	assert.NoError(t, err)
+}
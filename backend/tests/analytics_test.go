@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/controllers"
+	"backend/db"
+	"backend/models"
+	"backend/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerDashboard_SpendAnalytics(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+	services.ResetAnalyticsCache()
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+
+	jan := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	expenses := []models.Expense{
+		{UserID: bob.ID, AmountIDR: 200000, Category: constants.ExpenseCategoryMeals, Status: constants.ExpenseStatusCompleted, AutoApproved: true, SubmittedAt: jan},
+		{UserID: bob.ID, AmountIDR: 3000000, Category: constants.ExpenseCategoryLodging, Status: constants.ExpenseStatusApproved, RequiresApproval: true, SubmittedAt: jan},
+		{UserID: alice.ID, AmountIDR: 1500000, Category: constants.ExpenseCategoryTravel, Status: constants.ExpenseStatusRejected, RequiresApproval: true, SubmittedAt: feb},
+		{UserID: alice.ID, AmountIDR: 2000000, Category: constants.ExpenseCategoryTravel, Status: constants.ExpenseStatusPending, RequiresApproval: true, SubmittedAt: feb},
+		// outside the period
+		{UserID: bob.ID, AmountIDR: 9000000, Category: constants.ExpenseCategoryTravel, Status: constants.ExpenseStatusApproved, SubmittedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for i := range expenses {
+		expenses[i].UUID = uuid.New()
+		expenses[i].Description = "Expense"
+		db.DB.Create(&expenses[i])
+	}
+
+	// decided after 2 and 4 hours
+	db.DB.Create(&models.ExpenseAuditLog{ExpenseID: expenses[1].ID, ActorID: &alice.ID, FromStatus: constants.ExpenseStatusPending, ToStatus: constants.ExpenseStatusApproved, CreatedAt: jan.Add(2 * time.Hour)})
+	db.DB.Create(&models.ExpenseAuditLog{ExpenseID: expenses[2].ID, ActorID: &bob.ID, FromStatus: constants.ExpenseStatusPending, ToStatus: constants.ExpenseStatusRejected, CreatedAt: feb.Add(4 * time.Hour)})
+	db.DB.Create(&models.AuditEvent{Action: constants.AuditActionPaymentFail, EntityType: constants.AuditEntityExpense, EntityID: strconv.FormatInt(expenses[1].ID, 10), CreatedAt: jan})
+	// failed once and then paid on a retry, not a failed payment
+	db.DB.Create(&models.AuditEvent{Action: constants.AuditActionPaymentFail, EntityType: constants.AuditEntityExpense, EntityID: strconv.FormatInt(expenses[0].ID, 10), CreatedAt: jan})
+
+	dashboard := func(query string) (controllers.ManagerDashboardResponse, *httptest.ResponseRecorder) {
+		w := jsonRequest(router, http.MethodGet, "/api/manager/dashboard?"+query, nil, cookies)
+		var body controllers.ManagerDashboardResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body, w
+	}
+
+	body, w := dashboard("from=2026-01-01&to=2026-02-28")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private, max-age=300", w.Header().Get("Cache-Control"))
+
+	analytics := body.Analytics
+	assert.Equal(t, services.SpendBucket{Count: 4, AmountIDR: 6700000}, analytics.Totals)
+	assert.Len(t, analytics.ByStatus, 4)
+	assert.Equal(t, []services.CategorySpend{
+		{Category: constants.ExpenseCategoryLodging, SpendBucket: services.SpendBucket{Count: 1, AmountIDR: 3000000}},
+		{Category: constants.ExpenseCategoryMeals, SpendBucket: services.SpendBucket{Count: 1, AmountIDR: 200000}},
+		{Category: constants.ExpenseCategoryTravel, SpendBucket: services.SpendBucket{Count: 2, AmountIDR: 3500000}},
+	}, analytics.ByCategory)
+	assert.Equal(t, []services.UserSpend{
+		{UserID: alice.ID, Name: "Alice Manager", SpendBucket: services.SpendBucket{Count: 2, AmountIDR: 3500000}},
+		{UserID: bob.ID, Name: "Bob User", SpendBucket: services.SpendBucket{Count: 2, AmountIDR: 3200000}},
+	}, analytics.ByUser)
+	assert.Equal(t, []services.MonthSpend{
+		{Month: "2026-01", SpendBucket: services.SpendBucket{Count: 2, AmountIDR: 3200000}},
+		{Month: "2026-02", SpendBucket: services.SpendBucket{Count: 2, AmountIDR: 3500000}},
+	}, analytics.ByMonth)
+	assert.Equal(t, int64(2), analytics.DecidedCount)
+	assert.InDelta(t, 3.0, analytics.AvgApprovalTurnaroundHours, 0.01)
+	assert.InDelta(t, 0.25, analytics.AutoApprovalRate, 0.001)
+	assert.Equal(t, int64(1), analytics.PaymentsFailed)
+	assert.InDelta(t, 0.5, analytics.PaymentFailureRate, 0.001)
+
+	// the same period is served from the cache until it expires
+	db.DB.Create(&models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 100000, Description: "Late", Status: constants.ExpenseStatusPending, SubmittedAt: feb})
+	body, _ = dashboard("from=2026-01-01&to=2026-02-28")
+	assert.Equal(t, int64(4), body.Analytics.Totals.Count)
+	assert.Equal(t, analytics.GeneratedAt, body.Analytics.GeneratedAt)
+
+	from, to := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, cached, err := services.CachedSpendAnalytics(db.DB, &from, &to, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, cached)
+
+	_, w = dashboard("from=2026-03-01&to=2026-01-01")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,147 @@
+package actions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+	"backend/workers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditChain_DetectsTampering(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	for i := 0; i < 4; i++ {
+		err := helpers.AppendExpenseAuditLog(db.DB, &models.ExpenseAuditLog{
+			ExpenseID:  1,
+			FromStatus: constants.ExpenseStatusPending,
+			ToStatus:   constants.ExpenseStatusApproved,
+			Reason:     "Approved",
+		})
+		assert.NoError(t, err)
+	}
+
+	verify := func() helpers.AuditChainReport {
+		w := jsonRequest(router, http.MethodGet, "/api/manager/expense-logs/verify", nil, cookies)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report helpers.AuditChainReport
+		json.Unmarshal(w.Body.Bytes(), &report)
+		return report
+	}
+
+	report := verify()
+	assert.True(t, report.Valid)
+	assert.Equal(t, int64(4), report.Entries)
+	assert.Equal(t, int64(4), report.HeadSeq)
+
+	// anchored once, nothing new to anchor the second time
+	worker := workers.NewAuditAnchorWorker()
+	assert.NoError(t, worker.RunOnce(time.Now().UTC()))
+	assert.NoError(t, worker.RunOnce(time.Now().UTC()))
+	var anchors int64
+	db.DB.Model(&models.AuditAnchor{}).Count(&anchors)
+	assert.Equal(t, int64(1), anchors)
+	assert.Equal(t, 1, verify().Anchors)
+
+	// edited entry
+	db.DB.Model(&models.ExpenseAuditLog{}).Where("seq = ?", 2).Update("reason", "Rejected")
+	report = verify()
+	assert.False(t, report.Valid)
+	assert.Equal(t, int64(2), report.BrokenAt.Seq)
+	assert.Equal(t, rules.ErrAuditHashMismatch.Error(), report.BrokenAt.Error)
+	db.DB.Model(&models.ExpenseAuditLog{}).Where("seq = ?", 2).Update("reason", "Approved")
+	assert.True(t, verify().Valid)
+
+	// removed from the end, head and anchor moved back with it
+	db.DB.Where("seq = ?", 4).Delete(&models.ExpenseAuditLog{})
+	var third models.ExpenseAuditLog
+	db.DB.First(&third, "seq = ?", 3)
+	db.DB.Model(&models.AuditChainHead{}).Where("chain = ?", helpers.ExpenseAuditChain).
+		Updates(map[string]interface{}{"seq": 3, "hash": *third.Hash})
+	report = verify()
+	assert.False(t, report.Valid)
+	assert.Equal(t, rules.ErrAuditChainTruncated.Error(), report.BrokenAt.Error)
+
+	// removed from the middle
+	db.DB.Where("seq = ?", 2).Delete(&models.ExpenseAuditLog{})
+	report = verify()
+	assert.False(t, report.Valid)
+	assert.Equal(t, int64(3), report.BrokenAt.Seq)
+	assert.Equal(t, rules.ErrAuditSequenceGap.Error(), report.BrokenAt.Error)
+
+	// a broken chain is not anchored
+	assert.NoError(t, worker.RunOnce(time.Now().UTC()))
+	db.DB.Model(&models.AuditAnchor{}).Count(&anchors)
+	assert.Equal(t, int64(1), anchors)
+}
+
+func TestAuditChain_AnchorsAreNumberedSignedAndExported(t *testing.T) {
+	setupUserAdmin(t)
+	exportFile := filepath.Join(t.TempDir(), "anchors.jsonl")
+	t.Setenv("AUDIT_ANCHOR_EXPORT_FILE", exportFile)
+
+	worker := workers.NewAuditAnchorWorker()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, helpers.AppendExpenseAuditLog(db.DB, &models.ExpenseAuditLog{
+			ExpenseID:  1,
+			FromStatus: constants.ExpenseStatusPending,
+			ToStatus:   constants.ExpenseStatusApproved,
+			Reason:     "Approved",
+		}))
+		assert.NoError(t, worker.RunOnce(time.Now().UTC()))
+	}
+
+	verify := func() *helpers.AuditChainReport {
+		report, err := helpers.VerifyExpenseAuditChain(db.DB)
+		assert.NoError(t, err)
+		return report
+	}
+
+	// the anchor key does not rotate with the JWT keys
+	_, err := services.Keys().Rotate()
+	assert.NoError(t, err)
+	report := verify()
+	assert.True(t, report.Valid)
+	assert.Equal(t, 3, report.Anchors)
+	assert.Equal(t, 3, report.ExportedAnchors)
+	assert.NotEmpty(t, report.AnchorPublicKey)
+
+	exported, _ := os.ReadFile(exportFile)
+	assert.Equal(t, 3, strings.Count(string(exported), report.AnchorPublicKey))
+
+	// anchors another key signed are not trusted
+	t.Setenv("AUDIT_ANCHOR_KEY", base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	report = verify()
+	assert.False(t, report.Valid)
+	assert.Equal(t, rules.ErrAuditAnchorForged.Error(), report.BrokenAt.Error)
+	t.Setenv("AUDIT_ANCHOR_KEY", "")
+
+	// the last anchor removed from the database, the export still has it
+	db.DB.Where("number = ?", 3).Delete(&models.AuditAnchor{})
+	report = verify()
+	assert.False(t, report.Valid)
+	assert.Equal(t, int64(3), report.BrokenAt.Seq)
+	assert.Equal(t, rules.ErrAuditAnchorMissing.Error(), report.BrokenAt.Error)
+
+	// without the export a removed anchor still leaves a gap in the numbers
+	t.Setenv("AUDIT_ANCHOR_EXPORT_FILE", "")
+	assert.True(t, verify().Valid)
+	db.DB.Where("number = ?", 1).Delete(&models.AuditAnchor{})
+	report = verify()
+	assert.False(t, report.Valid)
+	assert.Equal(t, int64(2), report.BrokenAt.Seq)
+	assert.Equal(t, rules.ErrAuditAnchorMissing.Error(), report.BrokenAt.Error)
+}
@@ -0,0 +1,66 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEvents_RecordsChangesWithActor(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+
+	w := jsonRequest(router, http.MethodPost, "/api/manager/users", map[string]interface{}{
+		"email": "bob@user.com", "name": "Bob User", "role": "user", "password": "Expense-Tracker-7",
+	}, cookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var bob models.User
+	json.Unmarshal(w.Body.Bytes(), &bob)
+	bobID := strconv.FormatInt(bob.ID, 10)
+
+	w = jsonRequest(router, http.MethodPut, "/api/manager/users/"+bobID, map[string]interface{}{
+		"name": "Robert User", "role": "user",
+	}, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	fetch := func(query string) []models.AuditEvent {
+		events, code := listData[models.AuditEvent](router, "/api/manager/audit-events?"+query, cookies)
+		assert.Equal(t, http.StatusOK, code)
+		return events
+	}
+
+	events := fetch("entity_type=user&entity_id=" + bobID)
+	assert.Len(t, events, 2)
+
+	// newest first, only the changed field
+	update := events[0]
+	assert.Equal(t, constants.AuditActionUserUpdate, update.Action)
+	assert.Equal(t, alice.ID, *update.ActorID)
+	assert.NotEmpty(t, update.IPAddress)
+	assert.Len(t, update.Changes, 1)
+	assert.Equal(t, "Bob User", update.Changes["name"].Before)
+	assert.Equal(t, "Robert User", update.Changes["name"].After)
+
+	// secrets hidden from JSON stay out of the trail
+	create := events[1]
+	assert.Equal(t, constants.AuditActionUserCreate, create.Action)
+	assert.Contains(t, create.Changes, "email")
+	assert.NotContains(t, create.Changes, "password_hash")
+
+	// a trailing dot matches the group
+	logins := fetch("action=auth.&actor_id=" + strconv.FormatInt(alice.ID, 10))
+	assert.Len(t, logins, 1)
+	assert.Equal(t, string(constants.LoginResultSuccess), logins[0].Metadata["result"])
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/audit-events?from=yesterday", nil, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,95 @@
+package actions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withoutCSRF(cookies []*http.Cookie, forged string) []*http.Cookie {
+	var result []*http.Cookie
+	for _, cookie := range cookies {
+		if cookie.Name == "csrf_token" {
+			if forged == "" {
+				continue
+			}
+			cookie = &http.Cookie{Name: cookie.Name, Value: forged}
+		}
+		result = append(result, cookie)
+	}
+	return result
+}
+
+func TestCSRF_MutatingRequestsNeedSessionToken(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+	invite := map[string]string{"email": "carol@user.com"}
+
+	// reads do not need the token
+	w := jsonRequest(router, http.MethodGet, "/api/manager/invites", nil, withoutCSRF(cookies, ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", invite, withoutCSRF(cookies, ""))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// a cookie the attacker planted does not match the session
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", invite, withoutCSRF(cookies, "forged-token"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", invite, cookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// refresh rotates the token
+	w = jsonRequest(router, http.MethodPost, "/api/auth/refresh", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	newCookies := w.Result().Cookies()
+
+	var oldToken string
+	for _, cookie := range cookies {
+		if cookie.Name == "csrf_token" {
+			oldToken = cookie.Value
+		}
+	}
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", invite, withoutCSRF(newCookies, oldToken))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", invite, newCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// no path lets a cookie request through without the token, the former exemption setting is ignored
+	t.Setenv("CSRF_EXEMPT_PATHS", "/api/manager/invites")
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", map[string]string{"email": "dave@user.com"}, withoutCSRF(newCookies, ""))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCSRF_LogoutNeedsSessionToken(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	w := jsonRequest(router, http.MethodPost, "/api/auth/logout", nil, withoutCSRF(cookies, ""))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = jsonRequest(router, http.MethodPost, "/api/auth/logout", nil, withoutCSRF(cookies, "forged-token"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// the forged requests left the session alive
+	w = jsonRequest(router, http.MethodGet, "/api/manager/invites", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/logout", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = jsonRequest(router, http.MethodGet, "/api/manager/invites", nil, cookies)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// a client sending its refresh token in the body is not exposed to forgery
+	login := loginAs(t, router, "alice@manager.com", "manager-pass")
+	var refreshToken string
+	for _, cookie := range login {
+		if cookie.Name == "refresh_token" {
+			refreshToken = cookie.Value
+		}
+	}
+	w = jsonRequest(router, http.MethodPost, "/api/auth/logout", map[string]string{"refresh_token": refreshToken}, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = jsonRequest(router, http.MethodPost, "/api/auth/refresh", map[string]string{"refresh_token": refreshToken}, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,114 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/controllers"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExpenses_CursorPagination(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+
+	// two expenses share each updated_at, only the id tells them apart
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	var expenses []models.Expense
+	for i := 0; i < 5; i++ {
+		expense := models.Expense{UUID: uuid.New(), UserID: alice.ID, AmountIDR: int64(100000 * (i + 1)), Description: "Expense", Status: constants.ExpenseStatusPending}
+		db.DB.Create(&expense)
+		db.DB.Model(&expense).UpdateColumn("updated_at", base.Add(time.Duration(i/2)*time.Hour))
+		expenses = append(expenses, expense)
+	}
+
+	list := func(path, query string) ([]models.Expense, controllers.PaginationMeta, int) {
+		var body struct {
+			Data []models.Expense           `json:"data"`
+			Meta controllers.PaginationMeta `json:"meta"`
+		}
+		code := getJSON(router, path+"?"+query, cookies, &body)
+		return body.Data, body.Meta, code
+	}
+
+	rows, meta, code := list("/api/manager/expenses", "limit=2&cursor=")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{expenses[4].ID, expenses[3].ID}, expenseIDs(rows))
+	assert.Equal(t, 0, meta.Page)
+	assert.Equal(t, int64(5), meta.Total)
+	assert.NotNil(t, meta.NextCursor)
+
+	// an edit while paging moves the row to the front, offsets would repeat a row
+	db.DB.Model(&expenses[0]).UpdateColumn("updated_at", base.Add(24*time.Hour))
+
+	rows, meta, _ = list("/api/manager/expenses", "limit=2&cursor="+url.QueryEscape(*meta.NextCursor))
+	assert.Equal(t, []int64{expenses[2].ID, expenses[1].ID}, expenseIDs(rows))
+	assert.Nil(t, meta.NextCursor)
+
+	// ascending amount, the cursor keeps its sort
+	rows, meta, _ = list("/api/manager/expenses", "limit=3&sort=amount&order=asc&cursor=")
+	assert.Equal(t, []int64{expenses[0].ID, expenses[1].ID, expenses[2].ID}, expenseIDs(rows))
+	next := *meta.NextCursor
+	rows, _, _ = list("/api/manager/expenses", "limit=3&sort=amount&order=asc&cursor="+url.QueryEscape(next))
+	assert.Equal(t, []int64{expenses[3].ID, expenses[4].ID}, expenseIDs(rows))
+
+	_, _, code = list("/api/manager/expenses", "limit=3&sort=amount&order=desc&cursor="+url.QueryEscape(next))
+	assert.Equal(t, http.StatusBadRequest, code)
+	_, _, code = list("/api/manager/expenses", "cursor=not-a-cursor")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// page and limit still work, and hand out a cursor to continue with
+	rows, meta, _ = list("/api/manager/expenses", "page=2&limit=2")
+	assert.Equal(t, 2, meta.Page)
+	assert.Len(t, rows, 2)
+	assert.NotNil(t, meta.NextCursor)
+}
+
+func TestGetExpenseAuditLog_CursorPagination(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	expense := models.Expense{UUID: uuid.New(), UserID: alice.ID, AmountIDR: 500000, Description: "Taxi", Status: constants.ExpenseStatusCompleted}
+	db.DB.Create(&expense)
+
+	// worker entries have no actor name, they sort as an empty name
+	for _, actorID := range []*int64{&alice.ID, nil, &alice.ID, nil} {
+		entry := models.ExpenseAuditLog{ExpenseID: expense.ID, ActorID: actorID, FromStatus: constants.ExpenseStatusPending, ToStatus: constants.ExpenseStatusApproved}
+		assert.NoError(t, helpers.AppendExpenseAuditLog(db.DB, &entry))
+	}
+
+	var ids []int64
+	cursor := ""
+	for pages := 0; pages < 5; pages++ {
+		w := jsonRequest(router, http.MethodGet, "/api/manager/expense-logs?sort=actor_name&order=asc&limit=3&cursor="+url.QueryEscape(cursor), nil, cookies)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data []controllers.ExpenseAuditLogRow `json:"data"`
+			Meta controllers.PaginationMeta       `json:"meta"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		for _, row := range body.Data {
+			ids = append(ids, row.ID)
+		}
+		if body.Meta.NextCursor == nil {
+			break
+		}
+		cursor = *body.Meta.NextCursor
+	}
+
+	var entries []models.ExpenseAuditLog
+	db.DB.Order("seq").Find(&entries)
+	assert.Equal(t, []int64{entries[1].ID, entries[3].ID, entries[0].ID, entries[2].ID}, ids)
+}
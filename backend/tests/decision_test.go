@@ -0,0 +1,286 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/controllers"
+	"backend/db"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// pendingExpense stores an expense of userID waiting for approval
+func pendingExpense(t *testing.T, userID int64, amountIDR int64) models.Expense {
+	t.Helper()
+	expense := models.Expense{UUID: uuid.New(), UserID: userID, AmountIDR: amountIDR, Description: "Conference", Status: constants.ExpenseStatusPending,
+		RequiresApproval: true, SubmittedAt: time.Now().UTC()}
+	db.DB.Create(&expense)
+	db.DB.Create(&models.Approval{ExpenseID: expense.ID, Status: constants.ApprovalStatusPending})
+	return expense
+}
+
+func TestDecision_DelegateIsBoundByDelegatorSeparationOfDuties(t *testing.T) {
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none")
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	carol := createUser(t, "carol@user.com", "Carol Director", "")
+	bob := createUser(t, "bob@user.com", "Bob Lead", "")
+	dave := createUser(t, "dave@user.com", "Dave User", "")
+	db.DB.Model(&bob).Update("manager_id", carol.ID)
+	db.DB.Model(&dave).Update("manager_id", bob.ID)
+
+	now := time.Now().UTC()
+	db.DB.Create(&models.ApprovalDelegation{DelegatorID: bob.ID, DelegateID: alice.ID, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)})
+	db.DB.Create(&models.ApprovalDelegation{DelegatorID: carol.ID, DelegateID: alice.ID, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)})
+
+	approve := func(expense models.Expense, onBehalfOf int64) (int, string) {
+		w := jsonRequest(router, http.MethodPut, "/api/manager/expenses/"+strconv.FormatInt(expense.ID, 10)+"/approve",
+			map[string]interface{}{"notes": "ok", "on_behalf_of_id": onBehalfOf}, cookies)
+		return w.Code, w.Body.String()
+	}
+
+	// the delegator's own expense
+	own := pendingExpense(t, bob.ID, 2000000)
+	code, body := approve(own, bob.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Contains(t, body, rules.ErrDelegatedOwnExpense.Error())
+
+	// an expense of the delegator's superior
+	superior := pendingExpense(t, carol.ID, 2000000)
+	code, body = approve(superior, bob.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Contains(t, body, rules.ErrSuperiorApproval.Error())
+
+	// an expired delegation grants nothing
+	report := pendingExpense(t, dave.ID, 2000000)
+	code, body = approve(report, carol.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Contains(t, body, rules.ErrNoActiveDelegation.Error())
+
+	code, _ = approve(report, bob.ID)
+	assert.Equal(t, http.StatusOK, code)
+	var approval models.Approval
+	db.DB.First(&approval, "expense_id = ?", report.ID)
+	assert.Equal(t, alice.ID, *approval.ApproverID)
+	assert.Equal(t, bob.ID, *approval.OnBehalfOfID)
+
+	for _, expense := range []models.Expense{own, superior} {
+		db.DB.First(&expense, expense.ID)
+		assert.Equal(t, constants.ExpenseStatusPending, expense.Status)
+	}
+}
+
+func TestBulkDecision_DecidesEachExpenseOnItsOwn(t *testing.T) {
+	var mu sync.Mutex
+	paid := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payment struct {
+			ExternalID string `json:"external_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&payment)
+		mu.Lock()
+		paid[payment.ExternalID]++
+		mu.Unlock()
+		w.Write([]byte(`{"data":{"id":"pay_1","external_id":"x","status":"completed"},"message":"ok"}`))
+	}))
+	defer server.Close()
+	t.Setenv("PAYMENT_BASE_URL", server.URL)
+
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+
+	hotel := pendingExpense(t, bob.ID, 2000000)
+	flight := pendingExpense(t, bob.ID, 3000000)
+	own := pendingExpense(t, alice.ID, 2500000)
+	gift := pendingExpense(t, bob.ID, 1500000)
+	settled := pendingExpense(t, bob.ID, 1000000)
+	db.DB.Model(&settled).Update("status", constants.ExpenseStatusRejected)
+
+	bulk := func(decision string, ids ...int64) (controllers.BulkDecisionResponse, int) {
+		w := jsonRequest(router, http.MethodPost, "/api/manager/expenses/bulk-decision",
+			map[string]interface{}{"expense_ids": ids, "decision": decision, "notes": "Quarter close"}, cookies)
+		var body controllers.BulkDecisionResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body, w.Code
+	}
+
+	// the second factor is checked once for the whole batch
+	_, code := bulk("approve", hotel.ID, flight.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	db.DB.First(&hotel, hotel.ID)
+	assert.Equal(t, constants.ExpenseStatusPending, hotel.Status)
+
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none")
+
+	body, code := bulk("reject", gift.ID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, body.Succeeded)
+
+	body, code = bulk("approve", hotel.ID, own.ID, settled.ID, 999999, flight.ID, hotel.ID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, body.Succeeded)
+	assert.Equal(t, 3, body.Failed)
+	assert.Equal(t, []controllers.BulkDecisionResult{
+		{ExpenseID: hotel.ID, Success: true, Status: constants.ExpenseStatusApproved},
+		{ExpenseID: own.ID, Error: rules.ErrSelfApproval.Error()},
+		{ExpenseID: settled.ID, Error: rules.ErrInvalidStatusTransition.Error()},
+		{ExpenseID: 999999, Error: "Expense not found"},
+		{ExpenseID: flight.ID, Success: true, Status: constants.ExpenseStatusApproved},
+	}, body.Data)
+
+	// a decided expense is not decided again, its audit trail and ledger keep one entry
+	body, _ = bulk("reject", hotel.ID)
+	assert.Equal(t, 1, body.Failed)
+	var audits, entries int64
+	db.DB.Model(&models.ExpenseAuditLog{}).Where("expense_id = ? AND from_status = ?", hotel.ID, constants.ExpenseStatusPending).Count(&audits)
+	db.DB.Model(&models.JournalEntry{}).Where("expense_id = ? AND kind = ?", hotel.ID, constants.JournalEntryKindApproval).Count(&entries)
+	assert.Equal(t, int64(1), audits)
+	assert.Equal(t, int64(1), entries)
+
+	// payments are queued for the approved expenses only
+	assert.Eventually(t, func() bool {
+		var completed int64
+		db.DB.Model(&models.Expense{}).Where("id IN ? AND status = ?", []int64{hotel.ID, flight.ID}, constants.ExpenseStatusCompleted).Count(&completed)
+		return completed == 2
+	}, 15*time.Second, 100*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]int{hotel.UUID.String(): 1, flight.UUID.String(): 1}, paid)
+}
+
+func TestDecision_SubmitterComesFromTheSession(t *testing.T) {
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none")
+	router, aliceCookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "user-pass")
+	db.DB.Model(&bob).Update("manager_id", alice.ID)
+	bobCookies := loginAs(t, router, "bob@user.com", "user-pass")
+
+	submitAs := func(cookies []*http.Cookie, userID int64) int {
+		return jsonRequest(router, http.MethodPost, "/api/user/expenses", map[string]interface{}{
+			"user_id": userID, "amount_idr": 2500000, "description": "Conference ticket",
+		}, cookies).Code
+	}
+
+	// the manager cannot file an expense under bob's name to approve it afterwards
+	assert.Equal(t, http.StatusForbidden, submitAs(aliceCookies, bob.ID))
+	var count int64
+	db.DB.Model(&models.Expense{}).Count(&count)
+	assert.Zero(t, count)
+
+	// a user_id in the body is ignored, the expense is bob's
+	assert.Equal(t, http.StatusCreated, submitAs(bobCookies, alice.ID))
+	var expense models.Expense
+	db.DB.Last(&expense)
+	assert.Equal(t, bob.ID, expense.UserID)
+	assert.Equal(t, constants.ExpenseStatusPending, expense.Status)
+
+	// and so the manager decides it as bob's manager, rejecting keeps the payment worker out of the test
+	w := jsonRequest(router, http.MethodPut, "/api/manager/expenses/"+strconv.FormatInt(expense.ID, 10)+"/reject", map[string]string{"notes": "Over budget"}, aliceCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDecision_SecondStepNeedsAnotherApprover(t *testing.T) {
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none")
+	router, aliceCookies := setupUserAdmin(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"id":"pay_1","external_id":"x","status":"completed"},"message":"ok"}`))
+	}))
+	defer server.Close()
+	t.Setenv("PAYMENT_BASE_URL", server.URL)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	carol := createUser(t, "carol@manager.com", "Carol Lead", "lead-pass")
+	db.DB.Model(&carol).Updates(map[string]interface{}{"role": constants.UserRoleManager, "manager_id": alice.ID})
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	db.DB.Model(&bob).Update("manager_id", carol.ID)
+	carolCookies := loginAs(t, router, "carol@manager.com", "lead-pass")
+
+	expense := pendingExpense(t, bob.ID, constants.SecondApprovalThreshold)
+	approve := "/api/manager/expenses/" + strconv.FormatInt(expense.ID, 10) + "/approve"
+
+	// the first approval moves the expense to carol's manager
+	w := jsonRequest(router, http.MethodPut, approve, map[string]string{"notes": "Fine by me"}, carolCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stored models.Expense
+	db.DB.Preload("Approval.Steps").First(&stored, expense.ID)
+	assert.Equal(t, constants.ExpenseStatusPending, stored.Status)
+	assert.Equal(t, 2, stored.Approval.Step)
+	assert.Equal(t, &alice.ID, stored.Approval.AssigneeID)
+	if assert.Len(t, stored.Approval.Steps, 1) {
+		assert.Equal(t, carol.ID, stored.Approval.Steps[0].ApproverID)
+	}
+
+	// carol cannot approve the second step as well
+	w = jsonRequest(router, http.MethodPut, approve, map[string]string{"notes": "Again"}, carolCookies)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), rules.ErrSequentialApproval.Error())
+
+	w = jsonRequest(router, http.MethodPut, approve, map[string]string{"notes": "Confirmed"}, aliceCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var reasons []string
+	db.DB.Model(&models.ExpenseAuditLog{}).Where("expense_id = ?", expense.ID).Order("id").Pluck("reason", &reasons)
+	assert.Equal(t, []string{"Expense approved (step 1 of 2)", "Blocked: " + rules.ErrSequentialApproval.Error(), "Expense approved"}, reasons)
+
+	assert.Eventually(t, func() bool {
+		var paid models.Expense
+		db.DB.First(&paid, expense.ID)
+		return paid.Status == constants.ExpenseStatusCompleted
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestDecision_FailedCommitIsNotReportedAsDecided(t *testing.T) {
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none")
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	db.DB.Model(&bob).Update("manager_id", alice.ID)
+	expense := pendingExpense(t, bob.ID, 2500000)
+
+	// a deferred foreign key is only checked at commit, the trigger breaks it on every decision
+	var file string
+	db.DB.Raw("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file)
+	gdb, err := gorm.Open(sqlite.Open(file+"?_foreign_keys=on"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	db.DB = gdb
+	assert.NoError(t, gdb.Exec("CREATE TABLE commit_guard (user_id INTEGER REFERENCES users(id) DEFERRABLE INITIALLY DEFERRED)").Error)
+	assert.NoError(t, gdb.Exec("CREATE TRIGGER fail_commit AFTER UPDATE ON approvals BEGIN INSERT INTO commit_guard VALUES (-1); END").Error)
+
+	w := jsonRequest(router, http.MethodPut, "/api/manager/expenses/"+strconv.FormatInt(expense.ID, 10)+"/reject", map[string]string{"notes": "Over budget"}, cookies)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var stored models.Expense
+	db.DB.First(&stored, expense.ID)
+	assert.Equal(t, constants.ExpenseStatusPending, stored.Status)
+
+	var decided int64
+	db.DB.Model(&models.AuditEvent{}).Where("action = ?", constants.AuditActionApprovalDecide).Count(&decided)
+	assert.Zero(t, decided)
+}
@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportExpenses_CSVAndXLSX(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "user-pass")
+
+	submitted := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC) // 16:00 in Jakarta
+	hotel := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 1500000, Description: "Hotel <Bandung> & more", Category: constants.ExpenseCategoryLodging, Status: constants.ExpenseStatusApproved, SubmittedAt: submitted}
+	formula := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 50000, Description: "=cmd()", Category: constants.ExpenseCategoryMeals, Status: constants.ExpenseStatusPending, SubmittedAt: submitted}
+	own := models.Expense{UUID: uuid.New(), UserID: alice.ID, AmountIDR: 75000, Description: "Taxi", Category: constants.ExpenseCategoryTransport, Status: constants.ExpenseStatusPending, SubmittedAt: submitted}
+	for _, expense := range []*models.Expense{&hotel, &formula, &own} {
+		db.DB.Create(expense)
+	}
+	db.DB.Create(&models.Approval{ExpenseID: hotel.ID, ApproverID: &alice.ID, Status: constants.ApprovalStatusApproved})
+
+	w := jsonRequest(router, http.MethodGet, "/api/manager/expenses/export?category=lodging,meals&sort=amount&order=desc", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "Submitted At (Asia/Jakarta)", records[0][2])
+	assert.Equal(t, []string{"2026-01-10 16:00:00", "Bob User", "lodging", "Hotel <Bandung> & more", "Rp 1.500.000", "approved", "Alice Manager", ""},
+		records[1][2:10])
+	assert.Equal(t, "'=cmd()", records[2][5])
+	assert.Equal(t, "Rp 50.000", records[2][6])
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/expenses/export?format=xlsx&q=hotel", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+	sheet := readXLSXSheet(t, w.Body.Bytes())
+	assert.Contains(t, sheet, `<c r="F2" s="0" t="inlineStr"><is><t xml:space="preserve">Hotel &lt;Bandung&gt; &amp; more</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="G2" s="2"><v>1500000</v></c>`)
+	// 2026-01-10 16:00 is day 46032 and two thirds
+	assert.Contains(t, sheet, `<c r="C2" s="3"><v>46032.666666666`)
+	assert.Equal(t, 2, strings.Count(sheet, "<row "))
+
+	for _, query := range []string{"format=pdf", "sort=description"} {
+		w = jsonRequest(router, http.MethodGet, "/api/manager/expenses/export?"+query, nil, cookies)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// users only ever export their own expenses
+	bobCookies := loginAs(t, router, "bob@user.com", "user-pass")
+	w = jsonRequest(router, http.MethodGet, "/api/user/expenses/export", nil, bobCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	records, _ = csv.NewReader(w.Body).ReadAll()
+	assert.Len(t, records, 3)
+	for _, record := range records[1:] {
+		assert.Equal(t, "Bob User", record[3])
+	}
+	w = jsonRequest(router, http.MethodGet, "/api/user/expenses/export?user_id="+strconv.FormatInt(alice.ID, 10), nil, bobCookies)
+	records, _ = csv.NewReader(w.Body).ReadAll()
+	assert.Len(t, records, 1)
+}
+
+func TestFormatIDR(t *testing.T) {
+	assert.Equal(t, "Rp 0", helpers.FormatIDR(0))
+	assert.Equal(t, "Rp 999", helpers.FormatIDR(999))
+	assert.Equal(t, "Rp 10.000", helpers.FormatIDR(10000))
+	assert.Equal(t, "Rp 50.000.000", helpers.FormatIDR(50000000))
+	assert.Equal(t, "-Rp 1.250", helpers.FormatIDR(-1250))
+}
+
+func readXLSXSheet(t *testing.T, data []byte) string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+	for _, file := range archive.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			reader, _ := file.Open()
+			defer reader.Close()
+			sheet, _ := io.ReadAll(reader)
+			return string(sheet)
+		}
+	}
+	t.Fatal("sheet missing from workbook")
+	return ""
+}
@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/constants"
+	"backend/controllers"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpenseAuditLog_FiltersSortAndExport(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+
+	small := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 500000, Description: "Taxi", Status: constants.ExpenseStatusApproved}
+	large := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 9000000, Description: "=HYPERLINK(\"x\")", Status: constants.ExpenseStatusRejected}
+	db.DB.Create(&small)
+	db.DB.Create(&large)
+
+	for _, entry := range []models.ExpenseAuditLog{
+		{ExpenseID: small.ID, ActorID: &alice.ID, FromStatus: constants.ExpenseStatusPending, ToStatus: constants.ExpenseStatusApproved, Reason: "Expense approved"},
+		{ExpenseID: large.ID, ActorID: &alice.ID, FromStatus: constants.ExpenseStatusPending, ToStatus: constants.ExpenseStatusRejected, Reason: "Expense rejected"},
+		{ExpenseID: small.ID, FromStatus: constants.ExpenseStatusApproved, ToStatus: constants.ExpenseStatusCompleted, Reason: "Expense completed by payment processing"},
+	} {
+		assert.NoError(t, helpers.AppendExpenseAuditLog(db.DB, &entry))
+	}
+
+	list := func(query string) ([]controllers.ExpenseAuditLogRow, int) {
+		return listData[controllers.ExpenseAuditLogRow](router, "/api/manager/expense-logs?"+query, cookies)
+	}
+
+	rows, code := list("actor_id=" + strconv.FormatInt(alice.ID, 10) + "&to_status=rejected")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "Alice Manager", *rows[0].ActorName)
+	assert.Equal(t, "Bob User", rows[0].Expense.SubmitterName)
+	assert.Equal(t, int64(9000000), rows[0].Expense.AmountIDR)
+
+	rows, _ = list("reason=PAYMENT")
+	assert.Len(t, rows, 1)
+	assert.Nil(t, rows[0].ActorName)
+
+	rows, _ = list("sort=amount&order=asc")
+	assert.Len(t, rows, 3)
+	assert.Equal(t, large.ID, rows[2].ExpenseID)
+
+	rows, _ = list("from=2000-01-01&to=2000-01-31")
+	assert.Len(t, rows, 0)
+
+	_, code = list("sort=password_hash")
+	assert.Equal(t, http.StatusBadRequest, code)
+	_, code = list("from=2030-01-01&to=2020-01-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// the export follows the same filters and defuses formulas
+	w := jsonRequest(router, http.MethodGet, "/api/manager/expense-logs/export?expense_id="+strconv.FormatInt(large.ID, 10), nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "expense_description", records[0][4])
+	assert.Equal(t, "'=HYPERLINK(\"x\")", records[1][4])
+	assert.Equal(t, "Alice Manager", records[1][8])
+}
@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/actions"
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitExpense_Category(t *testing.T) {
+	input := actions.SubmitExpenseInput{UserID: 1, AmountIDR: 50000, Description: "Lunch"}
+	expense, _, err := actions.SubmitExpense(input)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ExpenseCategoryOther, expense.Category)
+
+	input.Category = constants.ExpenseCategoryMeals
+	expense, _, err = actions.SubmitExpense(input)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ExpenseCategoryMeals, expense.Category)
+
+	input.Category = "yachts"
+	_, _, err = actions.SubmitExpense(input)
+	assert.ErrorIs(t, err, rules.ErrInvalidCategory)
+}
+
+func TestGetExpenses_Search(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+
+	jan := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	hotel := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 2500000, Description: "Hotel Jakarta", Category: constants.ExpenseCategoryLodging, Status: constants.ExpenseStatusApproved, SubmittedAt: jan}
+	taxi := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 150000, Description: "Taxi to airport", Category: constants.ExpenseCategoryTransport, Status: constants.ExpenseStatusCompleted, SubmittedAt: feb}
+	dinner := models.Expense{UUID: uuid.New(), UserID: alice.ID, AmountIDR: 1200000, Description: "Team dinner", Category: constants.ExpenseCategoryMeals, Status: constants.ExpenseStatusPending, SubmittedAt: feb}
+	for _, expense := range []*models.Expense{&hotel, &taxi, &dinner} {
+		db.DB.Create(expense)
+	}
+	db.DB.Create(&models.Approval{ExpenseID: hotel.ID, ApproverID: &alice.ID, Status: constants.ApprovalStatusApproved})
+	db.DB.Create(&models.Approval{ExpenseID: dinner.ID, AssigneeID: &alice.ID, Status: constants.ApprovalStatusPending})
+
+	list := func(query string) ([]models.Expense, int) {
+		return listData[models.Expense](router, "/api/manager/expenses?"+query, cookies)
+	}
+
+	rows, code := list("q=HOTE")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{hotel.ID}, expenseIDs(rows))
+
+	rows, _ = list("min_amount=1000000&max_amount=2000000")
+	assert.Equal(t, []int64{dinner.ID}, expenseIDs(rows))
+
+	rows, _ = list("submitted_from=2026-02-01&submitted_to=2026-02-10&sort=amount&order=asc")
+	assert.Equal(t, []int64{taxi.ID, dinner.ID}, expenseIDs(rows))
+
+	rows, _ = list("category=lodging,transport&user_id=" + strconv.FormatInt(bob.ID, 10) + "&sort=submitted_at&order=desc")
+	assert.Equal(t, []int64{taxi.ID, hotel.ID}, expenseIDs(rows))
+
+	rows, _ = list("status=pending,approved&sort=amount&order=desc")
+	assert.Equal(t, []int64{hotel.ID, dinner.ID}, expenseIDs(rows))
+
+	rows, _ = list("approver_id=" + strconv.FormatInt(alice.ID, 10))
+	assert.Equal(t, []int64{hotel.ID}, expenseIDs(rows))
+	rows, _ = list("assignee_id=" + strconv.FormatInt(alice.ID, 10))
+	assert.Equal(t, []int64{dinner.ID}, expenseIDs(rows))
+
+	for _, query := range []string{"sort=description", "order=sideways", "min_amount=abc", "min_amount=5&max_amount=1", "category=yachts", "submitted_from=yesterday"} {
+		_, code = list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
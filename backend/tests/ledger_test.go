@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJournalEntry(t *testing.T) {
+	line := func(account constants.LedgerAccount, debit, credit int64) models.JournalLine {
+		return models.JournalLine{Account: account, DebitIDR: debit, CreditIDR: credit}
+	}
+
+	for name, test := range map[string]struct {
+		lines []models.JournalLine
+		err   error
+	}{
+		"balanced": {[]models.JournalLine{
+			line(constants.LedgerAccountReimbursedExpense, 150000, 0),
+			line(constants.LedgerAccountEmployeePayable, 0, 100000),
+			line(constants.LedgerAccountEmployeePayable, 0, 50000),
+		}, nil},
+		"unbalanced":   {[]models.JournalLine{line(constants.LedgerAccountCash, 100, 0), line(constants.LedgerAccountEmployeePayable, 0, 99)}, rules.ErrUnbalancedEntry},
+		"one line":     {[]models.JournalLine{line(constants.LedgerAccountCash, 0, 0)}, rules.ErrJournalEntryTooShort},
+		"both sides":   {[]models.JournalLine{line(constants.LedgerAccountCash, 100, 100), line(constants.LedgerAccountEmployeePayable, 0, 0)}, rules.ErrInvalidJournalLine},
+		"negative":     {[]models.JournalLine{line(constants.LedgerAccountCash, -100, 0), line(constants.LedgerAccountEmployeePayable, 0, -100)}, rules.ErrInvalidJournalLine},
+		"unknown code": {[]models.JournalLine{line("9999", 100, 0), line(constants.LedgerAccountEmployeePayable, 0, 100)}, rules.ErrUnknownLedgerAccount},
+	} {
+		assert.Equal(t, test.err, rules.ValidateJournalEntry(test.lines), name)
+	}
+}
+
+func TestLedger_ApprovalSettlementAndReports(t *testing.T) {
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none")
+	router, cookies := setupUserAdmin(t)
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	carol := createUser(t, "carol@user.com", "Carol User", "")
+
+	now := time.Now().UTC()
+	hotel := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 2000000, Description: "Hotel", Status: constants.ExpenseStatusPending, RequiresApproval: true, SubmittedAt: now}
+	db.DB.Create(&hotel)
+	db.DB.Create(&models.Approval{ExpenseID: hotel.ID, Status: constants.ApprovalStatusPending})
+
+	// approving posts the liability once
+	path := "/api/manager/expenses/" + strconv.FormatInt(hotel.ID, 10) + "/approve"
+	w := jsonRequest(router, http.MethodPut, path, map[string]string{"notes": "ok"}, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = jsonRequest(router, http.MethodPut, path, map[string]string{"notes": "again"}, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var entries []models.JournalEntry
+	db.DB.Preload("Lines").Where("expense_id = ?", hotel.ID).Find(&entries)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, constants.JournalEntryKindApproval, entries[0].Kind)
+		assert.ElementsMatch(t, []models.JournalLine{
+			{Account: constants.LedgerAccountReimbursedExpense, UserID: &bob.ID, DebitIDR: 2000000},
+			{Account: constants.LedgerAccountEmployeePayable, UserID: &bob.ID, CreditIDR: 2000000},
+		}, withoutIDs(entries[0].Lines))
+	}
+
+	// paying settles it, a repeated completion posts nothing new
+	db.DB.First(&hotel, hotel.ID)
+	reference := "pay_1"
+	hotel.Status, hotel.ProcessedAt, hotel.PaymentReference = constants.ExpenseStatusCompleted, &now, &reference
+	assert.NoError(t, helpers.PostExpenseSettlement(db.DB, &hotel, now))
+	assert.NoError(t, helpers.PostExpenseSettlement(db.DB, &hotel, now))
+
+	// paid before the ledger existed, the approval is posted with the settlement
+	taxi := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 500000, Description: "Taxi", Status: constants.ExpenseStatusCompleted, SubmittedAt: now, ProcessedAt: &now}
+	db.DB.Create(&taxi)
+	assert.NoError(t, helpers.PostExpenseSettlement(db.DB, &taxi, now))
+
+	flight := models.Expense{UUID: uuid.New(), UserID: carol.ID, AmountIDR: 300000, Description: "Flight", Status: constants.ExpenseStatusApproved, SubmittedAt: now}
+	db.DB.Create(&flight)
+	assert.NoError(t, helpers.PostExpenseApproval(db.DB, &flight, now))
+
+	// an unbalanced entry never reaches the ledger
+	err := helpers.PostJournalEntry(db.DB, &models.JournalEntry{Kind: "adjustment", PostedAt: now, Lines: []models.JournalLine{
+		{Account: constants.LedgerAccountCash, DebitIDR: 1000},
+		{Account: constants.LedgerAccountEmployeePayable, UserID: &carol.ID, CreditIDR: 900},
+	}})
+	assert.ErrorIs(t, err, rules.ErrUnbalancedEntry)
+
+	var count int64
+	db.DB.Model(&models.JournalEntry{}).Count(&count)
+	assert.Equal(t, int64(5), count)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/ledger/trial-balance", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var balance helpers.TrialBalance
+	json.Unmarshal(w.Body.Bytes(), &balance)
+	assert.True(t, balance.Balanced)
+	assert.Equal(t, int64(5300000), balance.TotalDebitIDR)
+	assert.Equal(t, []helpers.TrialBalanceAccount{
+		{Account: constants.LedgerAccountCash, Name: "Cash", Type: constants.LedgerAccountTypeAsset, CreditIDR: 2500000, BalanceIDR: -2500000},
+		{Account: constants.LedgerAccountEmployeePayable, Name: "Employee reimbursements payable", Type: constants.LedgerAccountTypeLiability, DebitIDR: 2500000, CreditIDR: 2800000, BalanceIDR: 300000},
+		{Account: constants.LedgerAccountReimbursedExpense, Name: "Reimbursed employee expenses", Type: constants.LedgerAccountTypeExpense, DebitIDR: 2800000, BalanceIDR: 2800000},
+	}, balance.Accounts)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/ledger/trial-balance?to="+now.Add(-time.Hour).Format(time.RFC3339), nil, cookies)
+	json.Unmarshal(w.Body.Bytes(), &balance)
+	assert.Zero(t, balance.TotalDebitIDR)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/ledger/balances", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var balances struct {
+		Data []helpers.EmployeeBalance `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &balances)
+	assert.Equal(t, []helpers.EmployeeBalance{
+		{UserID: carol.ID, Name: "Carol User", ExpensedIDR: 300000, OwedIDR: 300000},
+		{UserID: bob.ID, Name: "Bob User", ExpensedIDR: 2500000, PaidIDR: 2500000},
+	}, balances.Data)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/ledger/balances?user_id=abc", nil, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/ledger/entries?user_id="+strconv.FormatInt(bob.ID, 10)+"&kind=settlement", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []models.JournalEntry `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, int64(2), list.Meta.Total)
+	if assert.Len(t, list.Data, 2) {
+		assert.Len(t, list.Data[0].Lines, 2)
+		assert.Contains(t, []string{list.Data[0].Memo, list.Data[1].Memo}, "Expense #"+strconv.FormatInt(hotel.ID, 10)+" paid, payment pay_1")
+	}
+}
+
+func withoutIDs(lines []models.JournalLine) []models.JournalLine {
+	stripped := make([]models.JournalLine, len(lines))
+	for i, line := range lines {
+		line.ID, line.JournalEntryID = 0, 0
+		stripped[i] = line
+	}
+	return stripped
+}
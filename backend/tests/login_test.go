@@ -0,0 +1,115 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottlePolicy_Check(t *testing.T) {
+	policy := rules.LoginThrottlePolicy{
+		Window:          15 * time.Minute,
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        8 * time.Second,
+		LockoutAttempts: 10,
+		LockoutDuration: 15 * time.Minute,
+		IPAttempts:      50,
+	}
+
+	assert.Equal(t, time.Duration(0), policy.Delay(2))
+	assert.Equal(t, time.Second, policy.Delay(3))
+	assert.Equal(t, 4*time.Second, policy.Delay(5))
+	assert.Equal(t, 8*time.Second, policy.Delay(9))
+
+	now := time.Now()
+
+	_, err := policy.Check(rules.LoginFailures{Account: 2, LastAccountFailure: now}, now)
+	assert.NoError(t, err)
+
+	wait, err := policy.Check(rules.LoginFailures{Account: 4, LastAccountFailure: now.Add(-time.Second)}, now)
+	assert.ErrorIs(t, err, rules.ErrLoginThrottled)
+	assert.Equal(t, time.Second, wait)
+
+	_, err = policy.Check(rules.LoginFailures{Account: 4, LastAccountFailure: now.Add(-3 * time.Second)}, now)
+	assert.NoError(t, err)
+
+	wait, err = policy.Check(rules.LoginFailures{Account: 10, LastAccountFailure: now.Add(-5 * time.Minute)}, now)
+	assert.ErrorIs(t, err, rules.ErrAccountLocked)
+	assert.Equal(t, 10*time.Minute, wait)
+
+	_, err = policy.Check(rules.LoginFailures{IP: 50, LastIPFailure: now}, now)
+	assert.ErrorIs(t, err, rules.ErrLoginThrottled)
+}
+
+func TestLogin_UniformErrorsAndLockout(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+	t.Setenv("LOGIN_BASE_DELAY", "0s")
+	t.Setenv("LOGIN_LOCKOUT_ATTEMPTS", "3")
+
+	w := jsonRequest(router, http.MethodPost, "/api/manager/users", map[string]interface{}{
+		"email": "bob@user.com", "name": "Bob User", "role": "user", "password": "Expense-Tracker-7",
+	}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	login := func(email, password string) (int, string) {
+		w := jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": email, "password": password}, nil)
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body.Error
+	}
+
+	// an unknown email and a wrong password look the same
+	unknownCode, unknownError := login("nobody@user.com", "Expense-Tracker-7")
+	wrongCode, wrongError := login("bob@user.com", "wrong-password")
+	assert.Equal(t, http.StatusUnauthorized, unknownCode)
+	assert.Equal(t, unknownCode, wrongCode)
+	assert.Equal(t, unknownError, wrongError)
+
+	login("Bob@User.com", "wrong-password")
+	login("bob@user.com", "wrong-password")
+
+	// locked, even with the right password
+	code, message := login("bob@user.com", "Expense-Tracker-7")
+	assert.Equal(t, http.StatusTooManyRequests, code)
+	assert.Equal(t, rules.ErrAccountLocked.Error(), message)
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "bob@user.com", "password": "Expense-Tracker-7"}, nil)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	var bob models.User
+	db.DB.First(&bob, "email = ?", "bob@user.com")
+	w = jsonRequest(router, http.MethodPost, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/unlock", nil, managerCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	code, _ = login("bob@user.com", "Expense-Tracker-7")
+	assert.Equal(t, http.StatusOK, code)
+
+	// every attempt is in the audit trail
+	w = jsonRequest(router, http.MethodGet, "/api/manager/login-attempts?email=bob@user.com", nil, managerCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var trail struct {
+		Data []models.LoginAttempt `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &trail)
+
+	results := map[constants.LoginResult]int{}
+	for _, attempt := range trail.Data {
+		results[attempt.Result]++
+	}
+	assert.Equal(t, 3, results[constants.LoginResultInvalidCredentials])
+	assert.Equal(t, 2, results[constants.LoginResultThrottled])
+	assert.Equal(t, 1, results[constants.LoginResultUnlocked])
+	assert.Equal(t, 1, results[constants.LoginResultSuccess])
+}
@@ -0,0 +1,310 @@
+package actions
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/controllers"
+	"backend/db"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// ---------- Mock identity provider ----------
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+
+	mu    sync.Mutex
+	codes map[string]url.Values // code -> authorize params
+}
+
+func newMockIdP(t *testing.T, claims jwt.MapClaims) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate IdP key: %v", err)
+	}
+
+	idp := &mockIdP{key: key, claims: claims, codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "idp-key",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	// the user "logs in" immediately and is sent back with a code
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		code := "code-" + params.Get("state")
+
+		idp.mu.Lock()
+		idp.codes[code] = params
+		idp.mu.Unlock()
+
+		http.Redirect(w, r, params.Get("redirect_uri")+"?code="+code+"&state="+params.Get("state"), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		idp.mu.Lock()
+		params, ok := idp.codes[r.Form.Get("code")]
+		delete(idp.codes, r.Form.Get("code"))
+		idp.mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != params.Get("code_challenge") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   params.Get("client_id"),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": params.Get("nonce"),
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "idp-key"
+		idToken, _ := token.SignedString(key)
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func setupOIDC(t *testing.T, claims jwt.MapClaims) (*gin.Engine, *mockIdP) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("OIDC_MANAGER_VALUES", "expense-managers")
+
+	gdb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "oidc.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.UserSession{}, &models.SigningKey{}, &models.LoginAttempt{}, &models.AuditEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	db.DB = gdb
+
+	if _, err := services.InitKeySet(gdb); err != nil {
+		t.Fatalf("failed to init keys: %v", err)
+	}
+
+	idp := newMockIdP(t, claims)
+	controllers.SetOIDCService(services.NewOIDCServiceWithConfig(services.OIDCConfig{
+		IssuerURL:   idp.server.URL,
+		ClientID:    "expenses-app",
+		RedirectURL: "http://app.local/api/auth/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", controllers.OIDCLogin)
+	router.GET("/api/auth/oidc/callback", controllers.OIDCCallback)
+	return router, idp
+}
+
+// runOIDCLogin walks login -> IdP authorize -> callback and returns the callback response
+func runOIDCLogin(t *testing.T, router *gin.Engine, tamper func(callback *url.URL)) *httptest.ResponseRecorder {
+	login := httptest.NewRecorder()
+	router.ServeHTTP(login, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	assert.Equal(t, http.StatusFound, login.Code)
+
+	authorizeURL, _ := url.Parse(login.Header().Get("Location"))
+	assert.Equal(t, "S256", authorizeURL.Query().Get("code_challenge_method"))
+	assert.NotEmpty(t, authorizeURL.Query().Get("code_challenge"))
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authorizeURL.String())
+	if err != nil {
+		t.Fatalf("authorize failed: %v", err)
+	}
+	resp.Body.Close()
+
+	callbackURL, _ := url.Parse(resp.Header.Get("Location"))
+	if tamper != nil {
+		tamper(callbackURL)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+callbackURL.RawQuery, nil)
+	for _, cookie := range login.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	callback := httptest.NewRecorder()
+	router.ServeHTTP(callback, req)
+	return callback
+}
+
+func TestOIDC_LoginProvisionsUserWithMappedRole(t *testing.T) {
+	router, _ := setupOIDC(t, jwt.MapClaims{
+		"sub":            "idp|alice",
+		"email":          "Alice.SSO@example.com",
+		"email_verified": true,
+		"name":           "Alice SSO",
+		"groups":         []string{"staff", "expense-managers"},
+	})
+
+	callback := runOIDCLogin(t, router, nil)
+	assert.Equal(t, http.StatusFound, callback.Code)
+	assert.Equal(t, "/dashboard", callback.Header().Get("Location"))
+
+	var cookieNames []string
+	for _, cookie := range callback.Result().Cookies() {
+		cookieNames = append(cookieNames, cookie.Name)
+	}
+	assert.Contains(t, cookieNames, "token")
+	assert.Contains(t, cookieNames, "refresh_token")
+
+	var user models.User
+	assert.NoError(t, db.DB.First(&user, "email = ?", "alice.sso@example.com").Error)
+	assert.Equal(t, constants.UserRoleManager, user.Role)
+	assert.Equal(t, "idp|alice", *user.OIDCSubject)
+
+	// a second login reuses the same user
+	callback = runOIDCLogin(t, router, nil)
+	assert.Equal(t, http.StatusFound, callback.Code)
+	var count int64
+	db.DB.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestOIDC_RejectsTamperedState(t *testing.T) {
+	router, _ := setupOIDC(t, jwt.MapClaims{"sub": "idp|bob", "email": "bob@example.com"})
+
+	callback := runOIDCLogin(t, router, func(callbackURL *url.URL) {
+		query := callbackURL.Query()
+		query.Set("state", "forged")
+		callbackURL.RawQuery = query.Encode()
+	})
+	assert.Equal(t, http.StatusBadRequest, callback.Code)
+}
+
+func TestOIDC_RejectsUnknownCode(t *testing.T) {
+	router, _ := setupOIDC(t, jwt.MapClaims{"sub": "idp|bob", "email": "bob@example.com"})
+
+	callback := runOIDCLogin(t, router, func(callbackURL *url.URL) {
+		query := callbackURL.Query()
+		query.Set("code", "stolen-code")
+		callbackURL.RawQuery = query.Encode()
+	})
+	assert.Equal(t, http.StatusUnauthorized, callback.Code)
+
+	var count int64
+	db.DB.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestOIDC_NeverLinksAnUnverifiedEmail(t *testing.T) {
+	claims := jwt.MapClaims{
+		"sub":    "idp|mallory",
+		"email":  "bob@example.com",
+		"name":   "Mallory",
+		"groups": []string{"expense-managers"},
+	}
+	router, _ := setupOIDC(t, claims)
+	bob := createUser(t, "bob@example.com", "Bob User", "user-pass")
+
+	// without the claim, then with it false
+	for _, verified := range []interface{}{nil, false} {
+		if verified != nil {
+			claims["email_verified"] = verified
+		}
+		callback := runOIDCLogin(t, router, nil)
+		assert.Equal(t, http.StatusForbidden, callback.Code)
+	}
+
+	var user models.User
+	db.DB.First(&user, bob.ID)
+	assert.Nil(t, user.OIDCSubject)
+	assert.Equal(t, constants.UserRoleUser, user.Role)
+	assert.Equal(t, "Bob User", user.Name)
+
+	var count int64
+	db.DB.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestOIDC_RoleFollowsTheClaimOnlyWhenSent(t *testing.T) {
+	claims := jwt.MapClaims{
+		"sub":            "idp|carol",
+		"email":          "carol@example.com",
+		"email_verified": true,
+		"name":           "Carol SSO",
+		"groups":         []string{"staff"},
+		"manager_email":  "Dana@example.com",
+	}
+	router, _ := setupOIDC(t, claims)
+	dana := models.User{Email: "dana@example.com", Name: "Dana Manager", Role: constants.UserRoleManager}
+	db.DB.Create(&dana)
+
+	login := func() models.User {
+		t.Helper()
+		assert.Equal(t, http.StatusFound, runOIDCLogin(t, router, nil).Code)
+		var user models.User
+		db.DB.First(&user, "oidc_subject = ?", "idp|carol")
+		return user
+	}
+
+	// new users report to the manager named by the IdP
+	carol := login()
+	assert.Equal(t, constants.UserRoleUser, carol.Role)
+	if assert.NotNil(t, carol.ManagerID) {
+		assert.Equal(t, dana.ID, *carol.ManagerID)
+	}
+
+	claims["groups"] = []string{"expense-managers"}
+	assert.Equal(t, constants.UserRoleManager, login().Role)
+
+	// a token without the groups claim keeps the role
+	delete(claims, "groups")
+	assert.Equal(t, constants.UserRoleManager, login().Role)
+
+	claims["groups"] = []string{"staff"}
+	assert.Equal(t, constants.UserRoleUser, login().Role)
+}
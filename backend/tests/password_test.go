@@ -0,0 +1,208 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/controllers"
+	"backend/rules"
+	"backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ---------- Local SMTP server capturing messages ----------
+func newTestSMTPServer(t *testing.T) (services.SMTPConfig, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start SMTP server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return services.SMTPConfig{Host: host, Port: port, From: "no-reply@expenses.test"}, messages
+}
+
+func serveSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 expenses.test ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 expenses.test")
+		case strings.HasPrefix(command, "DATA"):
+			reply("354 end with .")
+			var body strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				body.WriteString(dataLine)
+			}
+			messages <- body.String()
+			reply("250 queued")
+		case strings.HasPrefix(command, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func waitForMail(t *testing.T, messages <-chan string) string {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+		return ""
+	}
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := rules.PasswordPolicy{MinLength: 10, MinCharacterClasses: 3, DisallowEmail: true}
+
+	assert.ErrorIs(t, policy.Validate("Sh0rt!", "bob@user.com"), rules.ErrPasswordTooShort)
+	assert.ErrorIs(t, policy.Validate("alllowercaseletters", "bob@user.com"), rules.ErrPasswordTooSimple)
+	assert.ErrorIs(t, policy.Validate("Bob-Secret-2026", "bob@user.com"), rules.ErrPasswordContainsEmail)
+	assert.ErrorIs(t, policy.Validate(strings.Repeat("Ab1", 25), "bob@user.com"), rules.ErrPasswordTooLong)
+	assert.NoError(t, policy.Validate("Correct-Horse-9", "bob@user.com"))
+
+	breached := services.NewDefaultBreachedPasswords()
+	assert.True(t, breached.Contains("P@ssw0rd1"))
+	assert.False(t, breached.Contains("Correct-Horse-9"))
+
+	// Have I Been Pwned dumps are "HASH:count"
+	custom, err := services.NewBreachedPasswordsFrom(strings.NewReader("7C4A8D09CA3762AF61E59520943DC26494F8941B:24230577\n"))
+	assert.NoError(t, err)
+	assert.True(t, custom.Contains("123456"))
+}
+
+func TestBreachedPasswords_SearchesASortedDump(t *testing.T) {
+	passwords := map[string]string{} // hash -> password
+	var hashes []string
+	for i := 0; i < 500; i++ {
+		password := fmt.Sprintf("leaked-%d", i)
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		passwords[hash] = password
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	// HIBP ships CRLF lines with counts of varying length
+	var dump strings.Builder
+	for i, hash := range hashes {
+		fmt.Fprintf(&dump, "%s:%d\r\n", hash, i*i+1)
+	}
+	data := []byte(dump.String())
+	list := services.NewSortedBreachedPasswords(bytes.NewReader(data), int64(len(data)))
+
+	for _, password := range passwords {
+		assert.True(t, list.Contains(password), password)
+	}
+	assert.False(t, list.Contains("Correct-Horse-9"))
+	assert.False(t, list.Contains("leaked-500"))
+
+	// lower case hex without counts or a trailing newline
+	data = []byte(strings.ToLower(strings.Join(hashes[:3], "\n")))
+	list = services.NewSortedBreachedPasswords(bytes.NewReader(data), int64(len(data)))
+	for _, hash := range hashes[:3] {
+		assert.True(t, list.Contains(passwords[hash]), passwords[hash])
+	}
+	assert.False(t, list.Contains(passwords[hashes[3]]))
+}
+
+func TestPassword_ForgotAndResetFlow(t *testing.T) {
+	router, _ := setupUserAdmin(t)
+
+	smtpConfig, messages := newTestSMTPServer(t)
+	controllers.SetMailer(services.NewMailerWithConfig(smtpConfig))
+
+	// unknown accounts get the same answer and no email
+	w := jsonRequest(router, http.MethodPost, "/api/auth/forgot-password", map[string]string{"email": "nobody@user.com"}, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	unknownBody := w.Body.String()
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/forgot-password", map[string]string{"email": "alice@manager.com"}, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, unknownBody, w.Body.String())
+
+	mail := waitForMail(t, messages)
+	assert.Contains(t, mail, "To: alice@manager.com")
+	token := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(mail)[1]
+
+	reset := map[string]string{"token": token, "password": "short"}
+	w = jsonRequest(router, http.MethodPost, "/api/auth/reset-password", reset, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	reset["password"] = "Password123"
+	w = jsonRequest(router, http.MethodPost, "/api/auth/reset-password", reset, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "breached")
+
+	reset["password"] = "Approve-Carefully-42"
+	w = jsonRequest(router, http.MethodPost, "/api/auth/reset-password", reset, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, waitForMail(t, messages), "Your password was changed")
+
+	// the token works once
+	w = jsonRequest(router, http.MethodPost, "/api/auth/reset-password", reset, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "alice@manager.com", "password": "Approve-Carefully-42"}, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPassword_Change(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+	smtpConfig, _ := newTestSMTPServer(t)
+	controllers.SetMailer(services.NewMailerWithConfig(smtpConfig))
+
+	w := jsonRequest(router, http.MethodPut, "/api/auth/password", map[string]string{"current_password": "wrong", "new_password": "Approve-Carefully-42"}, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodPut, "/api/auth/password", map[string]string{"current_password": "manager-pass", "new_password": "Approve-Carefully-42"}, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the session that changed the password keeps working
+	w = jsonRequest(router, http.MethodGet, "/api/sessions", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "alice@manager.com", "password": "manager-pass"}, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
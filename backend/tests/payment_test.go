@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/workers"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// failingPayments points the payment worker at a provider that always fails and returns
+// an approved expense of bob with the number of payment attempts made for it
+func failingPayments(t *testing.T) (models.Expense, *int32) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"message":"bank unavailable"}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("PAYMENT_BASE_URL", server.URL)
+	t.Setenv("PAYMENT_RETRY_DELAY", "10ms")
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	expense := pendingExpense(t, bob.ID, 500000)
+	db.DB.Model(&expense).Update("status", constants.ExpenseStatusApproved)
+	db.DB.Model(&models.Approval{}).Where("expense_id = ?", expense.ID).
+		Updates(map[string]interface{}{"status": constants.ApprovalStatusApproved, "notes": "Client visit"})
+	return expense, &attempts
+}
+
+func paymentFailEvents(expense models.Expense) []models.AuditEvent {
+	var events []models.AuditEvent
+	db.DB.Where("action = ? AND entity_id = ?", constants.AuditActionPaymentFail, strconv.FormatInt(expense.ID, 10)).Find(&events)
+	return events
+}
+
+func TestPaymentWorker_SavesTheFailureNote(t *testing.T) {
+	setupUserAdmin(t)
+	expense, attempts := failingPayments(t)
+
+	workers.NewPaymentWorker().ProcessExpensePaymentAsync(expense.ID)
+
+	assert.Eventually(t, func() bool { return len(paymentFailEvents(expense)) == 1 }, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, int32(workers.MaxRetries), atomic.LoadInt32(attempts))
+
+	var approval models.Approval
+	db.DB.First(&approval, "expense_id = ?", expense.ID)
+	assert.Equal(t, "Client visit - Payment failed after 3 attempts", approval.Notes)
+
+	// the expense stays approved, ready for a manual retry
+	var stored models.Expense
+	db.DB.First(&stored, expense.ID)
+	assert.Equal(t, constants.ExpenseStatusApproved, stored.Status)
+	assert.Nil(t, stored.ProcessedAt)
+}
+
+func TestPaymentWorker_RecordsTheFailureWhenTheNoteCannotBeSaved(t *testing.T) {
+	setupUserAdmin(t)
+	expense, _ := failingPayments(t)
+
+	db.DB.Callback().Update().Before("gorm:update").Register("fail_approval_update", func(tx *gorm.DB) {
+		if tx.Statement.Table == "approvals" {
+			tx.AddError(errors.New("disk full"))
+		}
+	})
+
+	workers.NewPaymentWorker().ProcessExpensePaymentAsync(expense.ID)
+
+	assert.Eventually(t, func() bool { return len(paymentFailEvents(expense)) == 1 }, 10*time.Second, 50*time.Millisecond)
+	event := paymentFailEvents(expense)[0]
+	assert.Contains(t, event.Metadata["error"], "bank unavailable")
+	assert.Empty(t, event.Changes) // the note was not saved
+
+	var approval models.Approval
+	db.DB.First(&approval, "expense_id = ?", expense.ID)
+	assert.Equal(t, "Client visit", approval.Notes)
+}
@@ -0,0 +1,240 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const scimTestToken = "scim-test-token"
+
+func setupSCIM(t *testing.T) *gin.Engine {
+	t.Setenv("SCIM_BEARER_TOKEN", scimTestToken)
+	t.Setenv("SCIM_MANAGER_GROUPS", "expense-managers")
+
+	gdb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "scim.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.Group{}, &models.UserSession{},
+		&models.Expense{}, &models.Approval{}, &models.ExpenseAuditLog{}, &models.AuditChainHead{}, &models.AuditAnchor{}, &models.AuditEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	db.DB = gdb
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.AuthRoutes(router.Group("/api"))
+	return router
+}
+
+func scimRequest(t *testing.T, router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, "/api/scim/v2"+path, &payload)
+	req.Header.Set("Authorization", "Bearer "+scimTestToken)
+	req.Header.Set("Content-Type", "application/scim+json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSCIM_ParseFilter(t *testing.T) {
+	columns := map[string]helpers.SCIMColumn{
+		"username": {Column: "email"},
+		"active":   {Column: "deactivated_at", NullMeansTrue: true},
+	}
+
+	clause, args, err := helpers.ParseSCIMFilter(`userName eq "Bob@User.com" and active eq false`, columns)
+	assert.NoError(t, err)
+	assert.Equal(t, "LOWER(email) = LOWER(?) AND deactivated_at IS NOT NULL", clause)
+	assert.Equal(t, []interface{}{"Bob@User.com"}, args)
+
+	clause, args, err = helpers.ParseSCIMFilter(`userName sw "bob and"`, columns)
+	assert.NoError(t, err)
+	assert.Equal(t, "LOWER(email) LIKE LOWER(?)", clause)
+	assert.Equal(t, []interface{}{"bob and%"}, args)
+
+	// ne matches eq: case-insensitive, and null means present
+	clause, args, err = helpers.ParseSCIMFilter(`userName ne "Bob@User.com" and title ne null`, map[string]helpers.SCIMColumn{
+		"username": {Column: "email"},
+		"title":    {Column: "title"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "LOWER(email) <> LOWER(?) AND title IS NOT NULL", clause)
+	assert.Equal(t, []interface{}{"Bob@User.com"}, args)
+
+	_, _, err = helpers.ParseSCIMFilter(`title eq "CEO"`, columns)
+	assert.ErrorIs(t, err, helpers.ErrInvalidSCIMFilter)
+
+	_, _, err = helpers.ParseSCIMFilter(`userName eq "bob" or userName eq "alice"`, columns)
+	assert.ErrorIs(t, err, helpers.ErrInvalidSCIMFilter)
+}
+
+func TestSCIM_RequiresBearerToken(t *testing.T) {
+	router := setupSCIM(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/scim/v2/Users", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSCIM_ProvisionAndDeactivateUser(t *testing.T) {
+	router := setupSCIM(t)
+
+	w := scimRequest(t, router, http.MethodPost, "/Users", map[string]interface{}{
+		"schemas":    []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"userName":   "Carol@Example.com",
+		"externalId": "okta-carol",
+		"name":       map[string]string{"givenName": "Carol", "familyName": "Employee"},
+		"active":     true,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/scim+json", w.Header().Get("Content-Type"))
+
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, "carol@example.com", created["userName"])
+	assert.Equal(t, "Carol Employee", created["displayName"])
+	userID, _ := strconv.ParseInt(created["id"].(string), 10, 64)
+
+	// duplicates are refused
+	w = scimRequest(t, router, http.MethodPost, "/Users", map[string]interface{}{"userName": "carol@example.com"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// the client looks the user up by userName
+	w = scimRequest(t, router, http.MethodGet, `/Users?filter=userName+eq+%22carol@example.com%22`, nil)
+	var list map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, float64(1), list["totalResults"])
+
+	// a session and a pending expense that should not survive deactivation
+	now := time.Now().UTC()
+	session := models.UserSession{UUID: uuid.New(), UserID: userID, RefreshTokenHash: "hash", ExpiresAt: now.Add(time.Hour), LastUsedAt: now}
+	assert.NoError(t, db.DB.Create(&session).Error)
+	pending := models.Expense{UUID: uuid.New(), UserID: userID, AmountIDR: 2000000, Status: constants.ExpenseStatusPending, RequiresApproval: true, SubmittedAt: now}
+	assert.NoError(t, db.DB.Create(&pending).Error)
+
+	w = scimRequest(t, router, http.MethodPatch, "/Users/"+created["id"].(string), map[string]interface{}{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": []map[string]interface{}{{"op": "replace", "value": map[string]interface{}{"active": false}}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var user models.User
+	db.DB.First(&user, userID)
+	assert.False(t, user.IsActive())
+
+	db.DB.First(&session, session.ID)
+	assert.NotNil(t, session.RevokedAt)
+
+	db.DB.First(&pending, pending.ID)
+	assert.True(t, pending.Flagged)
+	assert.Equal(t, "Submitter deactivated", *pending.FlagReason)
+	assert.Equal(t, constants.ExpenseStatusPending, pending.Status)
+
+	var logs int64
+	db.DB.Model(&models.ExpenseAuditLog{}).Where("expense_id = ?", pending.ID).Count(&logs)
+	assert.Equal(t, int64(1), logs)
+
+	// inactive users can be listed separately
+	w = scimRequest(t, router, http.MethodGet, `/Users?filter=active+eq+false`, nil)
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, float64(1), list["totalResults"])
+
+	// reactivating restores access but keeps the flag for review
+	w = scimRequest(t, router, http.MethodPatch, "/Users/"+created["id"].(string), map[string]interface{}{
+		"Operations": []map[string]interface{}{{"op": "replace", "path": "active", "value": true}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var reactivated models.User
+	db.DB.First(&reactivated, userID)
+	assert.True(t, reactivated.IsActive())
+}
+
+func TestSCIM_GroupMembershipSyncsManagerRole(t *testing.T) {
+	router := setupSCIM(t)
+
+	dave := models.User{Email: "dave@example.com", Name: "Dave", Role: constants.UserRoleUser}
+	assert.NoError(t, db.DB.Create(&dave).Error)
+	daveID := strconv.FormatInt(dave.ID, 10)
+
+	w := scimRequest(t, router, http.MethodPost, "/Groups", map[string]interface{}{
+		"displayName": "expense-managers",
+		"members":     []map[string]string{{"value": daveID}},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var group map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &group)
+	assert.Len(t, group["members"], 1)
+
+	db.DB.First(&dave, dave.ID)
+	assert.Equal(t, constants.UserRoleManager, dave.Role)
+
+	w = scimRequest(t, router, http.MethodPatch, "/Groups/"+group["id"].(string), map[string]interface{}{
+		"Operations": []map[string]interface{}{{"op": "remove", "path": `members[value eq "` + daveID + `"]`}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	db.DB.Table("user_group_members").Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	db.DB.First(&dave, dave.ID)
+	assert.Equal(t, constants.UserRoleUser, dave.Role)
+
+	// unknown members are rejected
+	w = scimRequest(t, router, http.MethodPatch, "/Groups/"+group["id"].(string), map[string]interface{}{
+		"Operations": []map[string]interface{}{{"op": "add", "path": "members", "value": []map[string]string{{"value": "999"}}}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSCIM_RenameFailsWhenTheUniquenessCheckFails(t *testing.T) {
+	router := setupSCIM(t)
+	carol := models.User{Email: "carol@example.com", Name: "Carol", Role: constants.UserRoleUser}
+	db.DB.Create(&carol)
+
+	// only the userName uniqueness count fails
+	db.DB.Callback().Query().Before("gorm:query").Register("fail_user_count", func(tx *gorm.DB) {
+		if _, isCount := tx.Statement.Dest.(*int64); isCount && tx.Statement.Table == "users" {
+			tx.AddError(errors.New("database is unavailable"))
+		}
+	})
+
+	w := scimRequest(t, router, http.MethodPatch, "/Users/"+strconv.FormatInt(carol.ID, 10), map[string]interface{}{
+		"Operations": []map[string]interface{}{{"op": "replace", "path": "userName", "value": "carol.new@example.com"}},
+	})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "urn:ietf:params:scim:api:messages:2.0:Error")
+
+	var stored models.User
+	db.DB.First(&stored, carol.ID)
+	assert.Equal(t, "carol@example.com", stored.Email)
+}
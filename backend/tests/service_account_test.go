@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/rules"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func apiKeyRequest(router *gin.Engine, method, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// issueManagerKey creates a manager service account and returns a key with scopes
+func issueManagerKey(t *testing.T, router *gin.Engine, managerCookies []*http.Cookie, scopes ...string) string {
+	t.Helper()
+	w := jsonRequest(router, http.MethodPost, "/api/manager/service-accounts", map[string]string{"name": "Integration", "role": "manager"}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var account models.User
+	json.Unmarshal(w.Body.Bytes(), &account)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/service-accounts/"+strconv.FormatInt(account.ID, 10)+"/keys",
+		map[string]interface{}{"name": "integration", "scopes": scopes}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var issued struct {
+		Key string `json:"key"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &issued)
+	return issued.Key
+}
+
+func TestServiceAccount_ScopedAPIKey(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+
+	w := jsonRequest(router, http.MethodPost, "/api/manager/service-accounts", map[string]string{"name": "Accounting integration", "role": "manager"}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var account models.User
+	json.Unmarshal(w.Body.Bytes(), &account)
+	assert.True(t, account.ServiceAccount)
+	keysPath := "/api/manager/service-accounts/" + strconv.FormatInt(account.ID, 10) + "/keys"
+
+	w = jsonRequest(router, http.MethodPost, keysPath, map[string]interface{}{"name": "export", "scopes": []string{"expenses:read", "pay:everything"}}, managerCookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, keysPath, map[string]interface{}{"name": "export", "scopes": []string{"expenses:read", "audit:read"}}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var issued struct {
+		APIKey models.APIKey `json:"api_key"`
+		Key    string        `json:"key"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &issued)
+	assert.NotNil(t, issued.APIKey.ExpiresAt)
+
+	// scopes on top of the role
+	assert.Equal(t, http.StatusOK, apiKeyRequest(router, http.MethodGet, "/api/manager/expenses", issued.Key).Code)
+	assert.Equal(t, http.StatusOK, apiKeyRequest(router, http.MethodGet, "/api/manager/expense-logs", issued.Key).Code)
+	assert.Equal(t, http.StatusForbidden, apiKeyRequest(router, http.MethodGet, "/api/manager/users", issued.Key).Code)
+	assert.Equal(t, http.StatusForbidden, apiKeyRequest(router, http.MethodPut, "/api/manager/expenses/1/approve", issued.Key).Code)
+
+	// keys cannot manage credentials
+	assert.Equal(t, http.StatusForbidden, apiKeyRequest(router, http.MethodPost, keysPath, issued.Key).Code)
+	assert.Equal(t, http.StatusForbidden, apiKeyRequest(router, http.MethodGet, "/api/sessions", issued.Key).Code)
+
+	var stored models.APIKey
+	db.DB.First(&stored, issued.APIKey.ID)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// service accounts cannot sign in with a password
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": account.Email, "password": ""}, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, apiKeyRequest(router, http.MethodGet, "/api/manager/expenses", "etk_made-up").Code)
+
+	// expired and revoked keys stop working
+	db.DB.Model(&models.APIKey{}).Where("id = ?", issued.APIKey.ID).Update("expires_at", time.Now().UTC().Add(-time.Minute))
+	w = apiKeyRequest(router, http.MethodGet, "/api/manager/expenses", issued.Key)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), rules.ErrAPIKeyExpired.Error())
+
+	db.DB.Model(&models.APIKey{}).Where("id = ?", issued.APIKey.ID).Update("expires_at", time.Now().UTC().Add(time.Hour))
+	w = jsonRequest(router, http.MethodDelete, keysPath+"/"+strconv.FormatInt(issued.APIKey.ID, 10), nil, managerCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = apiKeyRequest(router, http.MethodGet, "/api/manager/expenses", issued.Key)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), rules.ErrAPIKeyRevoked.Error())
+}
+
+func TestServiceAccount_CannotDecideExpenses(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+	t.Setenv("TWO_FACTOR_REQUIRED_ROLES", "none") // refused for being a key, not for a missing second factor
+
+	key := issueManagerKey(t, router, managerCookies, "expenses:read", "expenses:write")
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	expense := pendingExpense(t, bob.ID, 500000)
+	expensePath := "/api/manager/expenses/" + strconv.FormatInt(expense.ID, 10)
+
+	for _, path := range []string{expensePath + "/approve", expensePath + "/reject"} {
+		w := apiKeyRequest(router, http.MethodPut, path, key)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), rules.ErrAPIKeyNotAllowed.Error())
+	}
+	w := apiKeyRequest(router, http.MethodPost, "/api/manager/expenses/bulk-decision", key)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// the key still reads with the same scopes, and the expense waits for a person
+	assert.Equal(t, http.StatusOK, apiKeyRequest(router, http.MethodGet, expensePath, key).Code)
+	var stored models.Expense
+	db.DB.First(&stored, expense.ID)
+	assert.Equal(t, constants.ExpenseStatusPending, stored.Status)
+}
+
+func TestServiceAccount_ReportsKeyDownloadsStatements(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+	key := issueManagerKey(t, router, managerCookies, "reports:read")
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+
+	// statements are reports, the key needs no users scope
+	w := apiKeyRequest(router, http.MethodGet, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/statement", key)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusForbidden, apiKeyRequest(router, http.MethodGet, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10), key).Code)
+	assert.Equal(t, http.StatusOK, apiKeyRequest(router, http.MethodGet, "/api/manager/ledger/trial-balance", key).Code)
+}
@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/rules"
+	"backend/workers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLAWorker_EscalatesOnceAtTheTopOfTheChain(t *testing.T) {
+	setupUserAdmin(t)
+
+	director := createUser(t, "carol@user.com", "Carol Director", "")
+	lead := createUser(t, "bob@user.com", "Bob Lead", "")
+	db.DB.Model(&lead).Update("manager_id", director.ID)
+	dave := createUser(t, "dave@user.com", "Dave User", "")
+
+	policy := models.SLAPolicy{Name: "Default", ReminderAfterMinutes: 60, EscalateAfterMinutes: 120}
+	db.DB.Create(&policy)
+
+	created := time.Now().UTC().Add(-3 * time.Hour)
+	expense := pendingExpense(t, dave.ID, 2000000)
+	approval := models.Approval{ExpenseID: expense.ID, AssigneeID: &lead.ID, Status: constants.ApprovalStatusPending, CreatedAt: created}
+	rules.ApplySLAPolicy(&approval, &policy, created)
+	db.DB.Where("expense_id = ?", expense.ID).Delete(&models.Approval{})
+	db.DB.Create(&approval)
+
+	worker := workers.NewSLAWorker()
+	now := created
+	for i := 0; i < 6; i++ {
+		now = now.Add(3 * time.Hour)
+		assert.NoError(t, worker.RunOnce(context.Background(), now))
+	}
+
+	var escalated models.Approval
+	db.DB.First(&escalated, approval.ID)
+	assert.Equal(t, director.ID, *escalated.AssigneeID)
+	assert.Equal(t, 2, escalated.EscalationLevel)
+	assert.Nil(t, escalated.EscalationDueAt)
+	assert.NotNil(t, escalated.ReminderDueAt)
+
+	var reasons []string
+	db.DB.Model(&models.ExpenseAuditLog{}).Where("expense_id = ?", expense.ID).Order("seq").Pluck("reason", &reasons)
+	assert.Equal(t, []string{
+		"Approval SLA breached, escalated to Carol Director",
+		"Approval SLA breached, no higher manager to escalate to",
+	}, reasons)
+}
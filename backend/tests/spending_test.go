@@ -0,0 +1,74 @@
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSpendingSummary(t *testing.T) {
+	setupUserAdmin(t)
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	carol := createUser(t, "carol@user.com", "Carol User", "")
+
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	at := func(month time.Month, day int) time.Time { return time.Date(2026, month, day, 9, 0, 0, 0, time.UTC) }
+	paid := func(t time.Time) *time.Time { return &t }
+
+	for _, expense := range []models.Expense{
+		// this month
+		{UserID: bob.ID, AmountIDR: 200000, Status: constants.ExpenseStatusCompleted, SubmittedAt: at(5, 2), ProcessedAt: paid(at(5, 3))},
+		{UserID: bob.ID, AmountIDR: 1500000, Status: constants.ExpenseStatusPending, SubmittedAt: at(5, 10)},
+		// this quarter
+		{UserID: bob.ID, AmountIDR: 3000000, Status: constants.ExpenseStatusCompleted, SubmittedAt: at(4, 1), ProcessedAt: paid(at(4, 4))},
+		{UserID: bob.ID, AmountIDR: 1000000, Status: constants.ExpenseStatusApproved, SubmittedAt: at(4, 15)},
+		// submitted last month, reimbursed this month
+		{UserID: bob.ID, AmountIDR: 400000, Status: constants.ExpenseStatusCompleted, SubmittedAt: at(4, 30), ProcessedAt: paid(at(5, 2))},
+		// this year
+		{UserID: bob.ID, AmountIDR: 800000, Status: constants.ExpenseStatusRejected, SubmittedAt: at(2, 1)},
+		// submitted last year, reimbursed this year, and someone else
+		{UserID: bob.ID, AmountIDR: 9000000, Status: constants.ExpenseStatusCompleted, SubmittedAt: time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC),
+			ProcessedAt: paid(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))},
+		{UserID: carol.ID, AmountIDR: 700000, Status: constants.ExpenseStatusPending, SubmittedAt: at(5, 5)},
+	} {
+		expense.UUID = uuid.New()
+		expense.Description = "Expense"
+		db.DB.Create(&expense)
+	}
+
+	summary, err := services.UserSpendingSummary(db.DB, bob.ID, now)
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), summary.Month.From)
+	assert.Equal(t, services.SpendingTotals{Count: 2, AmountIDR: 1700000}, summary.Month.Submitted)
+	assert.Equal(t, services.SpendingTotals{Count: 2, AmountIDR: 600000}, summary.Month.Reimbursed)
+	assert.Equal(t, services.SpendingTotals{Count: 1, AmountIDR: 1500000}, summary.Month.Pending)
+	assert.InDelta(t, 36.0, summary.Month.AvgHoursToPayment, 0.01)
+
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), summary.Quarter.From)
+	assert.Equal(t, services.SpendingTotals{Count: 3, AmountIDR: 3600000}, summary.Quarter.Reimbursed)
+	assert.Equal(t, services.SpendingTotals{Count: 1, AmountIDR: 1000000}, summary.Quarter.AwaitingPayment)
+	assert.InDelta(t, 48.0, summary.Quarter.AvgHoursToPayment, 0.01)
+
+	assert.Equal(t, services.SpendingTotals{Count: 6, AmountIDR: 6900000}, summary.Year.Submitted)
+	assert.Equal(t, services.SpendingTotals{Count: 4, AmountIDR: 12600000}, summary.Year.Reimbursed)
+	assert.Equal(t, services.SpendingTotals{Count: 1, AmountIDR: 800000}, summary.Year.Rejected)
+
+	assert.Equal(t, constants.MaxExpenseAmount, summary.Limits.MaxExpenseIDR)
+	assert.Equal(t, int64(3000000), summary.Limits.LargestExpenseIDR)
+	assert.InDelta(t, 0.06, summary.Limits.LargestShareOfMax, 0.0001)
+	assert.Equal(t, int64(3), summary.Limits.NeededApproval)
+
+	empty, err := services.UserSpendingSummary(db.DB, carol.ID+100, now)
+	assert.NoError(t, err)
+	assert.Zero(t, empty.Year.Submitted.Count)
+	assert.Zero(t, empty.Year.AvgHoursToPayment)
+}
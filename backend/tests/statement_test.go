@@ -0,0 +1,154 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementAndVoucherPDFs(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	var alice models.User
+	db.DB.First(&alice, "email = ?", "alice@manager.com")
+	bob := createUser(t, "bob@user.com", "Bob User", "user-pass")
+
+	at := func(month time.Month, day int) *time.Time {
+		t := time.Date(2026, month, day, 3, 0, 0, 0, time.UTC) // 10:00 in Jakarta
+		return &t
+	}
+	reference := "pay_7f3a"
+	hotel := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 1500000, Description: "Hotel (Bandung)", Category: constants.ExpenseCategoryLodging,
+		Status: constants.ExpenseStatusCompleted, SubmittedAt: *at(1, 5), ProcessedAt: at(1, 8), PaymentReference: &reference}
+	taxi := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 75000, Description: "Taxi", Category: constants.ExpenseCategoryTransport,
+		Status: constants.ExpenseStatusCompleted, SubmittedAt: *at(1, 20), ProcessedAt: at(2, 2)}
+	flight := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 4200000, Description: "Flight to Surabaya", Category: constants.ExpenseCategoryTravel,
+		Status: constants.ExpenseStatusApproved, RequiresApproval: true, SubmittedAt: *at(2, 3)}
+	pending := models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 2000000, Description: "Conference", Status: constants.ExpenseStatusPending, SubmittedAt: *at(2, 4)}
+	own := models.Expense{UUID: uuid.New(), UserID: alice.ID, AmountIDR: 50000, Description: "Lunch", Status: constants.ExpenseStatusCompleted, SubmittedAt: *at(1, 6), ProcessedAt: at(1, 7)}
+	for _, expense := range []*models.Expense{&hotel, &taxi, &flight, &pending, &own} {
+		db.DB.Create(expense)
+	}
+	db.DB.Create(&models.Approval{ExpenseID: flight.ID, ApproverID: &alice.ID, Status: constants.ApprovalStatusApproved, Notes: "Client visit"})
+	db.DB.Create(&models.ExpenseAuditLog{ExpenseID: flight.ID, ActorID: &alice.ID, FromStatus: constants.ExpenseStatusPending,
+		ToStatus: constants.ExpenseStatusApproved, Reason: "Client visit", CreatedAt: *at(2, 5)})
+
+	w := jsonRequest(router, http.MethodGet, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/statement?from=2026-01-01&to=2026-01-31", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "statement-"+strconv.FormatInt(bob.ID, 10)+"-20260101.pdf")
+	statement := w.Body.String()
+	assertValidPDF(t, statement)
+	assert.Contains(t, statement, "(Bob User)")
+	assert.Contains(t, statement, "(2026-01-08 10:00)")
+	assert.Contains(t, statement, `(Hotel \(Bandung\))`)
+	assert.Contains(t, statement, "(pay_7f3a)")
+	assert.Contains(t, statement, "(Rp 1.500.000)")
+	assert.Contains(t, statement, "(1 expense)")
+	assert.NotContains(t, statement, "(Taxi)")  // paid in February
+	assert.NotContains(t, statement, "(Lunch)") // someone else's
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/users/999999/statement", nil, cookies)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = jsonRequest(router, http.MethodGet, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/statement?from=2026-02-01&to=2026-01-01", nil, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/expenses/"+strconv.FormatInt(flight.ID, 10)+"/voucher", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	voucher := w.Body.String()
+	assertValidPDF(t, voucher)
+	assert.Contains(t, voucher, "(Flight to Surabaya)")
+	assert.Contains(t, voucher, "(Rp 4.200.000)")
+	assert.Contains(t, voucher, "(Approved by)")
+	assert.Contains(t, voucher, "(Alice Manager)")
+	assert.Contains(t, voucher, "(2026-02-05 10:00)")
+	assert.Contains(t, voucher, "(Client visit)")
+	assert.Contains(t, voucher, "(Approved, the payment has not been completed yet.)")
+
+	w = jsonRequest(router, http.MethodGet, "/api/manager/expenses/"+strconv.FormatInt(pending.ID, 10)+"/voucher", nil, cookies)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// users only get their own documents
+	bobCookies := loginAs(t, router, "bob@user.com", "user-pass")
+
+	w = jsonRequest(router, http.MethodGet, "/api/user/statement?from=2026-02-01&to=2026-02-28", nil, bobCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "(Taxi)")
+	assert.Contains(t, w.Body.String(), "(-)") // paid before references were kept
+
+	w = jsonRequest(router, http.MethodGet, "/api/user/expenses/"+strconv.FormatInt(hotel.ID, 10)+"/voucher", nil, bobCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "(pay_7f3a)")
+	for _, id := range []int64{own.ID, pending.ID + 1000} {
+		w = jsonRequest(router, http.MethodGet, "/api/user/expenses/"+strconv.FormatInt(id, 10)+"/voucher", nil, bobCookies)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+}
+
+func TestStatementPDF_RunsOntoMorePages(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	bob := createUser(t, "bob@user.com", "Bob User", "")
+	paid := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 120; i++ {
+		db.DB.Create(&models.Expense{UUID: uuid.New(), UserID: bob.ID, AmountIDR: 10000, Description: strings.Repeat("Supplies ", 20),
+			Status: constants.ExpenseStatusCompleted, SubmittedAt: paid, ProcessedAt: &paid})
+	}
+
+	w := jsonRequest(router, http.MethodGet, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/statement?from=2026-03-01&to=2026-03-31", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assertValidPDF(t, body)
+	assert.Contains(t, body, "/Count 3")
+	assert.Contains(t, body, "(Page 3 of 3)")
+	assert.Equal(t, 3, strings.Count(body, "(Payment Reference)")) // the header repeats on every page
+	assert.Contains(t, body, "(Rp 1.200.000)")
+	assert.Contains(t, body, "...)") // long descriptions are cut to the column
+}
+
+func TestProcessPayment_KeepsPaymentReference(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"id":"pay_91c2","external_id":"x","status":"completed"},"message":"ok"}`))
+	}))
+	defer server.Close()
+
+	expense := &models.Expense{UUID: uuid.New(), AmountIDR: 500000, Status: constants.ExpenseStatusApproved}
+	updated, _, err := services.NewPaymentServiceWithBaseURL(server.URL).ProcessPayment(context.Background(), expense, &models.Approval{})
+	assert.NoError(t, err)
+	if assert.NotNil(t, updated.PaymentReference) {
+		assert.Equal(t, "pay_91c2", *updated.PaymentReference)
+	}
+}
+
+// assertValidPDF checks the header, trailer and that every xref offset lands on its object
+func assertValidPDF(t *testing.T, pdf string) {
+	t.Helper()
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	if !assert.NotNil(t, match) {
+		return
+	}
+	xref, _ := strconv.Atoi(match[1])
+	if !assert.True(t, strings.HasPrefix(pdf[xref:], "xref\n")) {
+		return
+	}
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xref:], -1) {
+		offset, _ := strconv.Atoi(entry[1])
+		assert.True(t, strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj\n"), "object %d", i+1)
+	}
+}
@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/rules"
+	"backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTP_CodeAndVerify(t *testing.T) {
+	// RFC 6238 test key "12345678901234567890", truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	code, err := services.TOTPCode(secret, services.TOTPCounter(time.Unix(59, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	code, err = services.TOTPCode(secret, services.TOTPCounter(time.Unix(1111111109, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "081804", code)
+
+	// one step of clock drift is tolerated, two are not
+	now := time.Unix(1111111109, 0)
+	counter, ok := services.VerifyTOTP(secret, "081804", now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, services.TOTPCounter(now), counter)
+
+	_, ok = services.VerifyTOTP(secret, "081804", now.Add(90*time.Second))
+	assert.False(t, ok)
+
+	_, ok = services.VerifyTOTP(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTwoFactor_EnrolLoginAndApprovalGate(t *testing.T) {
+	router, cookies := setupUserAdmin(t)
+
+	approveError := func(cookies []*http.Cookie) string {
+		w := jsonRequest(router, http.MethodPut, "/api/manager/expenses/999/approve", map[string]string{}, cookies)
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return body.Error
+	}
+
+	// managers cannot approve before enrolling
+	assert.Equal(t, rules.ErrTwoFactorRequired.Error(), approveError(cookies))
+
+	w := jsonRequest(router, http.MethodPost, "/api/auth/2fa/setup", nil, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var setup struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &setup)
+	assert.Contains(t, setup.OTPAuthURI, "otpauth://totp/")
+
+	now := time.Now()
+	code, _ := services.TOTPCode(setup.Secret, services.TOTPCounter(now))
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/2fa/enable", map[string]string{"code": "000000"}, cookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/2fa/enable", map[string]string{"code": code}, cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var recovery struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &recovery)
+	assert.Len(t, recovery.RecoveryCodes, 10)
+
+	// the enrolling session counts as verified
+	assert.NotEqual(t, rules.ErrTwoFactorRequired.Error(), approveError(cookies))
+
+	login := func() string {
+		w := jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "alice@manager.com", "password": "manager-pass"}, nil)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+
+		var challenge struct {
+			MFARequired bool   `json:"mfa_required"`
+			MFAToken    string `json:"mfa_token"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &challenge)
+		assert.True(t, challenge.MFARequired)
+		return challenge.MFAToken
+	}
+
+	mfaToken := login()
+
+	// the login token is not an access token
+	w = jsonRequest(router, http.MethodGet, "/api/sessions", nil, []*http.Cookie{{Name: "token", Value: mfaToken}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// a code that was already used is refused
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": code}, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	next, _ := services.TOTPCode(setup.Secret, services.TOTPCounter(now)+1)
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": next}, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, rules.ErrTwoFactorRequired.Error(), approveError(w.Result().Cookies()))
+
+	// recovery codes work once
+	mfaToken = login()
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "recovery_code": recovery.RecoveryCodes[0]}, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = jsonRequest(router, http.MethodPost, "/api/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "recovery_code": recovery.RecoveryCodes[0]}, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// managers must keep the second factor
+	w = jsonRequest(router, http.MethodPost, "/api/auth/2fa/disable", map[string]string{"password": "manager-pass", "code": "123456"}, cookies)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,225 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"backend/constants"
+	"backend/db"
+	"backend/models"
+	"backend/routes"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUserAdmin(t *testing.T) (*gin.Engine, []*http.Cookie) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	gdb, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "users.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := gdb.AutoMigrate(&models.User{}, &models.UserSession{}, &models.SigningKey{}, &models.UserInvite{}, &models.PasswordResetToken{}, &models.UserRecoveryCode{}, &models.LoginAttempt{}, &models.APIKey{},
+		&models.Expense{}, &models.Approval{}, &models.ExpenseAuditLog{}, &models.AuditChainHead{}, &models.AuditAnchor{}, &models.AuditEvent{},
+		&models.ApprovalDelegation{}, &models.SLAPolicy{}, &models.ApprovalStep{}, &models.JournalEntry{}, &models.JournalLine{}, &models.GLAccountMapping{}, &models.AccountingExport{}, &models.AccountingExportItem{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	db.DB = gdb
+
+	if _, err := services.InitKeySet(gdb); err != nil {
+		t.Fatalf("failed to init keys: %v", err)
+	}
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("manager-pass"), bcrypt.MinCost)
+	gdb.Create(&models.User{Email: "alice@manager.com", Name: "Alice Manager", Role: constants.UserRoleManager, PasswordHash: string(hash)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.AuthRoutes(router.Group("/api"))
+
+	return router, loginAs(t, router, "alice@manager.com", "manager-pass")
+}
+
+// createUser stores a user with the user role, without a password they cannot log in
+func createUser(t *testing.T, email, name, password string) models.User {
+	t.Helper()
+	user := models.User{Email: email, Name: name, Role: constants.UserRoleUser}
+	if password != "" {
+		hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		user.PasswordHash = string(hash)
+	}
+	if err := db.DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create %s: %v", email, err)
+	}
+	return user
+}
+
+// loginAs returns the session cookies of a password login
+func loginAs(t *testing.T, router *gin.Engine, email, password string) []*http.Cookie {
+	t.Helper()
+	w := jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": email, "password": password}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login of %s failed: %s", email, w.Body.String())
+	}
+	return w.Result().Cookies()
+}
+
+// getJSON decodes the response of a GET into out and returns its status
+func getJSON(router *gin.Engine, path string, cookies []*http.Cookie, out interface{}) int {
+	w := jsonRequest(router, http.MethodGet, path, nil, cookies)
+	json.Unmarshal(w.Body.Bytes(), out)
+	return w.Code
+}
+
+// listData fetches the data of a listing and returns it with the status
+func listData[T any](router *gin.Engine, path string, cookies []*http.Cookie) ([]T, int) {
+	var body struct {
+		Data []T `json:"data"`
+	}
+	code := getJSON(router, path, cookies, &body)
+	return body.Data, code
+}
+
+func expenseIDs(expenses []models.Expense) []int64 {
+	var ids []int64
+	for _, expense := range expenses {
+		ids = append(ids, expense.ID)
+	}
+	return ids
+}
+
+func jsonRequest(router *gin.Engine, method, path string, body interface{}, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+		// echo the CSRF cookie like the frontend does
+		if cookie.Name == "csrf_token" {
+			req.Header.Set("X-CSRF-Token", cookie.Value)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUserAdmin_InviteSignupIsSingleUse(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+
+	w := jsonRequest(router, http.MethodPost, "/api/manager/invites", map[string]interface{}{"email": "Dave@User.com"}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var invite struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &invite)
+	assert.NotEmpty(t, invite.Token)
+
+	signup := map[string]string{"token": invite.Token, "name": "Dave User", "password": "short"}
+	w = jsonRequest(router, http.MethodPost, "/api/auth/register", signup, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	signup["password"] = "long-enough-password"
+	w = jsonRequest(router, http.MethodPost, "/api/auth/register", signup, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var dave models.User
+	assert.NoError(t, db.DB.First(&dave, "email = ?", "dave@user.com").Error)
+	assert.Equal(t, constants.UserRoleUser, dave.Role)
+
+	// the same invite cannot be used twice
+	w = jsonRequest(router, http.MethodPost, "/api/auth/register", signup, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/auth/register", map[string]string{"token": "made-up", "name": "Eve", "password": "long-enough-password"}, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUserAdmin_DeactivateBlocksExistingToken(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+
+	w := jsonRequest(router, http.MethodPost, "/api/manager/users", map[string]interface{}{
+		"email": "bob@user.com", "name": "Bob User", "role": "user", "password": "Expense-Tracker-7",
+	}, managerCookies)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var bob models.User
+	json.Unmarshal(w.Body.Bytes(), &bob)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/users", map[string]interface{}{
+		"email": "BOB@user.com", "name": "Bob Again", "role": "user",
+	}, managerCookies)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	login := jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "bob@user.com", "password": "Expense-Tracker-7"}, nil)
+	assert.Equal(t, http.StatusOK, login.Code)
+	bobCookies := login.Result().Cookies()
+
+	w = jsonRequest(router, http.MethodGet, "/api/user/expenses", nil, bobCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/users/1/deactivate", nil, managerCookies)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "managers cannot deactivate themselves")
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/deactivate", nil, managerCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = jsonRequest(router, http.MethodGet, "/api/user/expenses", nil, bobCookies)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	login = jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "bob@user.com", "password": "Expense-Tracker-7"}, nil)
+	assert.Equal(t, http.StatusUnauthorized, login.Code)
+
+	// reset password hands out a temporary password once the account is back
+	jsonRequest(router, http.MethodPost, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/reactivate", nil, managerCookies)
+	w = jsonRequest(router, http.MethodPost, "/api/manager/users/"+strconv.FormatInt(bob.ID, 10)+"/reset-password", nil, managerCookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var reset struct {
+		TemporaryPassword string `json:"temporary_password"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &reset)
+	login = jsonRequest(router, http.MethodPost, "/api/auth/login", map[string]string{"email": "bob@user.com", "password": reset.TemporaryPassword}, nil)
+	assert.Equal(t, http.StatusOK, login.Code)
+}
+
+func TestUserAdmin_EmailCheckFailureIsAServerError(t *testing.T) {
+	router, managerCookies := setupUserAdmin(t)
+
+	// the duplicate email count fails, the user must not be created as if the email was free
+	db.DB.Callback().Query().Before("gorm:query").Register("test:fail_email_count", func(tx *gorm.DB) {
+		if _, counting := tx.Statement.Dest.(*int64); counting && tx.Statement.Table == "users" {
+			tx.AddError(errors.New("database is unavailable"))
+		}
+	})
+
+	w := jsonRequest(router, http.MethodPost, "/api/manager/users", map[string]interface{}{
+		"email": "bob@user.com", "name": "Bob User", "role": "user",
+	}, managerCookies)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = jsonRequest(router, http.MethodPost, "/api/manager/invites", map[string]interface{}{"email": "carol@user.com"}, managerCookies)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	db.DB.Callback().Query().Remove("test:fail_email_count")
+	var count int64
+	db.DB.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
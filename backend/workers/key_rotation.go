@@ -0,0 +1,55 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/services"
+)
+
+const keyRotationCheckInterval = time.Hour
+
+type KeyRotationWorker struct {
+	keys     *services.KeySet
+	interval time.Duration
+}
+
+func NewKeyRotationWorker(keys *services.KeySet) *KeyRotationWorker {
+	return &KeyRotationWorker{
+		keys:     keys,
+		interval: services.KeyRotationInterval(),
+	}
+}
+
+// Start checks hourly whether the active key is due for rotation. It also reloads
+// the key set so keys rotated by another instance are picked up.
+func (w *KeyRotationWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(keyRotationCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := w.keys.Reload(); err != nil {
+				log.Printf("Failed to reload signing keys: %v", err)
+				continue
+			}
+
+			age, err := w.keys.CurrentKeyAge(time.Now().UTC())
+			if err != nil || age >= w.interval {
+				key, err := w.keys.Rotate()
+				if err != nil {
+					log.Printf("Failed to rotate signing key: %v", err)
+					continue
+				}
+				log.Printf("Rotated signing key, new kid %s", key.KID)
+			}
+		}
+	}()
+}
@@ -9,23 +9,26 @@ import (
 	"backend/actions"
 	"backend/constants"
 	"backend/db"
+	"backend/helpers"
 	"backend/models"
 	"backend/services"
 )
 
 type PaymentWorker struct {
 	paymentService services.PaymentService
+	retryDelay     time.Duration
 }
 
 func NewPaymentWorker() *PaymentWorker {
 	return &PaymentWorker{
 		paymentService: services.NewPaymentService(),
+		retryDelay:     services.DurationFromEnv("PAYMENT_RETRY_DELAY", RetryDelay),
 	}
 }
 
 const (
 	MaxRetries = 3
-	RetryDelay = 5 * time.Second
+	RetryDelay = 5 * time.Second // default wait before the second attempt, growing with each attempt
 )
 
 // ProcessExpensePaymentAsync processes payment in background
@@ -38,8 +41,6 @@ func (w *PaymentWorker) ProcessExpensePaymentAsync(expenseID int64) {
 
 		log.Printf("Starting payment processing for expense %d", expenseID)
 
-		tx := db.DB.Begin()
-
 		// Retry logic, 3 times max, can be improved by making an api to retry failed payments manually
 		for attempt := 1; attempt <= MaxRetries; attempt++ {
 			// Fetch fresh expense data
@@ -61,23 +62,38 @@ func (w *PaymentWorker) ProcessExpensePaymentAsync(expenseID int64) {
 
 			if err != nil {
 				if attempt >= MaxRetries {
+					event := helpers.AuditEventInput{
+						Action:     constants.AuditActionPaymentFail,
+						EntityType: constants.AuditEntityExpense,
+						EntityID:   expense.ID,
+						Metadata:   map[string]interface{}{"attempts": attempt, "error": err.Error()},
+					}
 					if expense.Approval != nil {
+						approvalBefore := *expense.Approval
 						failureNote := fmt.Sprintf("Payment failed after %d attempts", MaxRetries)
 						if expense.Approval.Notes != "" {
 							expense.Approval.Notes += " - " + failureNote
 						} else {
 							expense.Approval.Notes = failureNote
 						}
-						if err := tx.Save(expense.Approval).Error; err != nil {
-							tx.Rollback()
+						// the failure is recorded even when the note cannot be saved, only without the note diff
+						if err := db.DB.Save(expense.Approval).Error; err != nil {
 							log.Printf("Failed to update approval %d: %v", expense.Approval.ID, err)
-							return
+						} else {
+							event.Before, event.After = approvalBefore, expense.Approval
 						}
 					}
+					helpers.RecordAuditEvent(db.DB, nil, event)
 					return
 				}
 
-				retryWait := RetryDelay * time.Duration(attempt)
+				retryWait := w.retryDelay * time.Duration(attempt)
+				helpers.RecordAuditEvent(db.DB, nil, helpers.AuditEventInput{
+					Action:     constants.AuditActionPaymentRetry,
+					EntityType: constants.AuditEntityExpense,
+					EntityID:   expense.ID,
+					Metadata:   map[string]interface{}{"attempt": attempt, "error": err.Error(), "retry_in": retryWait.String()},
+				})
 				time.Sleep(retryWait)
 				continue
 			}
@@ -94,6 +110,8 @@ func (w *PaymentWorker) ProcessExpensePaymentAsync(expenseID int64) {
 				return
 			}
 
+			// one transaction per payment, nothing is left open by the failed attempts
+			tx := db.DB.Begin()
 			if err := tx.Save(updatedExpense).Error; err != nil {
 				tx.Rollback()
 				fmt.Printf("Failed to update expense %d: %v", expense.ID, err)
@@ -108,13 +126,26 @@ func (w *PaymentWorker) ProcessExpensePaymentAsync(expenseID int64) {
 				}
 			}
 
-			if err := tx.Create(audit).Error; err != nil {
+			if err := helpers.AppendExpenseAuditLog(tx, audit); err != nil {
 				tx.Rollback()
 				fmt.Printf("Failed to create audit log for expense %d: %v", expense.ID, err)
 				return
 			}
 
+			if err := helpers.PostExpenseSettlement(tx, updatedExpense, *updatedExpense.ProcessedAt); err != nil {
+				tx.Rollback()
+				log.Printf("Failed to post settlement for expense %d: %v", expense.ID, err)
+				return
+			}
+
 			tx.Commit()
+
+			helpers.RecordAuditEvent(db.DB, nil, helpers.AuditEventInput{
+				Action:     constants.AuditActionPaymentComplete,
+				EntityType: constants.AuditEntityExpense,
+				EntityID:   expense.ID,
+				Metadata:   map[string]interface{}{"attempt": attempt},
+			})
 		}
 	}()
 }
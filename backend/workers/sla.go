@@ -0,0 +1,183 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"backend/actions"
+	"backend/constants"
+	"backend/db"
+	"backend/helpers"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+)
+
+const DefaultSLACheckInterval = 5 * time.Minute
+
+type SLAWorker struct {
+	notifier services.Notifier
+	interval time.Duration
+}
+
+func NewSLAWorker() *SLAWorker {
+	interval := DefaultSLACheckInterval
+	if v := os.Getenv("SLA_CHECK_INTERVAL"); v != "" {
+		if parsed, err := time.ParseDuration(v); err == nil && parsed > 0 {
+			interval = parsed
+		}
+	}
+
+	return &SLAWorker{
+		notifier: services.NewNotifier(),
+		interval: interval,
+	}
+}
+
+// Start runs the SLA check on every tick until the context is cancelled
+func (w *SLAWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.RunOnce(ctx, time.Now().UTC()); err != nil {
+				log.Printf("SLA check failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce sends due reminders and escalates overdue approvals
+func (w *SLAWorker) RunOnce(ctx context.Context, now time.Time) error {
+	var approvals []models.Approval
+	if err := db.DB.Preload("Expense").
+		Where("status = ?", constants.ApprovalStatusPending).
+		Where("reminder_due_at <= ? OR escalation_due_at <= ?", now, now).
+		Find(&approvals).Error; err != nil {
+		return err
+	}
+
+	for i := range approvals {
+		approval := &approvals[i]
+
+		switch rules.NextSLAAction(approval, now) {
+		case constants.SLAActionRemind:
+			w.remind(ctx, approval, now)
+		case constants.SLAActionEscalate:
+			w.escalate(ctx, approval, now)
+		}
+	}
+
+	return nil
+}
+
+func (w *SLAWorker) remind(ctx context.Context, approval *models.Approval, now time.Time) {
+	if approval.AssigneeID != nil {
+		var assignee models.User
+		if err := db.DB.First(&assignee, *approval.AssigneeID).Error; err != nil {
+			log.Printf("Failed to fetch assignee for approval %d: %v", approval.ID, err)
+			return
+		}
+
+		subject := fmt.Sprintf("Reminder: expense %d is waiting for your approval", approval.ExpenseID)
+		body := fmt.Sprintf("Please review \"%s\", it is past its approval deadline.", approval.Expense.Description)
+		if approval.EscalationDueAt != nil {
+			body = fmt.Sprintf("Please review \"%s\" before %s.", approval.Expense.Description, approval.EscalationDueAt.Format(time.RFC3339))
+		}
+		if err := w.notifier.Notify(ctx, &assignee, subject, body); err != nil {
+			log.Printf("Failed to send reminder for approval %d: %v", approval.ID, err)
+			return
+		}
+	}
+
+	if err := db.DB.Model(approval).Update("reminded_at", now).Error; err != nil {
+		log.Printf("Failed to mark approval %d as reminded: %v", approval.ID, err)
+	}
+}
+
+func (w *SLAWorker) escalate(ctx context.Context, approval *models.Approval, now time.Time) {
+	var policy models.SLAPolicy
+	if approval.SLAPolicyID == nil || db.DB.First(&policy, *approval.SLAPolicyID).Error != nil {
+		log.Printf("Approval %d has no SLA policy, cannot escalate", approval.ID)
+		return
+	}
+
+	// next manager up the chain, the assignee stays when the top is reached
+	var next *models.User
+	if approval.AssigneeID != nil {
+		chain, err := helpers.GetManagerChain(db.DB, *approval.AssigneeID)
+		if err != nil {
+			log.Printf("Failed to load manager chain for approval %d: %v", approval.ID, err)
+			return
+		}
+		if len(chain) > 0 {
+			next = &models.User{}
+			if err := db.DB.First(next, chain[0]).Error; err != nil {
+				log.Printf("Failed to fetch escalation target for approval %d: %v", approval.ID, err)
+				return
+			}
+		}
+	}
+
+	reason := "Approval SLA breached, no higher manager to escalate to"
+	if next != nil {
+		approval.AssigneeID = &next.ID
+		reason = fmt.Sprintf("Approval SLA breached, escalated to %s", next.Name)
+	}
+	approval.EscalatedAt = &now
+	approval.EscalationLevel++
+	rules.ApplySLAPolicy(approval, &policy, now)
+	// the top of the chain is told once, the assignee still gets a reminder
+	if next == nil {
+		approval.EscalationDueAt = nil
+	}
+
+	audit, err := actions.ExpenseAuditLog(actions.ExpenseAuditLogInput{
+		ExpenseID:  approval.ExpenseID,
+		ActorID:    nil,
+		FromStatus: approval.Expense.Status,
+		ToStatus:   approval.Expense.Status,
+		Reason:     reason,
+	})
+	if err != nil {
+		log.Printf("Failed to create audit log for approval %d: %v", approval.ID, err)
+		return
+	}
+
+	tx := db.DB.Begin()
+	if err := tx.Model(approval).Updates(map[string]interface{}{
+		"assignee_id":       approval.AssigneeID,
+		"escalated_at":      approval.EscalatedAt,
+		"escalation_level":  approval.EscalationLevel,
+		"reminder_due_at":   approval.ReminderDueAt,
+		"escalation_due_at": approval.EscalationDueAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Failed to escalate approval %d: %v", approval.ID, err)
+		return
+	}
+	if err := helpers.AppendExpenseAuditLog(tx, audit); err != nil {
+		tx.Rollback()
+		log.Printf("Failed to create audit log for approval %d: %v", approval.ID, err)
+		return
+	}
+	tx.Commit()
+
+	if next != nil {
+		subject := fmt.Sprintf("Escalation: expense %d needs your approval", approval.ExpenseID)
+		body := fmt.Sprintf("\"%s\" was not decided in time and has been escalated to you.", approval.Expense.Description)
+		if err := w.notifier.Notify(ctx, next, subject, body); err != nil {
+			log.Printf("Failed to notify escalation for approval %d: %v", approval.ID, err)
+		}
+	}
+}
@@ -8,18 +8,37 @@ export const useApi = (role) => {
       ? '/v1/api/user'
       : '/v1/api'
 
-  const request = async (path, options = {}) => {
-    // const isBrowser = import.meta.client
-    const url = `${base}${path}`
-        
-    return await $fetch(url, {
+  // read on every call, refresh rotates the token
+  const csrfToken = () =>
+    import.meta.client
+      ? decodeURIComponent(document.cookie.match(/(?:^|; )csrf_token=([^;]*)/)?.[1] || '')
+      : ''
+
+  const send = (url, options) =>
+    $fetch(url, {
       ...options,
       credentials: 'include',
       headers: {
         'Content-Type': 'application/json',
+        ...(options.method && options.method !== 'GET' ? { 'X-CSRF-Token': csrfToken() } : {}),
         ...options.headers,
       },
     })
+
+  const request = async (path, options = {}) => {
+    // const isBrowser = import.meta.client
+    const url = `${base}${path}`
+
+    try {
+      return await send(url, options)
+    } catch (err) {
+      // access tokens are short-lived, rotate the refresh token once and retry
+      if (err?.status !== 401 || path.startsWith('/auth/')) {
+        throw err
+      }
+      await send('/v1/api/auth/refresh', { method: 'POST' })
+      return await send(url, options)
+    }
   }
 
   return {
@@ -41,6 +41,15 @@ export const useAuth = () => {
   }
 
   const logout = async () => {
+    try {
+      const csrfToken = import.meta.client
+        ? decodeURIComponent(document.cookie.match(/(?:^|; )csrf_token=([^;]*)/)?.[1] || '')
+        : ''
+      await $fetch('/v1/api/auth/logout', { method: 'POST', credentials: 'include', headers: { 'X-CSRF-Token': csrfToken } })
+    } catch (err) {
+      // cookies are cleared below either way
+    }
+
     role.value = null
     userName.value = null
     userId.value = null
@@ -2,7 +2,7 @@ import { useAuth } from '~/composables/useAuth'
 
 export default defineNuxtRouteMiddleware((to) => {
   const { isLoggedIn, isManager } = useAuth()
-  const publicPages = ['/login']
+  const publicPages = ['/login', '/forgot-password', '/reset-password']
 
   if (!isLoggedIn.value && !publicPages.includes(to.path)) {
     return navigateTo('/login')